@@ -12,12 +12,15 @@ require (
 
 require (
 	github.com/go-playground/assert/v2 v2.0.1
+	github.com/go-sql-driver/mysql v1.8.1
+	github.com/lib/pq v1.10.9
 	github.com/pkg/xattr v0.4.8
 	github.com/zyedidia/generic v1.1.0
 	golang.org/x/exp v0.0.0-20220218215828-6cf2b201936e
 )
 
 require (
+	filippo.io/edwards25519 v1.1.0 // indirect
 	github.com/gin-contrib/sse v0.1.0 // indirect
 	github.com/go-playground/locales v0.14.0 // indirect
 	github.com/go-playground/universal-translator v0.18.0 // indirect
@@ -1,47 +1,43 @@
 package main
 
 import (
+	"flag"
+	"fsrv/src/cli"
 	"fsrv/src/config"
-	"fsrv/src/database/dbutil"
-	"fsrv/src/database/impl/cache"
-	"fsrv/src/filemanager"
-	"fsrv/src/server/files"
-	"log"
-	"strconv"
+	"fsrv/src/logging"
+	"os"
 )
 
-var cfg *config.Config
 var configPaths = []string{
 	"/etc/fsrv/config.toml",
 	"config.toml",
 }
 
-func init() {
-	var err error
-	cfg, err = config.Load(configPaths)
-	if err != nil {
-		log.Fatal(err)
-	}
-}
+var configPath = flag.String("config", "", "the path to the config file (default: /etc/fsrv/config.toml, then ./config.toml)")
 
 func main() {
-	// setup database
-	db, err := dbutil.Create(cfg.Database)
+	flag.Parse()
+
+	paths := configPaths
+	if *configPath != "" {
+		paths = []string{*configPath}
+	}
+	cfg, err := config.Load(paths)
 	if err != nil {
-		log.Fatal(err)
+		logging.Fatalf("%v", err)
 	}
-	db = cache.NewCache(cfg.Cache, db)
-
-	// setup file manager
-	fm := filemanager.New(cfg.FileManager)
 
-	// setup server
-	serv := files.New(cfg, db, fm)
+	// start the server if no command is given
+	args := flag.Args()
+	if len(args) == 0 {
+		args = []string{"serve"}
+	}
 
-	// begin server
-	addr := ":" + strconv.Itoa(int(cfg.Server.Port))
-	err = serv.Start(addr)
+	err = cli.Run(cli.NewEnv(cfg), "fsrv", cli.Commands(), args)
+	if err == cli.ErrUsage {
+		os.Exit(2)
+	}
 	if err != nil {
-		log.Fatal(err)
+		logging.Fatalf("%v", err)
 	}
 }
@@ -0,0 +1,86 @@
+package access
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"fsrv/src/database"
+	"fsrv/src/database/entities"
+	"fsrv/src/filemanager"
+	"fsrv/utils/keygen"
+	"os"
+)
+
+var ErrAlreadyAttached = errors.New("a resource is already attached to the specified path")
+
+// resourceIDBytes is the number of random bytes in generated resource ids.
+const resourceIDBytes = 12
+
+// NewResourceID returns a random resource id.
+func NewResourceID() string {
+	return base64.RawURLEncoding.EncodeToString(keygen.GetRand(resourceIDBytes))
+}
+
+// Attach creates a resource and attaches it to an existing on-disk path,
+// which must not already have a resource attached directly to it. If
+// attaching the resource fails, it is deleted again, so that no resource
+// is left unattached. The path is locked while it is checked and attached,
+// so concurrent attaches to it do not replace each other's resources.
+func (r *Resolver) Attach(name string, res *entities.Resource) error {
+	unlock := r.fileManager.LockPath(name)
+	defer unlock()
+
+	_, err := os.Lstat(name)
+	if err != nil {
+		return err
+	}
+	_, err = r.fileManager.ResourceID(name)
+	if err == nil {
+		return ErrAlreadyAttached
+	}
+	if err != filemanager.ErrNotAttached {
+		return err
+	}
+
+	err = r.database.CreateResource(res)
+	if err != nil {
+		return err
+	}
+	err = r.fileManager.AttachResource(name, res.ID)
+	if err != nil {
+		if delErr := r.database.DeleteResource(res.ID); delErr != nil {
+			return fmt.Errorf("%w (deleting the resource also failed: %v)", err, delErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// Detach detaches the resource attached directly to an on-disk path, so
+// that the path inherits its access from its parent directory, and returns
+// its id. The resource is deleted, unless keep is true. If deleting the
+// resource fails, it is attached to the path again.
+func (r *Resolver) Detach(name string, keep bool) (string, error) {
+	unlock := r.fileManager.LockPath(name)
+	defer unlock()
+
+	resID, err := r.fileManager.ResourceID(name)
+	if err != nil {
+		return "", err
+	}
+
+	err = r.fileManager.DetachResource(name)
+	if err != nil {
+		return "", err
+	}
+	if !keep {
+		err = r.database.DeleteResource(resID)
+		if err != nil && err != database.ErrResourceMissing {
+			if attachErr := r.fileManager.AttachResource(name, resID); attachErr != nil {
+				return "", fmt.Errorf("%w (attaching the resource again also failed: %v)", err, attachErr)
+			}
+			return "", err
+		}
+	}
+	return resID, nil
+}
@@ -0,0 +1,68 @@
+package access
+
+import (
+	"fsrv/src/config"
+	"fsrv/src/database/entities"
+	"fsrv/src/database/impl/inmemory"
+	"fsrv/src/filemanager"
+	"github.com/go-playground/assert/v2"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// slowDB is a database which is slow to create resources,
+// so that concurrent attaches overlap.
+type slowDB struct {
+	*inmemory.InMemoryDB
+}
+
+func (db slowDB) CreateResource(res *entities.Resource) error {
+	time.Sleep(10 * time.Millisecond)
+	return db.InMemoryDB.CreateResource(res)
+}
+
+func TestResolver_AttachConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "file.txt")
+	err := os.WriteFile(name, []byte("hello"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db := slowDB{inmemory.New()}
+	cfg := &config.FileManager{Path: dir, ResourceLocator: config.ResourceLocatorDatabase}
+	fm := filemanager.New(cfg, db)
+	r := New(db, fm, cfg)
+
+	const attempts = 8
+	errs := make([]error, attempts)
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = r.Attach(name, &entities.Resource{ID: NewResourceID()})
+		}(i)
+	}
+	wg.Wait()
+
+	attached := 0
+	for _, err := range errs {
+		if err == nil {
+			attached++
+		} else {
+			assert.Equal(t, ErrAlreadyAttached, err)
+		}
+	}
+	assert.Equal(t, 1, attached)
+
+	ids, err := db.GetResourceIDs(attempts, 0)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(ids))
+	id, err := fm.ResourceID(name)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, ids[0], id)
+}
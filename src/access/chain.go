@@ -0,0 +1,61 @@
+package access
+
+import (
+	"fsrv/src/database"
+	"fsrv/src/database/entities"
+	"fsrv/src/filemanager"
+)
+
+// Link is a resource attached to a path, which may decide
+// the access to the path and each of its descendants.
+type Link struct {
+	// Path is the path the resource is attached to, as seen by clients.
+	Path string `json:"path"`
+	// ResourceID is the id of the resource attached to the path.
+	ResourceID string `json:"resource_id"`
+	// Resource is the resource attached to the path, or nil if
+	// the resource does not exist, in which case access to the
+	// path is denied.
+	Resource *entities.Resource `json:"resource"`
+}
+
+// Chain returns the resources which are evaluated, in order, to determine
+// the access to an on-disk path, which does not need to exist: the resource
+// attached to the path itself, followed by those attached to each of its
+// parents, up to the base directory of the file manager.
+func (r *Resolver) Chain(name string) ([]*Link, error) {
+	chain := []*Link{}
+	err := r.walk(name, func(name string) (bool, error) {
+		link, err := r.link(name)
+		if link != nil {
+			chain = append(chain, link)
+		}
+		return false, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return chain, nil
+}
+
+// link returns the resource attached directly to an on-disk path,
+// or nil if there is none.
+func (r *Resolver) link(name string) (*Link, error) {
+	resID, err := r.fileManager.ResourceID(name)
+	if err == filemanager.ErrNotAttached {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	link := &Link{
+		Path:       r.fileManager.RelPath(name),
+		ResourceID: resID,
+	}
+	link.Resource, err = r.database.GetResourceData(resID)
+	if err != nil && err != database.ErrResourceMissing {
+		return nil, err
+	}
+	return link, nil
+}
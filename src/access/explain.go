@@ -0,0 +1,142 @@
+package access
+
+import (
+	"fsrv/src/database"
+	"fsrv/src/database/entities"
+	"fsrv/src/filemanager"
+	"fsrv/src/types"
+	"strings"
+)
+
+// Step is the evaluation of the resource attached directly to a path,
+// as part of determining the access to the path or one of its descendants.
+type Step struct {
+	// Path is the path which was evaluated, as seen by clients.
+	Path string `json:"path"`
+	// ResourceID is the id of the resource attached to the path,
+	// or empty if there is none.
+	ResourceID string `json:"resource_id,omitempty"`
+	// Match explains the access given by the resource, or is nil
+	// if there is no resource attached to the path.
+	Match *entities.AccessMatch `json:"match,omitempty"`
+	// Error is set if the resource could not be evaluated, in
+	// which case access is denied.
+	Error string `json:"error,omitempty"`
+}
+
+// Explanation is the trace of determining the access of a key
+// for an operation on a path.
+type Explanation struct {
+	// KeyID is the id of the key, or empty for anonymous access.
+	KeyID string `json:"key_id"`
+	// Path is the path access was determined for, as seen by clients.
+	Path string `json:"path"`
+	// Operation is the name of the operation.
+	Operation string `json:"operation"`
+	// Steps are the paths which were evaluated, in order.
+	Steps []*Step `json:"steps"`
+	// Default is whether no resource allowed or denied access,
+	// so that the default access status was used.
+	Default bool `json:"default"`
+	// Status is the final access status.
+	Status entities.AccessStatus `json:"status"`
+}
+
+// Explain determines the access of a key (may be nil) for an operation
+// on an on-disk path, which does not need to exist, in the same way as
+// Check, and returns the evaluation at each level of the directory walk.
+// A resource which does not exist is recorded as a step which denies access.
+func (r *Resolver) Explain(key *entities.Key, name string, op types.OperationType) (*Explanation, error) {
+	exp := &Explanation{
+		Path:      r.fileManager.RelPath(name),
+		Operation: op.String(),
+		Steps:     []*Step{},
+		Default:   true,
+		Status:    r.defaultStatus,
+	}
+	if key != nil {
+		exp.KeyID = key.ID
+	}
+
+	err := r.walk(name, func(name string) (bool, error) {
+		step, err := r.explainPath(key, name, op)
+		if err != nil {
+			return false, err
+		}
+		exp.Steps = append(exp.Steps, step)
+
+		switch {
+		case step.Error != "":
+			exp.Status = entities.AccessDenied
+		case step.Match != nil && step.Match.Status != entities.AccessNeutral:
+			exp.Status = step.Match.Status
+		default:
+			return false, nil
+		}
+		exp.Default = false
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return exp, nil
+}
+
+// explainPath evaluates the resource attached directly to an on-disk path.
+func (r *Resolver) explainPath(key *entities.Key, name string, op types.OperationType) (*Step, error) {
+	step := &Step{Path: r.fileManager.RelPath(name)}
+	resID, err := r.fileManager.ResourceID(name)
+	if err == filemanager.ErrNotAttached {
+		return step, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	step.ResourceID = resID
+
+	res, err := r.database.GetResourceData(resID)
+	if err == database.ErrResourceMissing {
+		step.Error = err.Error()
+		return step, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	match := res.ExplainAccess(key, op)
+	step.Match = &match
+	return step, nil
+}
+
+// String returns a summary of an explanation on a single line, such as:
+//
+//	/dir/file: -; /dir: res1 role read:reader denied => denied
+func (e *Explanation) String() string {
+	var b strings.Builder
+	for i, step := range e.Steps {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(step.Path)
+		b.WriteString(":")
+		switch {
+		case step.ResourceID == "":
+			b.WriteString(" -")
+		case step.Match == nil:
+			b.WriteString(" " + step.ResourceID + " error")
+		default:
+			b.WriteString(" " + step.ResourceID + " " + string(step.Match.Rule))
+			if step.Match.Node != nil {
+				text, _ := step.Match.Node.MarshalText()
+				b.WriteString(" " + string(text))
+			}
+			b.WriteString(" " + step.Match.Status.String())
+		}
+	}
+
+	b.WriteString(" => ")
+	if e.Default {
+		b.WriteString("default ")
+	}
+	b.WriteString(e.Status.String())
+	return b.String()
+}
@@ -0,0 +1,99 @@
+package access
+
+import (
+	"fsrv/src/config"
+	"fsrv/src/database"
+	"fsrv/src/database/entities"
+	"fsrv/src/filemanager"
+	"fsrv/src/logging"
+	"fsrv/src/types"
+	"path/filepath"
+)
+
+// Resolver determines the access a key has to files and directories.
+type Resolver struct {
+	database      database.DBInterface
+	fileManager   *filemanager.FileManager
+	defaultStatus entities.AccessStatus
+}
+
+// New creates a new Resolver. Paths which no resource allows
+// or denies access to are denied, unless the default access
+// in the file manager config is 'allow'.
+func New(db database.DBInterface, fm *filemanager.FileManager, cfg *config.FileManager) *Resolver {
+	defaultStatus := entities.AccessDenied
+	if cfg.DefaultAccess == "allow" {
+		defaultStatus = entities.AccessAllowed
+	}
+
+	return &Resolver{
+		database:      db,
+		fileManager:   fm,
+		defaultStatus: defaultStatus,
+	}
+}
+
+// Check returns the access status of a key (may be nil) for an operation
+// on an on-disk path, which does not need to exist. The resources attached
+// to the path and each of its parents are evaluated in turn, up to the base
+// directory of the file manager, and the first to allow or deny access
+// decides it. If none do, the default access status is returned.
+func (r *Resolver) Check(key *entities.Key, name string, op types.OperationType) (entities.AccessStatus, error) {
+	status := r.defaultStatus
+	err := r.walk(name, func(name string) (bool, error) {
+		pathStatus, err := r.CheckPath(key, name, op)
+		if err != nil || pathStatus == entities.AccessNeutral {
+			return false, err
+		}
+		status = pathStatus
+		return true, nil
+	})
+	if err != nil {
+		return entities.AccessDenied, err
+	}
+	return status, nil
+}
+
+// CheckPath returns the access status of a key (may be nil) for an
+// operation on an on-disk path, considering only the resource attached
+// directly to it. AccessNeutral means the path inherits its access from
+// its parent directory. A resource which is attached but does not exist
+// denies access, without an error, as it does in Explain.
+func (r *Resolver) CheckPath(key *entities.Key, name string, op types.OperationType) (entities.AccessStatus, error) {
+	resID, err := r.fileManager.ResourceID(name)
+	if err == filemanager.ErrNotAttached {
+		return entities.AccessNeutral, nil
+	}
+	if err != nil {
+		return entities.AccessDenied, err
+	}
+
+	res, err := r.database.GetResourceData(resID)
+	if err == database.ErrResourceMissing {
+		logging.Warningf("resource '%s' attached to '%s' does not exist, denying access", resID, r.fileManager.RelPath(name))
+		return entities.AccessDenied, nil
+	}
+	if err != nil {
+		return entities.AccessDenied, err
+	}
+	return res.CheckAccess(key, op), nil
+}
+
+// walk calls visit with an on-disk path and each of its parents in turn,
+// up to the base directory of the file manager, until visit returns true
+// or an error.
+func (r *Resolver) walk(name string, visit func(name string) (bool, error)) error {
+	base := r.fileManager.BaseDir()
+	for {
+		done, err := visit(name)
+		if done || err != nil {
+			return err
+		}
+
+		parent := filepath.Dir(name)
+		if name == base || parent == name {
+			return nil
+		}
+		name = parent
+	}
+}
@@ -0,0 +1,175 @@
+package access
+
+import (
+	"fsrv/src/config"
+	"fsrv/src/database"
+	"fsrv/src/database/entities"
+	"fsrv/src/filemanager"
+	"fsrv/src/types"
+	"github.com/go-playground/assert/v2"
+	"github.com/pkg/xattr"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// resourceDB is a database which only stores resources.
+type resourceDB struct {
+	database.DBInterface
+	resources map[string]*entities.Resource
+}
+
+func (db *resourceDB) GetResourceData(resourceID string) (*entities.Resource, error) {
+	res, ok := db.resources[resourceID]
+	if !ok {
+		return nil, database.ErrResourceMissing
+	}
+	return res, nil
+}
+
+func attachResource(t *testing.T, path, id string) {
+	err := os.MkdirAll(path, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = xattr.Set(path, "user.fsrv.resourceid", []byte(id))
+	if err != nil {
+		t.Skip("extended attributes are not supported:", err)
+	}
+}
+
+func TestResolver_Check(t *testing.T) {
+	dir := t.TempDir()
+	db := &resourceDB{resources: map[string]*entities.Resource{
+		"root": {
+			ID: "root",
+			OperationNodes: map[entities.ResourceOperationAccess]bool{
+				{ID: "reader", Type: types.OperationRead}:  true,
+				{ID: "writer", Type: types.OperationWrite}: true,
+			},
+		},
+		"private": {
+			ID: "private",
+			OperationNodes: map[entities.ResourceOperationAccess]bool{
+				{ID: "reader", Type: types.OperationRead}: false,
+				{ID: "key2", Type: types.OperationRead}:   true,
+			},
+		},
+		"public": {
+			ID:    "public",
+			Flags: entities.FlagPublicRead,
+			OperationNodes: map[entities.ResourceOperationAccess]bool{
+				{ID: "*", Type: types.OperationRead}: true,
+			},
+		},
+		"neutral": {
+			ID:             "neutral",
+			OperationNodes: map[entities.ResourceOperationAccess]bool{},
+		},
+	}}
+
+	attachResource(t, dir, "root")
+	attachResource(t, filepath.Join(dir, "private"), "private")
+	attachResource(t, filepath.Join(dir, "private", "public"), "public")
+	attachResource(t, filepath.Join(dir, "neutral"), "neutral")
+	attachResource(t, filepath.Join(dir, "missing"), "missing")
+
+	cfg := &config.FileManager{Path: dir}
+	r := New(db, filemanager.New(cfg, nil), cfg)
+
+	reader := &entities.Key{ID: "key1", Roles: []string{"reader"}}
+	privileged := &entities.Key{ID: "key2", Roles: []string{"reader", "writer"}}
+
+	tests := []struct {
+		name   string
+		key    *entities.Key
+		path   string
+		op     types.OperationType
+		status entities.AccessStatus
+		err    bool
+	}{
+		{"root allows role", reader, "file.txt", types.OperationRead, entities.AccessAllowed, false},
+		{"root denies unknown operation", reader, "file.txt", types.OperationDelete, entities.AccessDenied, false},
+		{"nested deny overrides parent allow", reader, "private/file.txt", types.OperationRead, entities.AccessDenied, false},
+		{"key overrides role", privileged, "private/file.txt", types.OperationRead, entities.AccessAllowed, false},
+		{"inherits from grandparent", privileged, "private/a/b/file.txt", types.OperationWrite, entities.AccessAllowed, false},
+		{"catch-all overrides parent deny", reader, "private/public/file.txt", types.OperationRead, entities.AccessAllowed, false},
+		{"anonymous public read", nil, "private/public/file.txt", types.OperationRead, entities.AccessAllowed, false},
+		{"anonymous write", nil, "private/public/file.txt", types.OperationWrite, entities.AccessDenied, false},
+		{"neutral resource inherits", reader, "neutral/file.txt", types.OperationRead, entities.AccessAllowed, false},
+		{"non-existent path", reader, "new/dir/file.txt", types.OperationWrite, entities.AccessDenied, false},
+		{"missing resource", reader, "missing/file.txt", types.OperationRead, entities.AccessDenied, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status, err := r.Check(test.key, filepath.Join(dir, test.path), test.op)
+			assert.Equal(t, test.err, err != nil)
+			assert.Equal(t, test.status, status)
+
+			// explanations always agree with checks
+			exp, err := r.Explain(test.key, filepath.Join(dir, test.path), test.op)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, test.status, exp.Status)
+		})
+	}
+
+	exp, err := r.Explain(reader, filepath.Join(dir, "private", "a", "file.txt"), types.OperationRead)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, len(exp.Steps))
+	assert.Equal(t, entities.MatchRole, exp.Steps[2].Match.Rule)
+	assert.Equal(t, "/private/a/file.txt: -; /private/a: -; /private: private role read:reader denied => denied", exp.String())
+
+	exp, err = r.Explain(nil, filepath.Join(dir, "new.txt"), types.OperationWrite)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "/new.txt: -; /: root anonymous denied => denied", exp.String())
+
+	exp, err = r.Explain(reader, filepath.Join(dir, "missing", "file.txt"), types.OperationRead)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, database.ErrResourceMissing.Error(), exp.Steps[1].Error)
+	assert.Equal(t, "/missing/file.txt: -; /missing: missing error => denied", exp.String())
+}
+
+func TestResolver_CheckDefault(t *testing.T) {
+	tests := []struct {
+		defaultAccess string
+		status        entities.AccessStatus
+	}{
+		{"", entities.AccessDenied},
+		{"deny", entities.AccessDenied},
+		{"allow", entities.AccessAllowed},
+	}
+	for _, test := range tests {
+		t.Run(test.defaultAccess, func(t *testing.T) {
+			cfg := &config.FileManager{Path: t.TempDir(), DefaultAccess: test.defaultAccess}
+			r := New(&resourceDB{}, filemanager.New(cfg, nil), cfg)
+
+			status, err := r.Check(nil, filepath.Join(cfg.Path, "a", "b.txt"), types.OperationRead)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, test.status, status)
+		})
+	}
+}
+
+func TestResolver_Chain(t *testing.T) {
+	dir := t.TempDir()
+	db := &resourceDB{resources: map[string]*entities.Resource{
+		"root": {ID: "root"},
+	}}
+	attachResource(t, dir, "root")
+	attachResource(t, filepath.Join(dir, "missing"), "missing")
+	err := os.Mkdir(filepath.Join(dir, "missing", "sub"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.FileManager{Path: dir}
+	r := New(db, filemanager.New(cfg, nil), cfg)
+
+	chain, err := r.Chain(filepath.Join(dir, "missing", "sub"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(chain))
+	assert.Equal(t, "/missing", chain[0].Path)
+	assert.Equal(t, (*entities.Resource)(nil), chain[0].Resource)
+	assert.Equal(t, "/", chain[1].Path)
+	assert.Equal(t, "root", chain[1].Resource.ID)
+}
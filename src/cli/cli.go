@@ -0,0 +1,218 @@
+// Package cli implements the subcommands of the fsrv binary, which
+// either start the server or manage its database and file manager
+// directly, without the admin API. Changes made while the server is
+// running are not seen by it until its cached data expires, after
+// cache.ttl, or until it is restarted if cache.ttl is 0.
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"fsrv/src/access"
+	"fsrv/src/config"
+	"fsrv/src/database"
+	"fsrv/src/database/dbutil"
+	"fsrv/src/filemanager"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrUsage is returned when a command is used incorrectly,
+// after its usage has been printed.
+var ErrUsage = errors.New("invalid usage")
+
+// Command is a subcommand of the fsrv binary. A command either
+// runs, or groups further subcommands.
+type Command struct {
+	// Name is the name of the command.
+	Name string
+	// Args describes the positional arguments of the command.
+	Args string
+	// Short is a one line description of the command.
+	Short string
+	// NArgs is the number of positional arguments the command
+	// takes, or the minimum if negative.
+	NArgs int
+	// Flags defines the flags of the command, if any.
+	Flags func(fs *flag.FlagSet)
+	// Run runs the command with its positional arguments.
+	Run func(env *Env, args []string) error
+	// Commands are the subcommands of the command.
+	Commands []*Command
+}
+
+// Commands returns the top level commands of the fsrv binary. The
+// commands hold the values of their flags, so they should only be run
+// once.
+func Commands() []*Command {
+	return []*Command{
+		serveCommand(),
+		{Name: "key", Short: "manage keys", Commands: keyCommands()},
+		{Name: "role", Short: "manage roles", Commands: roleCommands()},
+		{Name: "resource", Short: "manage resources and the paths they are attached to", Commands: resourceCommands()},
+		{Name: "ratelimit", Short: "manage rate limits", Commands: rateLimitCommands()},
+		{Name: "token", Short: "manage admin api tokens", Commands: tokenCommands()},
+		{Name: "db", Short: "manage the database", Commands: dbCommands()},
+	}
+}
+
+// Env is the environment commands run in. The database and
+// file manager are only opened once a command needs them.
+type Env struct {
+	Config *config.Config
+	Out    io.Writer
+	Err    io.Writer
+
+	database    database.DBInterface
+	fileManager *filemanager.FileManager
+}
+
+// NewEnv creates an environment which writes to stdout and stderr.
+func NewEnv(cfg *config.Config) *Env {
+	return &Env{
+		Config: cfg,
+		Out:    os.Stdout,
+		Err:    os.Stderr,
+	}
+}
+
+// Database opens the existing database, or returns the
+// database which was already opened.
+func (e *Env) Database() (database.DBInterface, error) {
+	if e.database != nil {
+		return e.database, nil
+	}
+
+	db, err := dbutil.Open(e.Config.Database)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, errors.New("the database does not exist; create it with 'fsrv db init'")
+	}
+	if err != nil {
+		return nil, err
+	}
+	e.database = db
+	return db, nil
+}
+
+// FileManager returns the file manager, opening the database if needed.
+func (e *Env) FileManager() (*filemanager.FileManager, error) {
+	if e.fileManager != nil {
+		return e.fileManager, nil
+	}
+
+	db, err := e.Database()
+	if err != nil {
+		return nil, err
+	}
+	e.fileManager = filemanager.New(e.Config.FileManager, db)
+	return e.fileManager, nil
+}
+
+// Resolver returns a resolver for the database and file manager.
+func (e *Env) Resolver() (*access.Resolver, error) {
+	fm, err := e.FileManager()
+	if err != nil {
+		return nil, err
+	}
+	return access.New(e.database, fm, e.Config.FileManager), nil
+}
+
+// Path returns the on-disk path of a path relative to the file manager path.
+// The path is rooted at the file manager path, so ".." refers to it too.
+func (e *Env) Path(name string) (string, error) {
+	fm, err := e.FileManager()
+	if err != nil {
+		return "", err
+	}
+
+	cleaned := fm.CleanPath(filepath.Join(string(filepath.Separator), name))
+	if fm.IsReserved(cleaned) {
+		return "", fmt.Errorf("the path '%s' is reserved", name)
+	}
+	return cleaned, nil
+}
+
+// Print writes a value to the output as indented JSON.
+func (e *Env) Print(v any) error {
+	enc := json.NewEncoder(e.Out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// Run runs the command named by the first argument, from the given
+// commands, passing it the remaining arguments. The path contains the
+// names of the parent commands, and is used when printing usage.
+func Run(env *Env, path string, commands []*Command, args []string) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "-help" {
+		printCommands(env.Err, path, commands)
+		if len(args) == 0 {
+			return ErrUsage
+		}
+		return nil
+	}
+
+	for _, cmd := range commands {
+		if cmd.Name != args[0] {
+			continue
+		}
+		if cmd.Commands != nil {
+			return Run(env, path+" "+cmd.Name, cmd.Commands, args[1:])
+		}
+		return cmd.run(env, path+" "+cmd.Name, args[1:])
+	}
+
+	fmt.Fprintf(env.Err, "unknown command '%s'\n", args[0])
+	printCommands(env.Err, path, commands)
+	return ErrUsage
+}
+
+func (c *Command) run(env *Env, path string, args []string) error {
+	fs := flag.NewFlagSet(path, flag.ContinueOnError)
+	fs.SetOutput(env.Err)
+	fs.Usage = func() {
+		fmt.Fprintf(env.Err, "usage: %s [flags] %s\n\n%s\n", path, c.Args, c.Short)
+		fs.PrintDefaults()
+	}
+	if c.Flags != nil {
+		c.Flags(fs)
+	}
+
+	err := fs.Parse(args)
+	if err == flag.ErrHelp {
+		return nil
+	}
+	if err != nil {
+		return ErrUsage
+	}
+
+	n := fs.NArg()
+	if (c.NArgs >= 0 && n != c.NArgs) || (c.NArgs < 0 && n < -c.NArgs) {
+		fs.Usage()
+		return ErrUsage
+	}
+	return c.Run(env, fs.Args())
+}
+
+func printCommands(w io.Writer, path string, commands []*Command) {
+	fmt.Fprintf(w, "usage: %s <command> [flags] [arguments]\n\ncommands:\n", path)
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.Name, cmd.Short)
+	}
+}
+
+// stringList is a flag which may be repeated to give multiple values.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
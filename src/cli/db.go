@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"fsrv/src/config"
+	"fsrv/src/database/dbutil"
+	"fsrv/src/filemanager"
+)
+
+func dbCommands() []*Command {
+	return []*Command{
+		{
+			Name:  "init",
+			Short: "create the database if it does not exist",
+			Run: func(env *Env, args []string) error {
+				db, err := dbutil.Create(env.Config.Database)
+				if err != nil {
+					return err
+				}
+				err = db.Close()
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(env.Out, "database ready:", describeDatabase(env.Config.Database))
+				return nil
+			},
+		},
+		{
+			Name:  "check",
+			Short: "verify the schema of the database",
+			Run: func(env *Env, args []string) error {
+				db, err := env.Database()
+				if err != nil {
+					return err
+				}
+				err = db.Check()
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(env.Out, "database ok")
+				return nil
+			},
+		},
+		dbMigrateCommand(),
+	}
+}
+
+// describeDatabase returns the path of a sqlite database, or otherwise
+// only the type of the database, since the connection strings of
+// server databases may contain passwords.
+func describeDatabase(cfg *config.Database) string {
+	if cfg.Type == config.DatabaseSQLite {
+		return cfg.ConnectionString
+	}
+	return string(cfg.Type)
+}
+
+func dbMigrateCommand() *Command {
+	var xattrs bool
+	var version int
+	return &Command{
+		Name:  "migrate",
+		Short: "upgrade the schema of the database",
+		Flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&xattrs, "xattrs", false, "also copy resource ids from extended attributes into the database")
+			fs.IntVar(&version, "to", 0, "the schema version to upgrade or downgrade to, or 0 for the latest")
+		},
+		Run: func(env *Env, args []string) error {
+			from, to, err := dbutil.Migrate(env.Config.Database, version)
+			if err != nil {
+				return err
+			}
+			if from == to {
+				fmt.Fprintf(env.Out, "database schema is up to date at version %d\n", to)
+			} else {
+				fmt.Fprintf(env.Out, "database schema migrated from version %d to %d\n", from, to)
+			}
+			if !xattrs {
+				return nil
+			}
+
+			db, err := env.Database()
+			if err != nil {
+				return err
+			}
+			dst := filemanager.NewDatabaseLocator(db, env.Config.FileManager.Path)
+			fm, err := env.FileManager()
+			if err != nil {
+				return err
+			}
+			n, err := filemanager.CopyResources(fm.BaseDir(), filemanager.XAttrLocator{}, dst)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(env.Out, "copied %d resource ids from extended attributes into the database\n", n)
+			return nil
+		},
+	}
+}
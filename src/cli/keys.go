@@ -0,0 +1,174 @@
+package cli
+
+import (
+	"flag"
+	"fsrv/src/server/admin/handlers"
+	"fsrv/utils/serde"
+	"time"
+)
+
+func keyCommands() []*Command {
+	return []*Command{
+		keyCreateCommand(),
+		{
+			Name:  "list",
+			Short: "list keys",
+			Run: func(env *Env, args []string) error {
+				db, err := env.Database()
+				if err != nil {
+					return err
+				}
+				return printPages(env, db.GetKeys)
+			},
+		},
+		{
+			Name:  "show",
+			Args:  "<id>",
+			Short: "show a key",
+			NArgs: 1,
+			Run: func(env *Env, args []string) error {
+				db, err := env.Database()
+				if err != nil {
+					return err
+				}
+				key, err := db.GetKeyData(args[0])
+				if err != nil {
+					return err
+				}
+				return env.Print(key)
+			},
+		},
+		{
+			Name:  "revoke",
+			Args:  "<id>",
+			Short: "revoke a key",
+			NArgs: 1,
+			Run: func(env *Env, args []string) error {
+				db, err := env.Database()
+				if err != nil {
+					return err
+				}
+				return db.DeleteKey(args[0])
+			},
+		},
+	}
+}
+
+func keyCreateCommand() *Command {
+	var comment, rateLimitID string
+	var roles stringList
+	var expires time.Duration
+	return &Command{
+		Name:  "create",
+		Short: "mint a key, printing it once",
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&comment, "comment", "", "the owner or usage of the key")
+			fs.Var(&roles, "role", "a role of the key; may be repeated")
+			fs.StringVar(&rateLimitID, "ratelimit", "", "the rate limit of the key (default: the default authenticated rate limit)")
+			fs.DurationVar(&expires, "expires", 0, "how long until the key expires (default: never)")
+		},
+		Run: func(env *Env, args []string) error {
+			db, err := env.Database()
+			if err != nil {
+				return err
+			}
+			if rateLimitID != "" {
+				_, err = db.GetRateLimitData(rateLimitID)
+				if err != nil {
+					return err
+				}
+			}
+
+			key, plaintext := handlers.NewKey(env.Config.Server)
+			key.Comment = comment
+			key.Roles = roles
+			key.RateLimitID = rateLimitID
+			if expires > 0 {
+				key.ExpiresAt = serde.Time(time.Now().Add(expires))
+			}
+			err = db.CreateKey(key)
+			if err != nil {
+				return err
+			}
+			return env.Print(&handlers.CreatedKey{Key: key, Plaintext: plaintext})
+		},
+	}
+}
+
+func tokenCommands() []*Command {
+	return []*Command{
+		tokenCreateCommand(),
+		{
+			Name:  "list",
+			Short: "list admin api tokens",
+			Run: func(env *Env, args []string) error {
+				db, err := env.Database()
+				if err != nil {
+					return err
+				}
+				return printPages(env, db.GetTokens)
+			},
+		},
+		{
+			Name:  "revoke",
+			Args:  "<id>",
+			Short: "revoke an admin api token",
+			NArgs: 1,
+			Run: func(env *Env, args []string) error {
+				db, err := env.Database()
+				if err != nil {
+					return err
+				}
+				return db.DeleteToken(args[0])
+			},
+		},
+	}
+}
+
+func tokenCreateCommand() *Command {
+	var comment string
+	var expires time.Duration
+	return &Command{
+		Name:  "create",
+		Short: "create an admin api token, printing it once",
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&comment, "comment", "", "the owner or usage of the token")
+			fs.DurationVar(&expires, "expires", 0, "how long until the token expires (default: never)")
+		},
+		Run: func(env *Env, args []string) error {
+			db, err := env.Database()
+			if err != nil {
+				return err
+			}
+
+			expiresAt := time.Unix(0, 0)
+			if expires > 0 {
+				expiresAt = time.Now().Add(expires)
+			}
+			token, plaintext := handlers.NewToken(comment, expiresAt)
+			err = db.CreateToken(token)
+			if err != nil {
+				return err
+			}
+			return env.Print(&handlers.CreatedToken{Token: token, Plaintext: plaintext})
+		},
+	}
+}
+
+// pageSize is the number of items requested from the database at once.
+const pageSize = 100
+
+// printPages prints every item returned by a paginated database method.
+func printPages[T any](env *Env, get func(pageSize int, offset int) ([]T, error)) error {
+	all := []T{}
+	for offset := 0; ; offset += pageSize {
+		page, err := get(pageSize, offset)
+		if err != nil {
+			return err
+		}
+		all = append(all, page...)
+		if len(page) < pageSize {
+			return env.Print(all)
+		}
+	}
+}
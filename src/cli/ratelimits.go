@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"flag"
+	"fsrv/src/database/entities"
+	"fsrv/utils/serde"
+	"time"
+)
+
+func rateLimitCommands() []*Command {
+	return []*Command{
+		rateLimitCreateCommand(),
+		{
+			Name:  "show",
+			Args:  "<id>",
+			Short: "show a rate limit",
+			NArgs: 1,
+			Run: func(env *Env, args []string) error {
+				db, err := env.Database()
+				if err != nil {
+					return err
+				}
+				limit, err := db.GetRateLimitData(args[0])
+				if err != nil {
+					return err
+				}
+				return env.Print(limit)
+			},
+		},
+		rateLimitUpdateCommand(),
+		{
+			Name:  "delete",
+			Args:  "<id>",
+			Short: "delete a rate limit",
+			NArgs: 1,
+			Run: func(env *Env, args []string) error {
+				db, err := env.Database()
+				if err != nil {
+					return err
+				}
+				_, err = db.GetRateLimitData(args[0])
+				if err != nil {
+					return err
+				}
+				return db.DeleteRateLimit(args[0])
+			},
+		},
+	}
+}
+
+// rateLimitFlags defines the flags which set the fields of a rate limit.
+func rateLimitFlags(fs *flag.FlagSet, limit *entities.RateLimit, refill *time.Duration) {
+	fs.Int64Var(&limit.Limit, "limit", limit.Limit, "the number of requests allowed per refill period")
+	fs.Int64Var(&limit.Burst, "burst", limit.Burst, "the number of requests allowed in a short burst")
+	fs.DurationVar(refill, "refill", *refill, "the refill period")
+}
+
+func rateLimitCreateCommand() *Command {
+	limit := &entities.RateLimit{}
+	var refill time.Duration
+	return &Command{
+		Name:  "create",
+		Args:  "<id>",
+		Short: "create a rate limit",
+		NArgs: 1,
+		Flags: func(fs *flag.FlagSet) {
+			rateLimitFlags(fs, limit, &refill)
+		},
+		Run: func(env *Env, args []string) error {
+			db, err := env.Database()
+			if err != nil {
+				return err
+			}
+			limit.ID = args[0]
+			limit.Refill = serde.Duration(refill)
+			err = db.CreateRateLimit(limit)
+			if err != nil {
+				return err
+			}
+			return env.Print(limit)
+		},
+	}
+}
+
+func rateLimitUpdateCommand() *Command {
+	update := &entities.RateLimit{}
+	var refill time.Duration
+	var flags *flag.FlagSet
+	return &Command{
+		Name:  "update",
+		Args:  "<id>",
+		Short: "update the given fields of a rate limit",
+		NArgs: 1,
+		Flags: func(fs *flag.FlagSet) {
+			flags = fs
+			fs.StringVar(&update.ID, "id", "", "a new id for the rate limit; keys using it are updated")
+			rateLimitFlags(fs, update, &refill)
+		},
+		Run: func(env *Env, args []string) error {
+			db, err := env.Database()
+			if err != nil {
+				return err
+			}
+			limit, err := db.GetRateLimitData(args[0])
+			if err != nil {
+				return err
+			}
+
+			flags.Visit(func(f *flag.Flag) {
+				switch f.Name {
+				case "id":
+					limit.ID = update.ID
+				case "limit":
+					limit.Limit = update.Limit
+				case "burst":
+					limit.Burst = update.Burst
+				case "refill":
+					limit.Refill = serde.Duration(refill)
+				}
+			})
+			err = db.UpdateRateLimit(args[0], limit)
+			if err != nil {
+				return err
+			}
+			return env.Print(limit)
+		},
+	}
+}
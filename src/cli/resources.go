@@ -0,0 +1,224 @@
+package cli
+
+import (
+	"flag"
+	"fsrv/src/access"
+	"fsrv/src/database/entities"
+	"fsrv/src/server/admin/handlers"
+	"fsrv/src/types"
+)
+
+func resourceCommands() []*Command {
+	return []*Command{
+		resourceAttachCommand(),
+		resourceDetachCommand(),
+		{
+			Name:  "show",
+			Args:  "<path>",
+			Short: "show the resources which decide the access to a path, nearest first",
+			NArgs: 1,
+			Run: func(env *Env, args []string) error {
+				r, name, err := resolvePath(env, args[0])
+				if err != nil {
+					return err
+				}
+				chain, err := r.Chain(name)
+				if err != nil {
+					return err
+				}
+				return env.Print(chain)
+			},
+		},
+		resourceGrantCommand(),
+		resourceRevokeCommand(),
+		resourceExplainCommand(),
+	}
+}
+
+func resourceAttachCommand() *Command {
+	var id string
+	var publicRead bool
+	var allow, deny stringList
+	return &Command{
+		Name:  "attach",
+		Args:  "<path>",
+		Short: "create a resource and attach it to a path",
+		NArgs: 1,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&id, "id", "", "the id of the resource (default: generated)")
+			fs.BoolVar(&publicRead, "public-read", false, "allow anyone to read the path without a key")
+			fs.Var(&allow, "allow", "a node '<operation>:<role>' to allow; may be repeated")
+			fs.Var(&deny, "deny", "a node '<operation>:<role>' to deny; may be repeated")
+		},
+		Run: func(env *Env, args []string) error {
+			r, name, err := resolvePath(env, args[0])
+			if err != nil {
+				return err
+			}
+
+			res := &entities.Resource{
+				ID:             id,
+				OperationNodes: map[entities.ResourceOperationAccess]bool{},
+			}
+			if res.ID == "" {
+				res.ID = access.NewResourceID()
+			}
+			if publicRead {
+				res.Flags |= entities.FlagPublicRead
+			}
+			for status, nodes := range map[bool]stringList{true: allow, false: deny} {
+				for _, text := range nodes {
+					var node entities.ResourceOperationAccess
+					err = node.UnmarshalText([]byte(text))
+					if err != nil {
+						return err
+					}
+					res.OperationNodes[node] = status
+				}
+			}
+
+			err = r.Attach(name, res)
+			if err != nil {
+				return err
+			}
+			return env.Print(&handlers.AttachedResource{Path: env.fileManager.RelPath(name), Resource: res})
+		},
+	}
+}
+
+func resourceDetachCommand() *Command {
+	var keep bool
+	return &Command{
+		Name:  "detach",
+		Args:  "<path>",
+		Short: "detach the resource attached to a path, and delete it",
+		NArgs: 1,
+		Flags: func(fs *flag.FlagSet) {
+			fs.BoolVar(&keep, "keep", false, "keep the resource after it is detached")
+		},
+		Run: func(env *Env, args []string) error {
+			r, name, err := resolvePath(env, args[0])
+			if err != nil {
+				return err
+			}
+			_, err = r.Detach(name, keep)
+			return err
+		},
+	}
+}
+
+func resourceGrantCommand() *Command {
+	var op string
+	var deny bool
+	return &Command{
+		Name:  "grant",
+		Args:  "<resource id> <role>...",
+		Short: "allow or deny roles, key ids or '*' permission to perform an operation on a resource",
+		NArgs: -2,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&op, "op", "read", "the operation: read, write, modify or delete")
+			fs.BoolVar(&deny, "deny", false, "deny permission instead of allowing it")
+		},
+		Run: func(env *Env, args []string) error {
+			return changePermission(env, args, op, func(perm *entities.Permission, roles []string) error {
+				perm.Status = !deny
+				return env.database.GrantPermission(perm, roles...)
+			})
+		},
+	}
+}
+
+func resourceRevokeCommand() *Command {
+	var op string
+	return &Command{
+		Name:  "revoke",
+		Args:  "<resource id> <role>...",
+		Short: "remove the permission of roles to perform an operation on a resource",
+		NArgs: -2,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&op, "op", "read", "the operation: read, write, modify or delete")
+		},
+		Run: func(env *Env, args []string) error {
+			return changePermission(env, args, op, func(perm *entities.Permission, roles []string) error {
+				return env.database.RevokePermission(perm, roles...)
+			})
+		},
+	}
+}
+
+func resourceExplainCommand() *Command {
+	var keyID, op string
+	return &Command{
+		Name:  "explain",
+		Args:  "<path>",
+		Short: "explain why a key is allowed or denied permission to perform an operation on a path",
+		NArgs: 1,
+		Flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&keyID, "key", "", "the key id (default: anonymous access)")
+			fs.StringVar(&op, "op", "read", "the operation: read, write, modify or delete")
+		},
+		Run: func(env *Env, args []string) error {
+			operation, err := types.ParseOperationType(op)
+			if err != nil {
+				return err
+			}
+			r, name, err := resolvePath(env, args[0])
+			if err != nil {
+				return err
+			}
+
+			var key *entities.Key
+			if keyID != "" {
+				key, err = env.database.GetKeyData(keyID)
+				if err != nil {
+					return err
+				}
+			}
+			exp, err := r.Explain(key, name, operation)
+			if err != nil {
+				return err
+			}
+			return env.Print(exp)
+		},
+	}
+}
+
+// resolvePath returns a resolver and the on-disk path of a path
+// relative to the file manager path.
+func resolvePath(env *Env, path string) (*access.Resolver, string, error) {
+	r, err := env.Resolver()
+	if err != nil {
+		return nil, "", err
+	}
+	name, err := env.Path(path)
+	if err != nil {
+		return nil, "", err
+	}
+	return r, name, nil
+}
+
+// changePermission changes the permission of the roles in args[1:] for an
+// operation on the resource args[0], then prints the updated resource.
+func changePermission(env *Env, args []string, op string, change func(*entities.Permission, []string) error) error {
+	operation, err := types.ParseOperationType(op)
+	if err != nil {
+		return err
+	}
+	db, err := env.Database()
+	if err != nil {
+		return err
+	}
+	_, err = db.GetResourceData(args[0])
+	if err != nil {
+		return err
+	}
+	err = change(&entities.Permission{ResourceID: args[0], TypeRWMD: operation}, args[1:])
+	if err != nil {
+		return err
+	}
+	res, err := db.GetResourceData(args[0])
+	if err != nil {
+		return err
+	}
+	return env.Print(res)
+}
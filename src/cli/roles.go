@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"flag"
+	"fsrv/src/database/entities"
+)
+
+func roleCommands() []*Command {
+	return []*Command{
+		roleCreateCommand(),
+		{
+			Name:  "list",
+			Short: "list roles, ordered by precedence",
+			Run: func(env *Env, args []string) error {
+				db, err := env.Database()
+				if err != nil {
+					return err
+				}
+				return printPages(env, db.GetRoles)
+			},
+		},
+		{
+			Name:  "show",
+			Args:  "<name>",
+			Short: "show a role",
+			NArgs: 1,
+			Run: func(env *Env, args []string) error {
+				db, err := env.Database()
+				if err != nil {
+					return err
+				}
+				role, err := db.GetRoleData(args[0])
+				if err != nil {
+					return err
+				}
+				return env.Print(role)
+			},
+		},
+		{
+			Name:  "delete",
+			Args:  "<name>",
+			Short: "delete a role, taking it from every key and resource which has it",
+			NArgs: 1,
+			Run: func(env *Env, args []string) error {
+				db, err := env.Database()
+				if err != nil {
+					return err
+				}
+				return db.DeleteRole(args[0])
+			},
+		},
+		{
+			Name:  "give",
+			Args:  "<key id> <role>...",
+			Short: "give roles to a key",
+			NArgs: -2,
+			Run: func(env *Env, args []string) error {
+				db, err := env.Database()
+				if err != nil {
+					return err
+				}
+				return db.GiveRole(args[0], args[1:]...)
+			},
+		},
+		{
+			Name:  "take",
+			Args:  "<key id> <role>...",
+			Short: "take roles from a key",
+			NArgs: -2,
+			Run: func(env *Env, args []string) error {
+				db, err := env.Database()
+				if err != nil {
+					return err
+				}
+				return db.TakeRole(args[0], args[1:]...)
+			},
+		},
+	}
+}
+
+func roleCreateCommand() *Command {
+	var precedence int
+	return &Command{
+		Name:  "create",
+		Args:  "<name>",
+		Short: "create a role",
+		NArgs: 1,
+		Flags: func(fs *flag.FlagSet) {
+			fs.IntVar(&precedence, "precedence", 0, "the precedence of the role")
+		},
+		Run: func(env *Env, args []string) error {
+			db, err := env.Database()
+			if err != nil {
+				return err
+			}
+			role := &entities.Role{ID: args[0], Precedence: precedence}
+			err = db.CreateRole(role)
+			if err != nil {
+				return err
+			}
+			return env.Print(role)
+		},
+	}
+}
@@ -0,0 +1,185 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"fsrv/src/database/dbutil"
+	"fsrv/src/database/impl/cache"
+	"fsrv/src/database/impl/metricsdb"
+	"fsrv/src/filemanager"
+	"fsrv/src/logging"
+	"fsrv/src/metrics"
+	"fsrv/src/privileges"
+	"fsrv/src/server/admin"
+	"fsrv/src/server/files"
+	"fsrv/src/server/listener"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// defaultShutdownTimeout is how long servers are given to finish active
+// requests once they are shut down, if no timeout is configured.
+const defaultShutdownTimeout = 30 * time.Second
+
+// server is a server which is run alongside others.
+type server interface {
+	Serve(l net.Listener) error
+	Shutdown(ctx context.Context) error
+}
+
+// service is a server and the listener it serves on.
+type service struct {
+	name     string
+	server   server
+	listener net.Listener
+}
+
+func serveCommand() *Command {
+	return &Command{
+		Name:  "serve",
+		Short: "start the file and admin servers, creating the database if it does not exist",
+		Run: func(env *Env, args []string) (err error) {
+			// setup logging, opening the log file before dropping privileges
+			closeLog, err := logging.Setup(env.Config.Logging)
+			if err != nil {
+				return err
+			}
+			defer closeOnReturn(&err, "log file", closeLog)
+
+			// setup database
+			db, err := dbutil.Create(env.Config.Database)
+			if err != nil {
+				return err
+			}
+			db = cache.NewCache(env.Config.Cache, metricsdb.New(db))
+			defer closeOnReturn(&err, "database", db.Close)
+
+			// setup file manager
+			fm := filemanager.New(env.Config.FileManager, db)
+			defer closeOnReturn(&err, "file manager", fm.Close)
+
+			// setup servers
+			addr := ":" + strconv.Itoa(int(env.Config.Server.Port))
+			l, err := net.Listen("tcp", addr)
+			if err != nil {
+				return err
+			}
+			services := []*service{{"files", files.New(env.Config, db, fm), l}}
+
+			if tlsCfg := env.Config.Server.TLS; tlsCfg != nil && tlsCfg.RedirectPort != 0 {
+				l, err = net.Listen("tcp", ":"+strconv.Itoa(int(tlsCfg.RedirectPort)))
+				if err != nil {
+					closeListeners(services)
+					return err
+				}
+				services = append(services, &service{"redirect", files.NewRedirect(env.Config.Server.Port), l})
+			}
+
+			if env.Config.Admin != nil && env.Config.Admin.Listen != "" {
+				l, err = listener.Listen(env.Config.Admin.Listen, env.Config.Admin.SocketMode)
+				if err != nil {
+					closeListeners(services)
+					return err
+				}
+				services = append(services, &service{"admin", admin.New(env.Config, db, fm), l})
+			}
+
+			if env.Config.Metrics != nil && env.Config.Metrics.Listen != "" {
+				l, err = listener.Listen(env.Config.Metrics.Listen, env.Config.Metrics.SocketMode)
+				if err != nil {
+					closeListeners(services)
+					return err
+				}
+				services = append(services, &service{"metrics", metrics.NewServer(), l})
+			}
+
+			// the sockets are bound and the database is open,
+			// so root privileges are no longer needed
+			err = privileges.Drop(env.Config.Server)
+			if err != nil {
+				closeListeners(services)
+				return err
+			}
+
+			// stop on SIGINT or SIGTERM
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			timeout := env.Config.Server.ShutdownTimeout
+			if timeout <= 0 {
+				timeout = defaultShutdownTimeout
+			}
+			return serveAll(ctx, services, timeout)
+		},
+	}
+}
+
+// closeListeners closes the listeners of services which were not started.
+func closeListeners(services []*service) {
+	for _, svc := range services {
+		svc.listener.Close()
+	}
+}
+
+// closeOnReturn closes a resource, setting *err to the error
+// from closing it if it is nil. It is used with defer.
+func closeOnReturn(err *error, name string, close func() error) {
+	closeErr := close()
+	if closeErr != nil && *err == nil {
+		*err = fmt.Errorf("closing %s: %w", name, closeErr)
+	}
+}
+
+// serveAll runs each service concurrently. Once any of them stops, or the
+// context is done, all of them are shut down, waiting up to the timeout for
+// active requests to finish, and the first error encountered is returned.
+// The servers wait for the handlers of requests which did not finish in
+// time to return once their connections are closed, so the database and
+// file manager may be closed afterwards.
+func serveAll(ctx context.Context, services []*service, timeout time.Duration) error {
+	errs := make(chan error, len(services))
+	for _, svc := range services {
+		logging.Infof("%s server listening on %s", svc.name, svc.listener.Addr())
+		go func(svc *service) {
+			err := svc.server.Serve(svc.listener)
+			if err == http.ErrServerClosed {
+				err = nil
+			}
+			if err != nil {
+				err = fmt.Errorf("%s server: %w", svc.name, err)
+			}
+			errs <- err
+		}(svc)
+	}
+
+	// wait for the first service to stop, or a signal, then stop the rest
+	var first error
+	running := len(services)
+	select {
+	case first = <-errs:
+		running--
+	case <-ctx.Done():
+		logging.Infof("shutting down")
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	for _, svc := range services {
+		err := svc.server.Shutdown(shutdownCtx)
+		if err != nil && first == nil {
+			first = fmt.Errorf("shutting down %s server: %w", svc.name, err)
+		}
+	}
+	for i := 0; i < running; i++ {
+		err := <-errs
+		if err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"github.com/go-playground/assert/v2"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// testServer serves until it is shut down, or fails immediately.
+type testServer struct {
+	err      error
+	shutdown chan struct{}
+}
+
+func (s *testServer) Serve(l net.Listener) error {
+	defer l.Close()
+	if s.err != nil {
+		return s.err
+	}
+	<-s.shutdown
+	return http.ErrServerClosed
+}
+
+func (s *testServer) Shutdown(ctx context.Context) error {
+	close(s.shutdown)
+	return nil
+}
+
+func newTestServices(t *testing.T, errs ...error) []*service {
+	var services []*service
+	for _, err := range errs {
+		l, err2 := net.Listen("tcp", "127.0.0.1:0")
+		if err2 != nil {
+			t.Fatal(err2)
+		}
+		services = append(services, &service{"test", &testServer{err, make(chan struct{})}, l})
+	}
+	return services
+}
+
+func TestServeAll(t *testing.T) {
+	failure := errors.New("failure")
+
+	// the failure of one server shuts down the others
+	services := newTestServices(t, nil, failure, nil)
+	err := serveAll(context.Background(), services, time.Second)
+	assert.Equal(t, true, errors.Is(err, failure))
+
+	// cancelling the context shuts down all servers
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	services = newTestServices(t, nil, nil)
+	err = serveAll(ctx, services, time.Second)
+	assert.Equal(t, nil, err)
+}
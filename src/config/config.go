@@ -5,6 +5,7 @@ import (
 	"fsrv/src/database/entities"
 	"github.com/pelletier/go-toml"
 	"os"
+	"time"
 )
 
 var ErrNotFound = errors.New("no configuration file found")
@@ -12,12 +13,23 @@ var ErrNotFound = errors.New("no configuration file found")
 type DatabaseType string
 
 const (
-	DatabaseSQLite  DatabaseType = "sqlite"
-	DatabaseMariaDB DatabaseType = "mariadb"
+	DatabaseSQLite   DatabaseType = "sqlite"
+	DatabaseMariaDB  DatabaseType = "mariadb"
+	DatabasePostgres DatabaseType = "postgres"
+	DatabaseInMemory DatabaseType = "inmemory"
+)
+
+type ResourceLocatorType string
+
+const (
+	ResourceLocatorXAttr    ResourceLocatorType = "xattr"
+	ResourceLocatorDatabase ResourceLocatorType = "database"
 )
 
 type Config struct {
 	Server      *Server      `toml:"server"`
+	Admin       *Admin       `toml:"admin"`
+	Metrics     *Metrics     `toml:"metrics"`
 	FileManager *FileManager `toml:"file_manager"`
 	Database    *Database    `toml:"database"`
 	Cache       *Cache       `toml:"cache"`
@@ -26,6 +38,8 @@ type Config struct {
 
 type Server struct {
 	User                string              `toml:"user"`
+	Group               string              `toml:"group"`
+	AllowRoot           bool                `toml:"allow_root"`
 	Port                int16               `toml:"port"`
 	KeyValidationSecret string              `toml:"key_validation_secret"`
 	KeyRandomBytes      int                 `toml:"key_random_bytes"`
@@ -33,23 +47,65 @@ type Server struct {
 	IPAnonymousRL       *entities.RateLimit `toml:"ip_anonymous_rl"`
 	AuthAttemptRL       *entities.RateLimit `toml:"auth_attempt_rl"`
 	AuthDefaultRL       *entities.RateLimit `toml:"auth_default_rl"`
+	ExplainAccess       bool                `toml:"explain_access"`
+	ConcurrentReads     int                 `toml:"concurrent_read_limit"`
+	ConcurrentWrites    int                 `toml:"concurrent_write_limit"`
+	ShutdownTimeout     time.Duration       `toml:"shutdown_timeout"`
+	TLS                 *TLS                `toml:"tls"`
+}
+
+type TLS struct {
+	CertFile          string            `toml:"cert_file"`
+	KeyFile           string            `toml:"key_file"`
+	ReloadInterval    time.Duration     `toml:"reload_interval"`
+	RedirectPort      int16             `toml:"redirect_port"`
+	ClientCAFile      string            `toml:"client_ca_file"`
+	RequireClientCert bool              `toml:"require_client_cert"`
+	ClientKeys        map[string]string `toml:"client_keys"`
+}
+
+type Admin struct {
+	Listen     string `toml:"listen"`
+	SocketMode string `toml:"socket_mode"`
+}
+
+type Metrics struct {
+	Listen     string `toml:"listen"`
+	SocketMode string `toml:"socket_mode"`
 }
 
 type FileManager struct {
-	Path     string `toml:"path"`
-	MaxDepth int    `toml:"max_depth"`
+	Path            string              `toml:"path"`
+	MaxDepth        int                 `toml:"max_depth"`
+	DefaultAccess   string              `toml:"default_access"`
+	ResourceLocator ResourceLocatorType `toml:"resource_locator"`
+	Trash           bool                `toml:"trash"`
+	TrashRetention  time.Duration       `toml:"trash_retention"`
 }
 
 type Database struct {
-	Type             DatabaseType `toml:"type"`
-	Version          int          `toml:"version"`
-	ConnectionString string       `toml:"connection_string"`
+	Type DatabaseType `toml:"type"`
+	// Version is the schema version which the database is upgraded to
+	// automatically when opened, or 0 to upgrade it to the latest version.
+	Version          int    `toml:"version"`
+	ConnectionString string `toml:"connection_string"`
+	// MaxOpenConnections, MaxIdleConnections and ConnectionMaxLifetime
+	// configure the connection pool of server databases, such as MariaDB and PostgreSQL.
+	MaxOpenConnections    int           `toml:"max_open_connections"`
+	MaxIdleConnections    int           `toml:"max_idle_connections"`
+	ConnectionMaxLifetime time.Duration `toml:"connection_max_lifetime"`
+	// SeedFile is a JSON or TOML file of keys, roles, resources and rate
+	// limits, which an in-memory database is filled with when created.
+	SeedFile string `toml:"seed_file"`
 }
 
 type Cache struct {
 	Keys             int    `toml:"keys"`
 	PermissionIDs    int    `toml:"permission_ids"`
 	PermissionIDHash string `toml:"permission_id_hash"`
+	// TTL is how long cached keys, roles, resources and other data are
+	// used before being read from the database again, or 0 for no limit.
+	TTL time.Duration `toml:"ttl"`
 }
 
 type Logging struct {
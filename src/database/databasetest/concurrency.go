@@ -0,0 +1,74 @@
+package databasetest
+
+import (
+	"fmt"
+	"fsrv/src/database"
+	"fsrv/src/database/entities"
+	"fsrv/src/types"
+	"github.com/go-playground/assert/v2"
+	"sync"
+	"testing"
+)
+
+// concurrentWorkers is the number of goroutines which use a database at once.
+const concurrentWorkers = 8
+
+func testConcurrency(t *testing.T, db database.DBInterface) {
+	createRoles(t, db, map[string]int{"role": 1})
+	must(t, db.CreateResource(&entities.Resource{ID: "res"}))
+	read := &entities.Permission{ResourceID: "res", TypeRWMD: types.OperationRead, Status: true}
+
+	// each worker reads what the others write, which must
+	// neither fail nor be cached once it is stale
+	var wg sync.WaitGroup
+	errs := make(chan error, concurrentWorkers)
+	for i := 0; i < concurrentWorkers; i++ {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			errs <- func() error {
+				if err := db.CreateKey(newKey(id)); err != nil {
+					return err
+				}
+				if err := db.GiveRole(id, "role"); err != nil {
+					return err
+				}
+				if _, err := db.GetResourceData("res"); err != nil {
+					return err
+				}
+				if err := db.GrantPermission(read, id); err != nil {
+					return err
+				}
+				if _, err := db.GetKeyData(id); err != nil {
+					return err
+				}
+				if _, err := db.GetResourceData("res"); err != nil {
+					return err
+				}
+				return db.SetResourcePath("/"+id, "res")
+			}()
+		}(fmt.Sprintf("key%d", i))
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.Equal(t, nil, err)
+	}
+
+	wantNodes := nodes{}
+	var wantIDs []string
+	for i := 0; i < concurrentWorkers; i++ {
+		id := fmt.Sprintf("key%d", i)
+		wantIDs = append(wantIDs, id)
+		wantNodes[entities.ResourceOperationAccess{ID: id, Type: types.OperationRead}] = true
+
+		assert.Equal(t, []string{"role", id}, keyRoles(t, db, id))
+		resourceID, err := db.GetPathResourceID("/" + id)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, "res", resourceID)
+	}
+	ids, err := db.GetKeyIDs(2*concurrentWorkers, 0)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, wantIDs, sorted(ids))
+	assert.Equal(t, wantNodes, resourceNodes(t, db, "res"))
+}
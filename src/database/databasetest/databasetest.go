@@ -0,0 +1,106 @@
+// Package databasetest tests that implementations of database.DBInterface
+// behave identically, so that the server may use any of them, including
+// the results and errors of each method, paging, and concurrent use.
+package databasetest
+
+import (
+	"fsrv/src/database"
+	"fsrv/src/database/entities"
+	"fsrv/utils/serde"
+	"github.com/go-playground/assert/v2"
+	"sort"
+	"testing"
+	"time"
+)
+
+// Factory creates an empty database for a test. The
+// database should be closed when the test is cleaned up.
+type Factory func(t *testing.T) database.DBInterface
+
+// Run runs the conformance tests against databases created by newDB,
+// each test with a new database.
+func Run(t *testing.T, newDB Factory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, db database.DBInterface)
+	}{
+		{"Keys", testKeys},
+		{"Roles", testRoles},
+		{"Resources", testResources},
+		{"Permissions", testPermissions},
+		{"RateLimits", testRateLimits},
+		{"Tokens", testTokens},
+		{"ResourcePaths", testResourcePaths},
+		{"Pagination", testPagination},
+		{"Concurrency", testConcurrency},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			test.fn(t, newDB(t))
+		})
+	}
+}
+
+// must fails the test immediately if err is not nil, for setup
+// steps which the rest of a test depends on.
+func must(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("setup failed: %v", err)
+	}
+}
+
+// createRoles creates roles with the precedence they are mapped to.
+func createRoles(t *testing.T, db database.DBInterface, roles map[string]int) {
+	t.Helper()
+	for id, precedence := range roles {
+		must(t, db.CreateRole(&entities.Role{ID: id, Precedence: precedence}))
+	}
+}
+
+// newKey returns a key without roles, created now, which expires in an hour.
+func newKey(id string) *entities.Key {
+	now := time.Now()
+	return &entities.Key{
+		ID:        id,
+		Comment:   "key " + id,
+		ExpiresAt: serde.Time(now.Add(time.Hour)),
+		CreatedAt: serde.Time(now),
+	}
+}
+
+// assertKey asserts that a key read from a database matches the key it was
+// created from, other than its roles, with times to the millisecond.
+func assertKey(t *testing.T, want *entities.Key, got *entities.Key) {
+	t.Helper()
+	assert.Equal(t, want.ID, got.ID)
+	assert.Equal(t, want.Comment, got.Comment)
+	assert.Equal(t, want.RateLimitID, got.RateLimitID)
+	assert.Equal(t, time.Time(want.ExpiresAt).UnixMilli(), time.Time(got.ExpiresAt).UnixMilli())
+	assert.Equal(t, time.Time(want.CreatedAt).UnixMilli(), time.Time(got.CreatedAt).UnixMilli())
+}
+
+// keyRoles returns the roles of a key, in order of precedence.
+func keyRoles(t *testing.T, db database.DBInterface, keyID string) []string {
+	t.Helper()
+	key, err := db.GetKeyData(keyID)
+	must(t, err)
+	return key.Roles
+}
+
+// resourceNodes returns the operation nodes of a resource.
+func resourceNodes(t *testing.T, db database.DBInterface, resourceID string) map[entities.ResourceOperationAccess]bool {
+	t.Helper()
+	resource, err := db.GetResourceData(resourceID)
+	must(t, err)
+	return resource.OperationNodes
+}
+
+// sorted returns a sorted copy of ids, for comparing pages
+// whose order the interface does not define.
+func sorted(ids []string) []string {
+	ids = append([]string(nil), ids...)
+	sort.Strings(ids)
+	return ids
+}
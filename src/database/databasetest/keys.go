@@ -0,0 +1,64 @@
+package databasetest
+
+import (
+	"fsrv/src/database"
+	"fsrv/src/database/entities"
+	"fsrv/utils/serde"
+	"github.com/go-playground/assert/v2"
+	"testing"
+	"time"
+)
+
+func testKeys(t *testing.T, db database.DBInterface) {
+	createRoles(t, db, map[string]int{"low": 10, "high": 20})
+	must(t, db.CreateRateLimit(&entities.RateLimit{ID: "default", Limit: 10, Burst: 10, Refill: serde.Duration(time.Minute)}))
+
+	key := newKey("key")
+	key.Roles = []string{"high", "low"}
+	key.RateLimitID = "default"
+	assert.Equal(t, nil, db.CreateKey(key))
+	assert.Equal(t, database.ErrKeyDuplicate, db.CreateKey(key))
+
+	got, err := db.GetKeyData("key")
+	assert.Equal(t, nil, err)
+	assertKey(t, key, got)
+	// roles are ordered by precedence, followed by the KeyRole
+	assert.Equal(t, []string{"low", "high", "key"}, got.Roles)
+
+	rateLimitID, err := db.GetKeyRateLimitID("key")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "default", rateLimitID)
+
+	// a key with a missing role is not created
+	invalid := newKey("invalid")
+	invalid.Roles = []string{"missing"}
+	assert.Equal(t, database.ErrRoleMissing, db.CreateKey(invalid))
+	_, err = db.GetKeyData("invalid")
+	assert.Equal(t, database.ErrKeyMissing, err)
+	_, err = db.GetKeyRateLimitID("invalid")
+	assert.Equal(t, database.ErrKeyMissing, err)
+
+	key.Comment = "updated"
+	key.ExpiresAt = serde.Time(time.Now().Add(2 * time.Hour))
+	assert.Equal(t, nil, db.UpdateKey(key))
+	got, err = db.GetKeyData("key")
+	assert.Equal(t, nil, err)
+	assertKey(t, key, got)
+	assert.Equal(t, database.ErrKeyMissing, db.UpdateKey(invalid))
+
+	must(t, db.CreateKey(newKey("other")))
+	ids, err := db.GetKeyIDs(10, 0)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"key", "other"}, sorted(ids))
+	keys, err := db.GetKeys(10, 0)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(keys))
+
+	assert.Equal(t, nil, db.DeleteKey("key"))
+	_, err = db.GetKeyData("key")
+	assert.Equal(t, database.ErrKeyMissing, err)
+	assert.Equal(t, database.ErrKeyMissing, db.DeleteKey("key"))
+	ids, err = db.GetKeyIDs(10, 0)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"other"}, ids)
+}
@@ -0,0 +1,94 @@
+package databasetest
+
+import (
+	"fmt"
+	"fsrv/src/database"
+	"fsrv/src/database/entities"
+	"fsrv/utils/serde"
+	"github.com/go-playground/assert/v2"
+	"testing"
+	"time"
+)
+
+// pageCount is the number of each entity created to be paged through.
+const pageCount = 5
+
+// collectPages reads every page of ids of a size, checking that only the
+// last page is short, and returns the ids in the order they were paged.
+func collectPages(t *testing.T, pageSize int, getPage func(pageSize int, offset int) ([]string, error)) []string {
+	t.Helper()
+	var ids []string
+	for offset := 0; ; offset += pageSize {
+		page, err := getPage(pageSize, offset)
+		assert.Equal(t, nil, err)
+		ids = append(ids, page...)
+		if len(page) < pageSize {
+			break
+		}
+		if offset > 2*pageCount {
+			t.Fatal("pages did not end")
+		}
+	}
+
+	page, err := getPage(pageSize, len(ids)+pageSize)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(page))
+	return ids
+}
+
+func testPagination(t *testing.T, db database.DBInterface) {
+	start := time.Now().Add(-time.Hour)
+	var keyIDs, roleIDs, resourceIDs, tokenIDs []string
+	// entities are created in the reverse of their expected order, so
+	// that an order by insertion does not pass
+	for i := pageCount - 1; i >= 0; i-- {
+		created := serde.Time(start.Add(time.Duration(i) * time.Second))
+		id := fmt.Sprintf("%c%d", 'a'+pageCount-i, i)
+		keyIDs = append([]string{"key" + id}, keyIDs...)
+		roleIDs = append([]string{"role" + id}, roleIDs...)
+		resourceIDs = append(resourceIDs, "resource"+id)
+		tokenIDs = append([]string{"token" + id}, tokenIDs...)
+
+		key := newKey("key" + id)
+		key.CreatedAt = created
+		must(t, db.CreateKey(key))
+		must(t, db.CreateRole(&entities.Role{ID: "role" + id, Precedence: i}))
+		must(t, db.CreateResource(&entities.Resource{ID: "resource" + id}))
+		must(t, db.CreateToken(&entities.Token{ID: "token" + id, ExpiresAt: serde.Time(time.Unix(0, 0)), CreatedAt: created}))
+	}
+
+	for _, pageSize := range []int{1, 2, pageCount, pageCount + 1} {
+		t.Run(fmt.Sprint(pageSize), func(t *testing.T) {
+			// keys and tokens are ordered by creation, and roles by precedence
+			assert.Equal(t, keyIDs, collectPages(t, pageSize, db.GetKeyIDs))
+			assert.Equal(t, roleIDs, collectPages(t, pageSize, db.GetRoles))
+			assert.Equal(t, keyIDs, collectPages(t, pageSize, func(pageSize int, offset int) ([]string, error) {
+				keys, err := db.GetKeys(pageSize, offset)
+				ids := make([]string, len(keys))
+				for i, key := range keys {
+					ids[i] = key.ID
+				}
+				return ids, err
+			}))
+			assert.Equal(t, tokenIDs, collectPages(t, pageSize, func(pageSize int, offset int) ([]string, error) {
+				tokens, err := db.GetTokens(pageSize, offset)
+				ids := make([]string, len(tokens))
+				for i, token := range tokens {
+					ids[i] = token.ID
+				}
+				return ids, err
+			}))
+
+			// the order of resources is not defined, but must be consistent across pages
+			assert.Equal(t, sorted(resourceIDs), sorted(collectPages(t, pageSize, db.GetResourceIDs)))
+			assert.Equal(t, sorted(resourceIDs), sorted(collectPages(t, pageSize, func(pageSize int, offset int) ([]string, error) {
+				resources, err := db.GetResources(pageSize, offset)
+				ids := make([]string, len(resources))
+				for i, resource := range resources {
+					ids[i] = resource.ID
+				}
+				return ids, err
+			})))
+		})
+	}
+}
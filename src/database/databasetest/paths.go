@@ -0,0 +1,85 @@
+package databasetest
+
+import (
+	"fsrv/src/database"
+	"fsrv/src/database/entities"
+	"github.com/go-playground/assert/v2"
+	"testing"
+)
+
+// assertPaths asserts the resource id attached to each path, where
+// an empty id means no resource is attached.
+func assertPaths(t *testing.T, db database.DBInterface, paths map[string]string) {
+	t.Helper()
+	for path, want := range paths {
+		got, err := db.GetPathResourceID(path)
+		if want == "" {
+			assert.Equal(t, database.ErrResourcePathMissing, err)
+			continue
+		}
+		assert.Equal(t, nil, err)
+		assert.Equal(t, want, got)
+	}
+}
+
+func testResourcePaths(t *testing.T, db database.DBInterface) {
+	_, err := db.GetPathResourceID("/missing")
+	assert.Equal(t, database.ErrResourcePathMissing, err)
+
+	for path, id := range map[string]string{
+		"/dir":           "a",
+		"/dir/file.txt":  "b",
+		"/dir/ü/ö.txt":   "c",
+		"/directory.txt": "d",
+		"/dest/old.txt":  "e",
+	} {
+		assert.Equal(t, nil, db.SetResourcePath(path, id))
+	}
+	// setting a path replaces its resource
+	assert.Equal(t, nil, db.SetResourcePath("/dest", "x"))
+	assert.Equal(t, nil, db.SetResourcePath("/dest", "f"))
+	assertPaths(t, db, map[string]string{"/dest": "f"})
+
+	// moving a path moves its descendants, but not paths which share its prefix
+	assert.Equal(t, nil, db.MoveResourcePaths("/dir", "/moved"))
+	assertPaths(t, db, map[string]string{
+		"/dir":             "",
+		"/dir/file.txt":    "",
+		"/moved":           "a",
+		"/moved/file.txt":  "b",
+		"/moved/ü/ö.txt":   "c",
+		"/directory.txt":   "d",
+		"/movedirectory":   "",
+		"/moved/directory": "",
+	})
+	assert.Equal(t, nil, db.MoveResourcePaths("/missing", "/elsewhere"))
+
+	// moving onto existing paths replaces them
+	assert.Equal(t, nil, db.SetResourcePath("/moved/old.txt", "g"))
+	assert.Equal(t, nil, db.MoveResourcePaths("/moved", "/dest"))
+	assertPaths(t, db, map[string]string{
+		"/dest":          "a",
+		"/dest/file.txt": "b",
+		"/dest/old.txt":  "g",
+		"/moved":         "",
+	})
+
+	assert.Equal(t, nil, db.DeleteResourcePath("/dest/file.txt"))
+	assert.Equal(t, nil, db.DeleteResourcePath("/dest/file.txt"))
+	assertPaths(t, db, map[string]string{"/dest/file.txt": "", "/dest": "a"})
+
+	assert.Equal(t, nil, db.DeleteResourcePaths("/dest"))
+	assert.Equal(t, nil, db.DeleteResourcePaths("/missing"))
+	assertPaths(t, db, map[string]string{
+		"/dest":          "",
+		"/dest/ü/ö.txt":  "",
+		"/dest/old.txt":  "",
+		"/directory.txt": "d",
+	})
+
+	// deleting a resource detaches it from its paths
+	must(t, db.CreateResource(&entities.Resource{ID: "d"}))
+	assert.Equal(t, nil, db.SetResourcePath("/other.txt", "d"))
+	assert.Equal(t, nil, db.DeleteResource("d"))
+	assertPaths(t, db, map[string]string{"/directory.txt": "", "/other.txt": ""})
+}
@@ -0,0 +1,52 @@
+package databasetest
+
+import (
+	"fsrv/src/database"
+	"fsrv/src/database/entities"
+	"fsrv/utils/serde"
+	"github.com/go-playground/assert/v2"
+	"testing"
+	"time"
+)
+
+func testRateLimits(t *testing.T, db database.DBInterface) {
+	limit := &entities.RateLimit{ID: "limit", Limit: 10, Burst: 5, Refill: serde.Duration(90 * time.Second)}
+	assert.Equal(t, nil, db.CreateRateLimit(limit))
+	assert.Equal(t, database.ErrRateLimitDuplicate, db.CreateRateLimit(limit))
+	other := &entities.RateLimit{ID: "other", Limit: 1, Burst: 1, Refill: serde.Duration(time.Millisecond)}
+	must(t, db.CreateRateLimit(other))
+
+	got, err := db.GetRateLimitData("limit")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, limit, got)
+	_, err = db.GetRateLimitData("missing")
+	assert.Equal(t, database.ErrRateLimitMissing, err)
+
+	key := newKey("key")
+	key.RateLimitID = "limit"
+	must(t, db.CreateKey(key))
+
+	// keys follow a rate limit when it is renamed
+	renamed := &entities.RateLimit{ID: "renamed", Limit: 20, Burst: 10, Refill: serde.Duration(time.Second)}
+	assert.Equal(t, nil, db.UpdateRateLimit("limit", renamed))
+	got, err = db.GetRateLimitData("renamed")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, renamed, got)
+	_, err = db.GetRateLimitData("limit")
+	assert.Equal(t, database.ErrRateLimitMissing, err)
+	rateLimitID, err := db.GetKeyRateLimitID("key")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "renamed", rateLimitID)
+
+	assert.Equal(t, database.ErrRateLimitMissing, db.UpdateRateLimit("limit", renamed))
+	assert.Equal(t, database.ErrRateLimitDuplicate, db.UpdateRateLimit("renamed", other))
+
+	assert.Equal(t, nil, db.SetRateLimit(key, "other"))
+	rateLimitID, err = db.GetKeyRateLimitID("key")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "other", rateLimitID)
+
+	assert.Equal(t, nil, db.DeleteRateLimit("renamed"))
+	_, err = db.GetRateLimitData("renamed")
+	assert.Equal(t, database.ErrRateLimitMissing, err)
+}
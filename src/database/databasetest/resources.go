@@ -0,0 +1,85 @@
+package databasetest
+
+import (
+	"fsrv/src/database"
+	"fsrv/src/database/entities"
+	"fsrv/src/types"
+	"github.com/go-playground/assert/v2"
+	"testing"
+)
+
+type nodes = map[entities.ResourceOperationAccess]bool
+
+func testResources(t *testing.T, db database.DBInterface) {
+	assert.Equal(t, database.ErrResourceNameBad, db.CreateResource(&entities.Resource{ID: ""}))
+
+	// nodes may name roles and keys which do not exist
+	resource := &entities.Resource{
+		ID:    "res",
+		Flags: entities.FlagPublicRead,
+		OperationNodes: nodes{
+			{ID: "role", Type: types.OperationRead}:  true,
+			{ID: "*", Type: types.OperationWrite}:    false,
+			{ID: "key", Type: types.OperationDelete}: true,
+		},
+	}
+	assert.Equal(t, nil, db.CreateResource(resource))
+	assert.Equal(t, database.ErrResourceDuplicate, db.CreateResource(resource))
+
+	got, err := db.GetResourceData("res")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, resource, got)
+	_, err = db.GetResourceData("missing")
+	assert.Equal(t, database.ErrResourceMissing, err)
+
+	empty := &entities.Resource{ID: "empty", OperationNodes: nodes{}}
+	assert.Equal(t, nil, db.CreateResource(empty))
+	got, err = db.GetResourceData("empty")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, empty, got)
+
+	ids, err := db.GetResourceIDs(10, 0)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"empty", "res"}, sorted(ids))
+	resources, err := db.GetResources(10, 0)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(resources))
+
+	assert.Equal(t, nil, db.DeleteResource("res"))
+	_, err = db.GetResourceData("res")
+	assert.Equal(t, database.ErrResourceMissing, err)
+	assert.Equal(t, database.ErrResourceMissing, db.DeleteResource("res"))
+	ids, err = db.GetResourceIDs(10, 0)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"empty"}, ids)
+}
+
+func testPermissions(t *testing.T, db database.DBInterface) {
+	must(t, db.CreateResource(&entities.Resource{
+		ID:             "res",
+		OperationNodes: nodes{{ID: "a", Type: types.OperationRead}: true},
+	}))
+
+	// granting replaces the existing node of a role for the operation
+	read := &entities.Permission{ResourceID: "res", TypeRWMD: types.OperationRead, Status: false}
+	assert.Equal(t, nil, db.GrantPermission(read, "a", "b"))
+	write := &entities.Permission{ResourceID: "res", TypeRWMD: types.OperationWrite, Status: true}
+	assert.Equal(t, nil, db.GrantPermission(write, "a"))
+	assert.Equal(t, nodes{
+		{ID: "a", Type: types.OperationRead}:  false,
+		{ID: "b", Type: types.OperationRead}:  false,
+		{ID: "a", Type: types.OperationWrite}: true,
+	}, resourceNodes(t, db, "res"))
+
+	// revoking removes the node of either status
+	assert.Equal(t, nil, db.RevokePermission(&entities.Permission{ResourceID: "res", TypeRWMD: types.OperationRead, Status: true}, "a"))
+	assert.Equal(t, nil, db.RevokePermission(read, "c"))
+	assert.Equal(t, nodes{
+		{ID: "b", Type: types.OperationRead}:  false,
+		{ID: "a", Type: types.OperationWrite}: true,
+	}, resourceNodes(t, db, "res"))
+
+	assert.Equal(t, nil, db.RevokePermission(read, "b"))
+	assert.Equal(t, nil, db.RevokePermission(write, "a"))
+	assert.Equal(t, nodes{}, resourceNodes(t, db, "res"))
+}
@@ -0,0 +1,52 @@
+package databasetest
+
+import (
+	"fsrv/src/database"
+	"fsrv/src/database/entities"
+	"github.com/go-playground/assert/v2"
+	"testing"
+)
+
+func testRoles(t *testing.T, db database.DBInterface) {
+	assert.Equal(t, database.ErrRoleNameBad, db.CreateRole(&entities.Role{ID: ""}))
+	assert.Equal(t, database.ErrRoleNameBad, db.CreateRole(&entities.Role{ID: "*"}))
+
+	role := &entities.Role{ID: "a", Precedence: 5}
+	assert.Equal(t, nil, db.CreateRole(role))
+	assert.Equal(t, database.ErrRoleDuplicate, db.CreateRole(role))
+	must(t, db.CreateRole(&entities.Role{ID: "b", Precedence: 1}))
+
+	got, err := db.GetRoleData("a")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, role, got)
+	_, err = db.GetRoleData("missing")
+	assert.Equal(t, database.ErrRoleMissing, err)
+
+	// KeyRoles are not listed or returned as roles
+	must(t, db.CreateKey(newKey("key")))
+	roles, err := db.GetRoles(10, 0)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"b", "a"}, roles)
+	_, err = db.GetRoleData("key")
+	assert.Equal(t, database.ErrRoleMissing, err)
+
+	assert.Equal(t, nil, db.GiveRole("key", "a", "b"))
+	assert.Equal(t, []string{"b", "a", "key"}, keyRoles(t, db, "key"))
+	assert.Equal(t, nil, db.GiveRole("key", "a"))
+	assert.Equal(t, []string{"b", "a", "key"}, keyRoles(t, db, "key"))
+	assert.Equal(t, database.ErrRoleMissing, db.GiveRole("key", "missing"))
+	assert.Equal(t, database.ErrRoleMissing, db.GiveRole("key", "key"))
+	assert.Equal(t, database.ErrKeyMissing, db.GiveRole("missing", "a"))
+
+	assert.Equal(t, nil, db.TakeRole("key", "a"))
+	assert.Equal(t, []string{"b", "key"}, keyRoles(t, db, "key"))
+	assert.Equal(t, database.ErrRoleNameBad, db.TakeRole("key", "key"))
+	assert.Equal(t, database.ErrKeyMissing, db.TakeRole("missing", "b"))
+
+	assert.Equal(t, nil, db.DeleteRole("b"))
+	assert.Equal(t, []string{"key"}, keyRoles(t, db, "key"))
+	_, err = db.GetRoleData("b")
+	assert.Equal(t, database.ErrRoleMissing, err)
+	assert.Equal(t, database.ErrRoleMissing, db.DeleteRole("b"))
+	assert.Equal(t, database.ErrRoleMissing, db.DeleteRole("key"))
+}
@@ -0,0 +1,48 @@
+package databasetest
+
+import (
+	"fsrv/src/database"
+	"fsrv/src/database/entities"
+	"fsrv/utils/serde"
+	"github.com/go-playground/assert/v2"
+	"testing"
+	"time"
+)
+
+func testTokens(t *testing.T, db database.DBInterface) {
+	now := time.Now()
+	token := &entities.Token{ID: "token", Comment: "admin", ExpiresAt: serde.Time(time.Unix(0, 0)), CreatedAt: serde.Time(now)}
+	assert.Equal(t, nil, db.CreateToken(token))
+	assert.Equal(t, database.ErrTokenDuplicate, db.CreateToken(token))
+	assert.NotEqual(t, nil, db.CreateToken(&entities.Token{ID: ""}))
+
+	got, err := db.GetTokenData("token")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "token", got.ID)
+	assert.Equal(t, "admin", got.Comment)
+	assert.Equal(t, false, got.IsExpired())
+	assert.Equal(t, now.UnixMilli(), time.Time(got.CreatedAt).UnixMilli())
+	_, err = db.GetTokenData("missing")
+	assert.Equal(t, database.ErrTokenMissing, err)
+
+	expired := &entities.Token{ID: "expired", ExpiresAt: serde.Time(now.Add(-time.Hour)), CreatedAt: serde.Time(now.Add(-2 * time.Hour))}
+	must(t, db.CreateToken(expired))
+	got, err = db.GetTokenData("expired")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, got.IsExpired())
+
+	// tokens are listed in order of creation
+	tokens, err := db.GetTokens(10, 0)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(tokens))
+	assert.Equal(t, "expired", tokens[0].ID)
+	assert.Equal(t, "token", tokens[1].ID)
+
+	assert.Equal(t, nil, db.DeleteToken("token"))
+	_, err = db.GetTokenData("token")
+	assert.Equal(t, database.ErrTokenMissing, err)
+	assert.Equal(t, database.ErrTokenMissing, db.DeleteToken("token"))
+	tokens, err = db.GetTokens(10, 0)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, len(tokens))
+}
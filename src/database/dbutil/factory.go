@@ -4,28 +4,85 @@ import (
 	"errors"
 	"fsrv/src/config"
 	"fsrv/src/database"
+	"fsrv/src/database/impl/inmemory"
+	"fsrv/src/database/impl/mariadb"
+	"fsrv/src/database/impl/postgres"
 	"fsrv/src/database/impl/sqlite"
 	"io/fs"
 	"os"
 	"path/filepath"
 )
 
+// Create opens a database, creating it if it does not exist. Its schema is
+// upgraded as the database is opened, up to the configured version.
 func Create(cfg *config.Database) (database.DBInterface, error) {
 	if cfg.Type == config.DatabaseSQLite {
-		db, err := sqlite.Open(cfg.ConnectionString)
-		if err != nil {
+		_, err := os.Stat(cfg.ConnectionString)
+		if errors.Is(err, fs.ErrNotExist) {
 			err = createFile(cfg.ConnectionString)
-			if err != nil {
-				return nil, err
-			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		return sqlite.Open(cfg.ConnectionString, cfg.Version)
+	}
+	if cfg.Type == config.DatabaseMariaDB {
+		return mariadb.Open(cfg)
+	}
+	if cfg.Type == config.DatabasePostgres {
+		return postgres.Open(cfg)
+	}
+	if cfg.Type == config.DatabaseInMemory {
+		return inmemory.Create(cfg)
+	}
+	return nil, errors.New("invalid database type")
+}
 
-			return sqlite.Create(cfg.ConnectionString)
+// Open opens an existing database, unlike Create, which creates
+// the database if it does not exist.
+func Open(cfg *config.Database) (database.DBInterface, error) {
+	if cfg.Type == config.DatabaseSQLite {
+		_, err := os.Stat(cfg.ConnectionString)
+		if err != nil {
+			return nil, err
 		}
-		return db, err
+		return sqlite.Open(cfg.ConnectionString, cfg.Version)
+	}
+	if cfg.Type == config.DatabaseMariaDB {
+		return mariadb.Open(cfg)
+	}
+	if cfg.Type == config.DatabasePostgres {
+		return postgres.Open(cfg)
+	}
+	if cfg.Type == config.DatabaseInMemory {
+		// an in-memory database has nothing to open, so it is always created
+		return inmemory.Create(cfg)
 	}
 	return nil, errors.New("invalid database type")
 }
 
+// Migrate upgrades or downgrades the schema of an existing database to version,
+// or to the latest version if 0, returning the versions it was migrated from and to.
+func Migrate(cfg *config.Database, version int) (from int, to int, err error) {
+	if cfg.Type == config.DatabaseSQLite {
+		_, err := os.Stat(cfg.ConnectionString)
+		if err != nil {
+			return 0, 0, err
+		}
+		return sqlite.Migrate(cfg.ConnectionString, version)
+	}
+	if cfg.Type == config.DatabaseMariaDB {
+		return mariadb.Migrate(cfg, version)
+	}
+	if cfg.Type == config.DatabasePostgres {
+		return postgres.Migrate(cfg, version)
+	}
+	if cfg.Type == config.DatabaseInMemory {
+		return 0, 0, errors.New("an in-memory database has no schema to migrate")
+	}
+	return 0, 0, errors.New("invalid database type")
+}
+
 func createFile(path string) error {
 	dir := filepath.Dir(path)
 	if dir != "" && dir != "." {
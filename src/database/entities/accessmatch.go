@@ -0,0 +1,65 @@
+package entities
+
+import "fmt"
+
+// MatchRule is the rule of a resource which decided an access status.
+type MatchRule string
+
+const (
+	// MatchPublicRead means the resource allows anyone to read it.
+	MatchPublicRead MatchRule = "public_read"
+	// MatchAnonymous means access was denied because no key was used.
+	MatchAnonymous MatchRule = "anonymous"
+	// MatchAny means the node for any key ("*") matched.
+	MatchAny MatchRule = "any"
+	// MatchKey means the node for the key itself matched.
+	MatchKey MatchRule = "key"
+	// MatchRole means the node for one of the roles of the key matched.
+	MatchRole MatchRule = "role"
+	// MatchNone means no node matched, so access is inherited.
+	MatchNone MatchRule = "none"
+)
+
+// AccessMatch explains the access status a resource gives a key.
+type AccessMatch struct {
+	// Status is the access status given by the resource.
+	Status AccessStatus `json:"status"`
+	// Rule is the rule which decided the status.
+	Rule MatchRule `json:"rule"`
+	// Node is the node which matched, if the rule matches nodes.
+	Node *ResourceOperationAccess `json:"node,omitempty"`
+}
+
+func newNodeMatch(rule MatchRule, node ResourceOperationAccess, allow bool) AccessMatch {
+	status := AccessDenied
+	if allow {
+		status = AccessAllowed
+	}
+	return AccessMatch{Status: status, Rule: rule, Node: &node}
+}
+
+// accessStatusNames are the names of the access statuses, by value + 1.
+var accessStatusNames = [...]string{"denied", "neutral", "allowed"}
+
+// String returns the name of an access status, as used in the API.
+func (s AccessStatus) String() string {
+	if s < AccessDenied || s > AccessAllowed {
+		return "unknown"
+	}
+	return accessStatusNames[s+1]
+}
+
+// MarshalText encodes an access status as its name.
+func (s AccessStatus) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *AccessStatus) UnmarshalText(text []byte) error {
+	for i, name := range accessStatusNames {
+		if name == string(text) {
+			*s = AccessStatus(i - 1)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown access status '%s'", text)
+}
@@ -1,6 +1,10 @@
 package entities
 
-import "fsrv/src/types"
+import (
+	"errors"
+	"fsrv/src/types"
+	"strings"
+)
 
 type AccessStatus int8
 
@@ -35,56 +39,74 @@ type ResourceOperationAccess struct {
 	Type types.OperationType
 }
 
+// MarshalText encodes a node as "<operation>:<id>", so that
+// nodes may be used as keys in JSON objects.
+func (r ResourceOperationAccess) MarshalText() ([]byte, error) {
+	return []byte(r.Type.String() + ":" + r.ID), nil
+}
+
+func (r *ResourceOperationAccess) UnmarshalText(text []byte) error {
+	opName, id, ok := strings.Cut(string(text), ":")
+	if !ok {
+		return errors.New("expected a node in the form <operation>:<id>")
+	}
+
+	op, err := types.ParseOperationType(opName)
+	if err != nil {
+		return err
+	}
+	r.ID = id
+	r.Type = op
+	return nil
+}
+
 func (r *Resource) PublicCanRead() bool {
 	return (r.Flags & FlagPublicRead) == FlagPublicRead
 }
 
 // CheckAccess checks if a given key (may be nil) has access to perform a particular operation on this resource.
 func (r *Resource) CheckAccess(key *Key, op types.OperationType) AccessStatus {
+	return r.ExplainAccess(key, op).Status
+}
+
+// ExplainAccess returns the access status of a given key (may be nil) for a particular operation on this
+// resource, along with the rule, and node if any, which decided it.
+func (r *Resource) ExplainAccess(key *Key, op types.OperationType) AccessMatch {
 	if key == nil {
 		// allow public reads
 		if op == types.OperationRead && r.PublicCanRead() {
-			return AccessAllowed
+			return AccessMatch{Status: AccessAllowed, Rule: MatchPublicRead}
 		}
-		return AccessDenied
+		return AccessMatch{Status: AccessDenied, Rule: MatchAnonymous}
 	}
 
 	return r.checkKeyAccess(key, op)
 }
 
 // checkKeyAccess returns the access status for a particular role in an access map.
-func (r *Resource) checkKeyAccess(key *Key, op types.OperationType) AccessStatus {
+func (r *Resource) checkKeyAccess(key *Key, op types.OperationType) AccessMatch {
 	roa := ResourceOperationAccess{"*", op}
 	// if catch-all role is present, allow or deny based on its status.
 	if status, ok := r.OperationNodes[roa]; ok {
-		if status {
-			return AccessAllowed
-		}
-		return AccessDenied
+		return newNodeMatch(MatchAny, roa, status)
 	}
 
 	// if the key itself is present, allow or deny based on its status.
 	roa.ID = key.ID
 	if status, ok := r.OperationNodes[roa]; ok {
-		if status {
-			return AccessAllowed
-		}
-		return AccessDenied
+		return newNodeMatch(MatchKey, roa, status)
 	}
 
 	// check sorted key roles, returning allow or deny based on the first present in the access map.
 	for _, role := range key.Roles {
 		roa.ID = role
 		if status, ok := r.OperationNodes[roa]; ok {
-			if status {
-				return AccessAllowed
-			}
-			return AccessDenied
+			return newNodeMatch(MatchRole, roa, status)
 		}
 	}
 
 	// no access specifiers on this level
-	return AccessNeutral
+	return AccessMatch{Status: AccessNeutral, Rule: MatchNone}
 }
 
 func (r *Resource) GetID() string {
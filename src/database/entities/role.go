@@ -3,8 +3,11 @@ package entities
 import "fsrv/src/types"
 
 type Role struct {
-	ID         string
-	Precedence int
+	// ID is the name of the role.
+	ID string `json:"id"`
+	// Precedence determines the order in which a key's roles are checked
+	// for access to a resource, from lowest to highest.
+	Precedence int `json:"precedence"`
 }
 
 type Permission struct {
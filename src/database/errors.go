@@ -10,10 +10,17 @@ var (
 	ErrKeyDuplicate      = errors.New("A key with the given ID already exists")
 	ErrRoleDuplicate     = errors.New("A role with the given ID already exists")
 	ErrResourceDuplicate = errors.New("A resource with the given ID already exists")
+	ErrTokenDuplicate    = errors.New("A token with the given ID already exists")
 
-	ErrKeyMissing      = errors.New("the specified key does not exist")
-	ErrRoleMissing     = errors.New("the specified role does not exist")
-	ErrResourceMissing = errors.New("the specified resource does not exist")
+	ErrRateLimitDuplicate = errors.New("A rate limit with the given ID already exists")
+
+	ErrKeyMissing       = errors.New("the specified key does not exist")
+	ErrRoleMissing      = errors.New("the specified role does not exist")
+	ErrResourceMissing  = errors.New("the specified resource does not exist")
+	ErrRateLimitMissing = errors.New("the specified rate limit does not exist")
+	ErrTokenMissing     = errors.New("the specified token does not exist")
+
+	ErrResourcePathMissing = errors.New("no resource is attached to the specified path")
 
 	ErrRoleNameBad     = errors.New("the given role name is not allowed")
 	ErrKeyNameBad      = errors.New("the given key name is not allowed")
@@ -4,7 +4,7 @@ import (
 	"fsrv/src/config"
 	"fsrv/src/database"
 	"fsrv/src/database/entities"
-	"github.com/zyedidia/generic/cache"
+	"strings"
 )
 
 type CacheDB struct {
@@ -14,28 +14,43 @@ type CacheDB struct {
 	roleCache        *mutexCache[string, result[*entities.Role]]
 	rateLimitCache   *mutexCache[string, result[*entities.RateLimit]]
 	rateLimitIDCache *mutexCache[string, result[string]]
-	tokenCache       *mutexCache[string, result[*entities.Token]] // todo: build out infrastructure for tokens
+	tokenCache       *mutexCache[string, result[*entities.Token]]
+	pathCache        *mutexCache[string, result[string]]
 }
 
+// NewCache wraps a database with caches, whose entries are removed when they
+// are changed through the wrapper, and otherwise expire after cfg.TTL, so that
+// changes made to the database directly, such as by the cli, are seen.
 func NewCache(cfg *config.Cache, db database.DBInterface) *CacheDB {
 	// todo: add more cache size fields to *config.Cache
 	return &CacheDB{
 		db:               db,
-		resourceCache:    newMutexCache(cache.New[string, result[*entities.Resource]](200)),
-		keyCache:         newMutexCache(cache.New[string, result[*entities.Key]](cfg.Keys)),
-		roleCache:        newMutexCache(cache.New[string, result[*entities.Role]](25)),
-		rateLimitCache:   newMutexCache(cache.New[string, result[*entities.RateLimit]](500)),
-		rateLimitIDCache: newMutexCache(cache.New[string, result[string]](50)),
-		tokenCache:       newMutexCache(cache.New[string, result[*entities.Token]](25)),
+		resourceCache:    newMutexCache[string, result[*entities.Resource]]("resource", 200, cfg.TTL),
+		keyCache:         newMutexCache[string, result[*entities.Key]]("key", cfg.Keys, cfg.TTL),
+		roleCache:        newMutexCache[string, result[*entities.Role]]("role", 25, cfg.TTL),
+		rateLimitCache:   newMutexCache[string, result[*entities.RateLimit]]("rate_limit", 500, cfg.TTL),
+		rateLimitIDCache: newMutexCache[string, result[string]]("rate_limit_id", 50, cfg.TTL),
+		tokenCache:       newMutexCache[string, result[*entities.Token]]("token", 25, cfg.TTL),
+		pathCache:        newMutexCache[string, result[string]]("path", 500, cfg.TTL),
 	}
 }
 
+func (c *CacheDB) Check() error {
+	return c.db.Check()
+}
+
+func (c *CacheDB) Close() error {
+	return c.db.Close()
+}
+
 func (c *CacheDB) CreateKey(key *entities.Key) error {
-	err := c.db.CreateKey(key)
+	err := createData(c.keyCache, key, func() error {
+		return c.db.CreateKey(key)
+	})
 	if err != nil {
 		return err
 	}
-	c.keyCache.Put(key.ID, result[*entities.Key]{key, err})
+	c.rateLimitIDCache.Remove(key.ID)
 	return nil
 }
 
@@ -46,7 +61,9 @@ func (c *CacheDB) CreateResource(resource *entities.Resource) error {
 }
 
 func (c *CacheDB) CreateRole(role *entities.Role) error {
-	return c.db.CreateRole(role)
+	return createData(c.roleCache, role, func() error {
+		return c.db.CreateRole(role)
+	})
 }
 
 func (c *CacheDB) CreateRateLimit(limit *entities.RateLimit) error {
@@ -55,36 +72,32 @@ func (c *CacheDB) CreateRateLimit(limit *entities.RateLimit) error {
 	})
 }
 
+func (c *CacheDB) CreateToken(token *entities.Token) error {
+	return createData(c.tokenCache, token, func() error {
+		return c.db.CreateToken(token)
+	})
+}
+
 func (c *CacheDB) GetKeys(pageSize int, offset int) ([]*entities.Key, error) {
-	//TODO implement me
-	panic("implement me")
+	return c.db.GetKeys(pageSize, offset)
 }
 
 func (c *CacheDB) GetKeyIDs(pageSize int, offset int) ([]string, error) {
-	//TODO implement me
-	panic("implement me")
+	return c.db.GetKeyIDs(pageSize, offset)
 }
 
 func (c *CacheDB) GetKeyData(keyID string) (*entities.Key, error) {
-	res, ok := c.keyCache.Get(keyID)
-	if ok {
-		return res.Val, res.Err
-	}
-
-	key, err := c.db.GetKeyData(keyID)
-	// todo: check if the error should be kept before caching
-	c.keyCache.Put(keyID, result[*entities.Key]{key, err})
-	return key, err
+	return retrieveData[*entities.Key](c.keyCache, keyID, func() (*entities.Key, error) {
+		return c.db.GetKeyData(keyID)
+	})
 }
 
 func (c *CacheDB) GetResources(pageSize int, offset int) ([]*entities.Resource, error) {
-	//TODO implement me
-	panic("implement me")
+	return c.db.GetResources(pageSize, offset)
 }
 
 func (c *CacheDB) GetResourceIDs(pageSize int, offset int) ([]string, error) {
-	//TODO implement me
-	panic("implement me")
+	return c.db.GetResourceIDs(pageSize, offset)
 }
 
 func (c *CacheDB) GetResourceData(resourceID string) (*entities.Resource, error) {
@@ -95,81 +108,63 @@ func (c *CacheDB) GetResourceData(resourceID string) (*entities.Resource, error)
 }
 
 func (c *CacheDB) GetRoles(pageSize int, offset int) ([]string, error) {
-	//TODO implement me
-	panic("implement me")
+	return c.db.GetRoles(pageSize, offset)
 }
 
-func (c *CacheDB) GiveRole(keyID string, role ...string) error {
-	return updateData[*entities.Key](c.keyCache, func() error {
-		return c.db.GiveRole(keyID, role...)
-	}, func() (*entities.Key, error) {
-		return c.db.GetKeyData(keyID)
+func (c *CacheDB) GetRoleData(roleID string) (*entities.Role, error) {
+	return retrieveData[*entities.Role](c.roleCache, roleID, func() (*entities.Role, error) {
+		return c.db.GetRoleData(roleID)
 	})
 }
 
-func (c *CacheDB) TakeRole(keyID string, role ...string) error {
-	return updateData[*entities.Key](c.keyCache, func() error {
-		return c.db.TakeRole(keyID, role...)
-	}, func() (*entities.Key, error) {
-		return c.db.GetKeyData(keyID)
+func (c *CacheDB) UpdateKey(key *entities.Key) error {
+	err := c.db.UpdateKey(key)
+	c.keyCache.Remove(key.ID)
+	c.rateLimitIDCache.Remove(key.ID)
+	return err
+}
+
+func (c *CacheDB) GetTokens(pageSize int, offset int) ([]*entities.Token, error) {
+	return c.db.GetTokens(pageSize, offset)
+}
+
+func (c *CacheDB) GetTokenData(tokenID string) (*entities.Token, error) {
+	return retrieveData[*entities.Token](c.tokenCache, tokenID, func() (*entities.Token, error) {
+		return c.db.GetTokenData(tokenID)
 	})
 }
 
-func (c *CacheDB) GrantPermission(permission *entities.Permission, roles ...string) error {
-	err := c.db.GrantPermission(permission, roles...)
-	if err != nil {
-		return err
-	}
-	res, err := c.GetResourceData(permission.ResourceID)
-	if err != nil {
-		return err
-	}
+func (c *CacheDB) GiveRole(keyID string, role ...string) error {
+	err := c.db.GiveRole(keyID, role...)
+	c.keyCache.Remove(keyID)
+	return err
+}
 
-	for _, role := range roles {
-		resOpAccess := entities.ResourceOperationAccess{
-			ID:   role,
-			Type: permission.TypeRWMD,
-		}
-		res.OperationNodes[resOpAccess] = permission.Status
-	}
+func (c *CacheDB) TakeRole(keyID string, role ...string) error {
+	err := c.db.TakeRole(keyID, role...)
+	c.keyCache.Remove(keyID)
+	return err
+}
 
-	// todo: check if the error should be kept before caching
-	c.resourceCache.Put(permission.ResourceID, result[*entities.Resource]{res, nil})
+// GrantPermission removes the resource from the cache rather than changing the
+// cached resource, which may be in use by readers.
+func (c *CacheDB) GrantPermission(permission *entities.Permission, roles ...string) error {
+	err := c.db.GrantPermission(permission, roles...)
+	c.resourceCache.Remove(permission.ResourceID)
 	return err
 }
 
 func (c *CacheDB) RevokePermission(permission *entities.Permission, roles ...string) error {
 	err := c.db.RevokePermission(permission, roles...)
-	if err != nil {
-		return err
-	}
-	res, err := c.GetResourceData(permission.ResourceID)
-	if err != nil {
-		return err
-	}
-	for _, role := range roles {
-		resOpAccess := entities.ResourceOperationAccess{
-			ID:   role,
-			Type: permission.TypeRWMD,
-		}
-		delete(res.OperationNodes, resOpAccess)
-	}
-
-	// todo: check if the error should be kept before caching
-	c.resourceCache.Put(permission.ResourceID, result[*entities.Resource]{res, nil})
+	c.resourceCache.Remove(permission.ResourceID)
 	return err
 }
 
-// SetRateLimit
-// NOTE: mutates underlying key to use given limitID
 func (c *CacheDB) SetRateLimit(key *entities.Key, limitID string) error {
-	return createData[*entities.Key](c.keyCache, key, func() error {
-		err := c.db.SetRateLimit(key, limitID) //attempt to set in database
-		if err != nil {
-			key.RateLimitID = limitID //set in key object so that cache change is written by createData (see impl)
-		}
-		return err
-	})
+	err := c.db.SetRateLimit(key, limitID)
+	c.keyCache.Remove(key.ID)
+	c.rateLimitIDCache.Remove(key.ID)
+	return err
 }
 
 func (c *CacheDB) GetRateLimitData(rateLimitID string) (*entities.RateLimit, error) {
@@ -185,27 +180,110 @@ func (c *CacheDB) GetKeyRateLimitID(keyID string) (string, error) {
 }
 
 func (c *CacheDB) UpdateRateLimit(rateLimitID string, rateLimit *entities.RateLimit) error {
-	return createData[*entities.RateLimit](c.rateLimitCache, rateLimit, func() error {
+	err := createData(c.rateLimitCache, rateLimit, func() error {
 		return c.db.UpdateRateLimit(rateLimitID, rateLimit)
 	})
+	if err == nil && rateLimitID != rateLimit.ID {
+		// the rate limit was renamed; keys may still refer to the old id.
+		c.rateLimitCache.Remove(rateLimitID)
+		c.rateLimitIDCache.Clear()
+		c.keyCache.Clear()
+	}
+	return err
 }
 
 func (c *CacheDB) DeleteRateLimit(rateLimitID string) error {
-	//TODO implement me
-	panic("implement me")
+	err := c.db.DeleteRateLimit(rateLimitID)
+	c.rateLimitCache.Remove(rateLimitID)
+	return err
+}
+
+func (c *CacheDB) SetResourcePath(path string, resourceID string) error {
+	err := c.db.SetResourcePath(path, resourceID)
+	if err != nil {
+		return err
+	}
+	c.pathCache.Put(path, result[string]{resourceID, nil})
+	return nil
+}
+
+func (c *CacheDB) GetPathResourceID(path string) (string, error) {
+	return retrieveData[string](c.pathCache, path, func() (string, error) {
+		return c.db.GetPathResourceID(path)
+	})
+}
+
+func (c *CacheDB) MoveResourcePaths(oldPath string, newPath string) error {
+	err := c.db.MoveResourcePaths(oldPath, newPath)
+	c.removePaths(oldPath)
+	c.removePaths(newPath)
+	return err
+}
+
+func (c *CacheDB) DeleteResourcePath(path string) error {
+	err := c.db.DeleteResourcePath(path)
+	c.pathCache.Remove(path)
+	return err
+}
+
+func (c *CacheDB) DeleteResourcePaths(path string) error {
+	err := c.db.DeleteResourcePaths(path)
+	c.removePaths(path)
+	return err
+}
+
+// removePaths removes a path and its descendants from the path cache.
+func (c *CacheDB) removePaths(path string) {
+	prefix := strings.TrimSuffix(path, "/") + "/"
+	var paths []string
+	c.pathCache.Each(func(key string, _ result[string]) {
+		if key == path || strings.HasPrefix(key, prefix) {
+			paths = append(paths, key)
+		}
+	})
+	for _, key := range paths {
+		c.pathCache.Remove(key)
+	}
 }
 
 func (c *CacheDB) DeleteRole(name string) error {
-	//TODO implement me
-	panic("implement me")
+	err := c.db.DeleteRole(name)
+	if err != nil {
+		return err
+	}
+	// the role may be held by any key, and have
+	// permission nodes on any resource.
+	c.roleCache.Remove(name)
+	c.keyCache.Clear()
+	c.resourceCache.Clear()
+	return nil
 }
 
 func (c *CacheDB) DeleteKey(id string) error {
-	//TODO implement me
-	panic("implement me")
+	err := c.db.DeleteKey(id)
+	if err != nil {
+		return err
+	}
+	// the KeyRole of the key may have permission nodes on any resource.
+	c.keyCache.Remove(id)
+	c.rateLimitIDCache.Remove(id)
+	c.resourceCache.Clear()
+	return nil
 }
 
 func (c *CacheDB) DeleteResource(id string) error {
-	//TODO implement me
-	panic("implement me")
+	err := c.db.DeleteResource(id)
+	if err != nil {
+		return err
+	}
+	// the resource may be attached to any number of paths.
+	c.resourceCache.Remove(id)
+	c.pathCache.Clear()
+	return nil
+}
+
+func (c *CacheDB) DeleteToken(id string) error {
+	err := c.db.DeleteToken(id)
+	c.tokenCache.Remove(id)
+	return err
 }
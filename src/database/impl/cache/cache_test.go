@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"fsrv/src/config"
+	"fsrv/src/database"
+	"fsrv/src/database/databasetest"
+	"fsrv/src/database/entities"
+	"fsrv/src/database/impl/metricsdb"
+	"fsrv/src/database/impl/sqlite"
+	"fsrv/utils/serde"
+	"github.com/go-playground/assert/v2"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCacheDB_Conformance(t *testing.T) {
+	// the database is wrapped as it is when serving
+	databasetest.Run(t, func(t *testing.T) database.DBInterface {
+		db, err := sqlite.Create(filepath.Join(t.TempDir(), "fsrv.sqlite"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			_ = db.Close()
+		})
+		return NewCache(&config.Cache{Keys: 100}, metricsdb.New(db))
+	})
+}
+
+func TestCacheDB_TTL(t *testing.T) {
+	db, err := sqlite.Create(filepath.Join(t.TempDir(), "fsrv.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	c := NewCache(&config.Cache{Keys: 100, TTL: 50 * time.Millisecond}, db)
+
+	key := &entities.Key{
+		ID:        "key",
+		ExpiresAt: serde.Time(time.Unix(0, 0)),
+		CreatedAt: serde.Time(time.Now()),
+	}
+	assert.Equal(t, nil, c.CreateKey(key))
+	_, err = c.GetKeyData(key.ID)
+	assert.Equal(t, nil, err)
+
+	// deleted directly, as by the cli, so the cache is not told
+	assert.Equal(t, nil, db.DeleteKey(key.ID))
+	_, err = c.GetKeyData(key.ID)
+	assert.Equal(t, nil, err)
+
+	time.Sleep(100 * time.Millisecond)
+	_, err = c.GetKeyData(key.ID)
+	assert.Equal(t, database.ErrKeyMissing, err)
+}
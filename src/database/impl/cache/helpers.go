@@ -1,6 +1,9 @@
 package cache
 
-import "log"
+import (
+	"errors"
+	"fsrv/src/database"
+)
 
 type result[V any] struct {
 	Val V
@@ -20,34 +23,35 @@ func retrieveData[T any](cache *mutexCache[string, result[T]], id string, retrie
 		return data.Val, data.Err
 	}
 
+	generation := cache.Generation()
 	freshData, err := retrieveFn()
-	// todo: check if the error should be kept before caching
-	cache.Put(id, result[T]{freshData, err})
+	if isCacheable(err) {
+		cache.PutIfCurrent(id, result[T]{freshData, err}, generation)
+	}
 	return freshData, err
 }
 
+// createData creates data, and removes any result cached for its id, such
+// as it being missing, so that it is read back as the database stores it.
 func createData[T taggedType](cache *mutexCache[string, result[T]], data T, createFn createFunc) error {
 	err := createFn()
 	if err != nil {
 		return err
 	}
 
-	cache.Put(data.GetID(), result[T]{data, nil})
+	cache.Remove(data.GetID())
 	return nil
 }
 
-func updateData[T taggedType](cache *mutexCache[string, result[T]], createFn createFunc, retrieveFn retrieveFunc[T]) error {
-	err := createFn()
-	if err != nil {
-		return err
-	}
-
-	data, err := retrieveFn()
-	if err != nil {
-		log.Fatalf("ERROR: CACHE INCONSISTENCY DETETECTED: %e", err)
-		return err
-	}
-
-	cache.Put(data.GetID(), result[T]{data, nil})
-	return nil
+// isCacheable returns whether a result with an error may be cached, which it
+// may be if the error is that the data is missing, rather than a failure
+// which may not recur, such as the database being unreachable.
+func isCacheable(err error) bool {
+	return err == nil ||
+		errors.Is(err, database.ErrKeyMissing) ||
+		errors.Is(err, database.ErrRoleMissing) ||
+		errors.Is(err, database.ErrResourceMissing) ||
+		errors.Is(err, database.ErrRateLimitMissing) ||
+		errors.Is(err, database.ErrTokenMissing) ||
+		errors.Is(err, database.ErrResourcePathMissing)
 }
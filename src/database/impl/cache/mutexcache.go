@@ -1,43 +1,103 @@
 package cache
 
 import (
+	"fsrv/src/metrics"
 	"github.com/zyedidia/generic/cache"
 	"sync"
+	"time"
 )
 
 type mutexCache[K comparable, V any] struct {
-	*cache.Cache[K, V]
+	cache *cache.Cache[K, entry[V]]
 	mutex sync.Mutex
+	// ttl is how long entries are kept, or 0 to keep them until they
+	// are removed or evicted.
+	ttl time.Duration
+	// generation counts the removals from the cache, so that values read
+	// before an entry was invalidated are not put back into it.
+	generation uint64
+	hits       *metrics.Counter
+	misses     *metrics.Counter
 }
 
-func newMutexCache[K comparable, V any](c *cache.Cache[K, V]) *mutexCache[K, V] {
+type entry[V any] struct {
+	value   V
+	expires time.Time
+}
+
+// newMutexCache creates a cache of a capacity, whose entries expire after
+// ttl unless it is 0, counting its hits and misses under a name in
+// metrics.CacheLookups.
+func newMutexCache[K comparable, V any](name string, capacity int, ttl time.Duration) *mutexCache[K, V] {
 	return &mutexCache[K, V]{
-		Cache: c,
+		cache:  cache.New[K, entry[V]](capacity),
+		ttl:    ttl,
+		hits:   metrics.CacheLookups.With(name, "hit"),
+		misses: metrics.CacheLookups.With(name, "miss"),
 	}
 }
 
+// Get returns the value of an entry, unless it is missing or has expired.
 func (c *mutexCache[K, V]) Get(key K) (V, bool) {
 	c.mutex.Lock()
-	defer c.mutex.Unlock()
+	e, ok := c.cache.Get(key)
+	if ok && !e.expires.IsZero() && !time.Now().Before(e.expires) {
+		c.cache.Remove(key)
+		ok = false
+	}
+	c.mutex.Unlock()
 
-	return c.Cache.Get(key)
+	if ok {
+		c.hits.Inc()
+	} else {
+		c.misses.Inc()
+	}
+	return e.value, ok
 }
 
 func (c *mutexCache[K, V]) Put(key K, value V) {
 	c.mutex.Lock()
-	c.Cache.Put(key, value)
+	c.put(key, value)
 	c.mutex.Unlock()
 }
 
+func (c *mutexCache[K, V]) put(key K, value V) {
+	e := entry[V]{value: value}
+	if c.ttl > 0 {
+		e.expires = time.Now().Add(c.ttl)
+	}
+	c.cache.Put(key, e)
+}
+
+// PutIfCurrent puts a value read while the cache was at a generation,
+// unless any entry has been removed since.
+func (c *mutexCache[K, V]) PutIfCurrent(key K, value V, generation uint64) {
+	c.mutex.Lock()
+	if c.generation == generation {
+		c.put(key, value)
+	}
+	c.mutex.Unlock()
+}
+
+// Generation returns the generation of the cache, which changes
+// whenever an entry is removed.
+func (c *mutexCache[K, V]) Generation() uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.generation
+}
+
 func (c *mutexCache[K, V]) Remove(key K) {
 	c.mutex.Lock()
-	c.Cache.Remove(key)
+	c.generation++
+	c.cache.Remove(key)
 	c.mutex.Unlock()
 }
 
 func (c *mutexCache[K, V]) Resize(capacity int) {
 	c.mutex.Lock()
-	c.Cache.Resize(capacity)
+	c.cache.Resize(capacity)
 	c.mutex.Unlock()
 }
 
@@ -45,24 +105,43 @@ func (c *mutexCache[K, V]) Size() int {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	return c.Cache.Size()
+	return c.cache.Size()
 }
 
 func (c *mutexCache[K, V]) Capacity() int {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	return c.Cache.Capacity()
+	return c.cache.Capacity()
 }
 
 func (c *mutexCache[K, V]) Each(fn func(key K, value V)) {
 	c.mutex.Lock()
-	c.Cache.Each(fn)
+	c.cache.Each(func(key K, e entry[V]) {
+		fn(key, e.value)
+	})
 	c.mutex.Unlock()
 }
 
 func (c *mutexCache[K, V]) SetEvictCallback(fn func(key K, value V)) {
 	c.mutex.Lock()
-	c.Cache.SetEvictCallback(fn)
+	c.cache.SetEvictCallback(func(key K, e entry[V]) {
+		fn(key, e.value)
+	})
 	c.mutex.Unlock()
 }
+
+// Clear removes every entry from the cache.
+func (c *mutexCache[K, V]) Clear() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.generation++
+	var keys []K
+	c.cache.Each(func(key K, _ entry[V]) {
+		keys = append(keys, key)
+	})
+	for _, key := range keys {
+		c.cache.Remove(key)
+	}
+}
@@ -0,0 +1,147 @@
+// Package inmemory implements a database which is held in memory, for tests
+// and deployments which do not need to keep changes, such as read-only demos.
+// It behaves as the sqlite database does, and its contents may be seeded from
+// a file when it is created.
+package inmemory
+
+import (
+	"errors"
+	"fsrv/src/config"
+	"fsrv/src/database/entities"
+	"fsrv/utils/serde"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrClosed is returned by every method of a database once it is closed.
+var ErrClosed = errors.New("the database is closed")
+
+// keyRolePrecedence is the precedence of the KeyRole of each key, as in sqlite.
+const keyRolePrecedence = 10000
+
+type key struct {
+	comment     string
+	rateLimitID string
+	expires     time.Time
+	created     time.Time
+	roles       map[string]bool
+}
+
+type InMemoryDB struct {
+	mux    sync.RWMutex
+	closed bool
+
+	keys       map[string]*key
+	roles      map[string]int
+	resources  map[string]entities.Flags
+	rateLimits map[string]entities.RateLimit
+	tokens     map[string]entities.Token
+	paths      map[string]string
+	// nodes are the permission nodes of each resource id, which may be
+	// granted before the resource is created, as in sqlite
+	nodes map[string]map[entities.ResourceOperationAccess]bool
+}
+
+// New creates an empty database.
+func New() *InMemoryDB {
+	return &InMemoryDB{
+		keys:       make(map[string]*key),
+		roles:      make(map[string]int),
+		resources:  make(map[string]entities.Flags),
+		rateLimits: make(map[string]entities.RateLimit),
+		tokens:     make(map[string]entities.Token),
+		paths:      make(map[string]string),
+		nodes:      make(map[string]map[entities.ResourceOperationAccess]bool),
+	}
+}
+
+// Create creates a database, seeded from the seed file of the config if set.
+func Create(cfg *config.Database) (*InMemoryDB, error) {
+	db := New()
+	if cfg.SeedFile != "" {
+		seed, err := LoadSeed(cfg.SeedFile)
+		if err != nil {
+			return nil, err
+		}
+		err = db.Seed(seed)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return db, nil
+}
+
+// Check always succeeds, since the database has no schema.
+func (db *InMemoryDB) Check() error {
+	if err := db.rlock(); err != nil {
+		return err
+	}
+	defer db.mux.RUnlock()
+	return nil
+}
+
+// Close discards the contents of the database.
+func (db *InMemoryDB) Close() error {
+	if err := db.lock(); err != nil {
+		return err
+	}
+	defer db.mux.Unlock()
+	db.closed = true
+	db.keys, db.roles, db.resources, db.rateLimits = nil, nil, nil, nil
+	db.tokens, db.paths, db.nodes = nil, nil, nil
+	return nil
+}
+
+// lock locks the database for writing, unless it is closed.
+func (db *InMemoryDB) lock() error {
+	db.mux.Lock()
+	if db.closed {
+		db.mux.Unlock()
+		return ErrClosed
+	}
+	return nil
+}
+
+// rlock locks the database for reading, unless it is closed.
+func (db *InMemoryDB) rlock() error {
+	db.mux.RLock()
+	if db.closed {
+		db.mux.RUnlock()
+		return ErrClosed
+	}
+	return nil
+}
+
+// page returns the ids of a page, as with LIMIT and OFFSET, where
+// a negative page size is unlimited.
+func page(ids []string, pageSize int, offset int) []string {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(ids) {
+		return nil
+	}
+	ids = ids[offset:]
+	if pageSize >= 0 && pageSize < len(ids) {
+		ids = ids[:pageSize]
+	}
+	return ids
+}
+
+// sortByTime sorts ids by a time, then by id.
+func sortByTime(ids []string, timeOf func(id string) time.Time) {
+	sort.Slice(ids, func(i, j int) bool {
+		ti, tj := timeOf(ids[i]), timeOf(ids[j])
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return ids[i] < ids[j]
+	})
+}
+
+// toMillis returns a time in the local time zone, at the
+// millisecond precision with which sqlite stores times.
+func toMillis(t serde.Time) time.Time {
+	return time.UnixMilli(time.Time(t).UnixMilli())
+}
@@ -0,0 +1,25 @@
+package inmemory
+
+import (
+	"fsrv/src/database"
+	"fsrv/src/database/databasetest"
+	"github.com/go-playground/assert/v2"
+	"testing"
+)
+
+func TestInMemory_Conformance(t *testing.T) {
+	databasetest.Run(t, func(t *testing.T) database.DBInterface {
+		return New()
+	})
+}
+
+func TestInMemory_Close(t *testing.T) {
+	db := New()
+	assert.Equal(t, nil, db.Check())
+	assert.Equal(t, nil, db.Close())
+
+	assert.Equal(t, ErrClosed, db.Check())
+	_, err := db.GetKeyIDs(10, 0)
+	assert.Equal(t, ErrClosed, err)
+	assert.Equal(t, ErrClosed, db.Close())
+}
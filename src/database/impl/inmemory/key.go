@@ -0,0 +1,164 @@
+package inmemory
+
+import (
+	"fsrv/src/database"
+	"fsrv/src/database/entities"
+	"fsrv/utils/serde"
+	"sort"
+	"time"
+)
+
+func (db *InMemoryDB) CreateKey(k *entities.Key) error {
+	if k.ID == "" {
+		return database.ErrKeyNameBad
+	}
+	if err := db.lock(); err != nil {
+		return err
+	}
+	defer db.mux.Unlock()
+
+	if _, ok := db.keys[k.ID]; ok {
+		return database.ErrKeyDuplicate
+	}
+	//the KeyRole shares the namespace of roles
+	if _, ok := db.roles[k.ID]; ok {
+		return database.ErrRoleDuplicate
+	}
+	roles := make(map[string]bool, len(k.Roles))
+	for _, role := range k.Roles {
+		if _, ok := db.roles[role]; !ok {
+			return database.ErrRoleMissing
+		}
+		roles[role] = true
+	}
+
+	db.keys[k.ID] = &key{
+		comment:     k.Comment,
+		rateLimitID: k.RateLimitID,
+		expires:     toMillis(k.ExpiresAt),
+		created:     toMillis(k.CreatedAt),
+		roles:       roles,
+	}
+	return nil
+}
+
+func (db *InMemoryDB) UpdateKey(k *entities.Key) error {
+	if err := db.lock(); err != nil {
+		return err
+	}
+	defer db.mux.Unlock()
+
+	existing, ok := db.keys[k.ID]
+	if !ok {
+		return database.ErrKeyMissing
+	}
+	existing.comment = k.Comment
+	existing.rateLimitID = k.RateLimitID
+	existing.expires = toMillis(k.ExpiresAt)
+	return nil
+}
+
+// DeleteKey deletes a key, and the permission nodes of its KeyRole.
+func (db *InMemoryDB) DeleteKey(id string) error {
+	if err := db.lock(); err != nil {
+		return err
+	}
+	defer db.mux.Unlock()
+
+	if _, ok := db.keys[id]; !ok {
+		return database.ErrKeyMissing
+	}
+	delete(db.keys, id)
+	db.deleteRoleNodes(id)
+	return nil
+}
+
+func (db *InMemoryDB) GetKeys(pageSize int, offset int) ([]*entities.Key, error) {
+	if err := db.rlock(); err != nil {
+		return nil, err
+	}
+	defer db.mux.RUnlock()
+
+	ids := db.keyIDs(pageSize, offset)
+	keys := make([]*entities.Key, len(ids))
+	for i, id := range ids {
+		keys[i] = db.keyData(id)
+	}
+	return keys, nil
+}
+
+func (db *InMemoryDB) GetKeyIDs(pageSize int, offset int) ([]string, error) {
+	if err := db.rlock(); err != nil {
+		return nil, err
+	}
+	defer db.mux.RUnlock()
+	return db.keyIDs(pageSize, offset), nil
+}
+
+func (db *InMemoryDB) GetKeyData(keyID string) (*entities.Key, error) {
+	if err := db.rlock(); err != nil {
+		return nil, err
+	}
+	defer db.mux.RUnlock()
+
+	if _, ok := db.keys[keyID]; !ok {
+		return nil, database.ErrKeyMissing
+	}
+	return db.keyData(keyID), nil
+}
+
+func (db *InMemoryDB) GetKeyRateLimitID(keyID string) (string, error) {
+	if err := db.rlock(); err != nil {
+		return "", err
+	}
+	defer db.mux.RUnlock()
+
+	k, ok := db.keys[keyID]
+	if !ok {
+		return "", database.ErrKeyMissing
+	}
+	return k.rateLimitID, nil
+}
+
+// keyIDs returns a page of key ids, ordered by creation time.
+func (db *InMemoryDB) keyIDs(pageSize int, offset int) []string {
+	ids := make([]string, 0, len(db.keys))
+	for id := range db.keys {
+		ids = append(ids, id)
+	}
+	sortByTime(ids, func(id string) time.Time {
+		return db.keys[id].created
+	})
+	return page(ids, pageSize, offset)
+}
+
+// keyData returns a key, with its roles ordered by
+// precedence, followed by its KeyRole.
+func (db *InMemoryDB) keyData(id string) *entities.Key {
+	k := db.keys[id]
+	roles := make([]string, 0, len(k.roles)+1)
+	for role := range k.roles {
+		roles = append(roles, role)
+	}
+	sort.Slice(roles, func(i, j int) bool {
+		pi, pj := db.roles[roles[i]], db.roles[roles[j]]
+		if pi != pj {
+			return pi < pj
+		}
+		return roles[i] < roles[j]
+	})
+	// the KeyRole is last unless a role has a higher precedence
+	i := sort.Search(len(roles), func(i int) bool {
+		return db.roles[roles[i]] > keyRolePrecedence
+	})
+	roles = append(roles[:i], append([]string{id}, roles[i:]...)...)
+
+	return &entities.Key{
+		ID:          id,
+		Comment:     k.comment,
+		Roles:       roles,
+		RateLimitID: k.rateLimitID,
+		ExpiresAt:   serde.Time(k.expires),
+		CreatedAt:   serde.Time(k.created),
+	}
+}
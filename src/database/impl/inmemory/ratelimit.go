@@ -0,0 +1,90 @@
+package inmemory
+
+import (
+	"fsrv/src/database"
+	"fsrv/src/database/entities"
+	"fsrv/utils/serde"
+	"time"
+)
+
+func (db *InMemoryDB) CreateRateLimit(limit *entities.RateLimit) error {
+	if err := db.lock(); err != nil {
+		return err
+	}
+	defer db.mux.Unlock()
+
+	if _, ok := db.rateLimits[limit.ID]; ok {
+		return database.ErrRateLimitDuplicate
+	}
+	db.rateLimits[limit.ID] = toStored(limit)
+	return nil
+}
+
+// DeleteRateLimit deletes a rate limit, which does not fail if it is missing.
+func (db *InMemoryDB) DeleteRateLimit(rateLimitID string) error {
+	if err := db.lock(); err != nil {
+		return err
+	}
+	defer db.mux.Unlock()
+
+	delete(db.rateLimits, rateLimitID)
+	return nil
+}
+
+func (db *InMemoryDB) SetRateLimit(key *entities.Key, limitID string) error {
+	if err := db.lock(); err != nil {
+		return err
+	}
+	defer db.mux.Unlock()
+
+	if k, ok := db.keys[key.ID]; ok {
+		k.rateLimitID = limitID
+	}
+	return nil
+}
+
+func (db *InMemoryDB) GetRateLimitData(ratelimitid string) (*entities.RateLimit, error) {
+	if err := db.rlock(); err != nil {
+		return nil, err
+	}
+	defer db.mux.RUnlock()
+
+	limit, ok := db.rateLimits[ratelimitid]
+	if !ok {
+		return nil, database.ErrRateLimitMissing
+	}
+	return &limit, nil
+}
+
+// UpdateRateLimit replaces a rate limit, and moves the keys using it if it is renamed.
+func (db *InMemoryDB) UpdateRateLimit(rateLimitID string, rateLimit *entities.RateLimit) error {
+	if err := db.lock(); err != nil {
+		return err
+	}
+	defer db.mux.Unlock()
+
+	if _, ok := db.rateLimits[rateLimitID]; !ok {
+		return database.ErrRateLimitMissing
+	}
+	if rateLimit.ID != rateLimitID {
+		if _, ok := db.rateLimits[rateLimit.ID]; ok {
+			return database.ErrRateLimitDuplicate
+		}
+		delete(db.rateLimits, rateLimitID)
+		for _, k := range db.keys {
+			if k.rateLimitID == rateLimitID {
+				k.rateLimitID = rateLimit.ID
+			}
+		}
+	}
+	db.rateLimits[rateLimit.ID] = toStored(rateLimit)
+	return nil
+}
+
+// toStored returns a copy of a rate limit, with its refill
+// at the millisecond precision with which sqlite stores it.
+func toStored(limit *entities.RateLimit) entities.RateLimit {
+	stored := *limit
+	stored.Refill = serde.Duration(time.Duration(limit.Refill).Milliseconds() * int64(time.Millisecond))
+	return stored
+}
@@ -0,0 +1,163 @@
+package inmemory
+
+import (
+	"fsrv/src/database"
+	"fsrv/src/database/entities"
+	"sort"
+)
+
+func (db *InMemoryDB) CreateResource(resource *entities.Resource) error {
+	if resource.ID == "" {
+		return database.ErrResourceNameBad
+	}
+	if err := db.lock(); err != nil {
+		return err
+	}
+	defer db.mux.Unlock()
+
+	if _, ok := db.resources[resource.ID]; ok {
+		return database.ErrResourceDuplicate
+	}
+	db.resources[resource.ID] = resource.Flags
+	nodes := db.resourceNodes(resource.ID)
+	for node, status := range resource.OperationNodes {
+		nodes[node] = status
+	}
+	return nil
+}
+
+// DeleteResource deletes a resource, its permission nodes, and detaches it from its paths.
+func (db *InMemoryDB) DeleteResource(id string) error {
+	if err := db.lock(); err != nil {
+		return err
+	}
+	defer db.mux.Unlock()
+
+	if _, ok := db.resources[id]; !ok {
+		return database.ErrResourceMissing
+	}
+	delete(db.nodes, id)
+	for path, resourceID := range db.paths {
+		if resourceID == id {
+			delete(db.paths, path)
+		}
+	}
+	delete(db.resources, id)
+	return nil
+}
+
+func (db *InMemoryDB) GetResources(pageSize int, offset int) ([]*entities.Resource, error) {
+	if err := db.rlock(); err != nil {
+		return nil, err
+	}
+	defer db.mux.RUnlock()
+
+	ids := db.resourceIDs(pageSize, offset)
+	resources := make([]*entities.Resource, len(ids))
+	for i, id := range ids {
+		resources[i] = db.resourceData(id)
+	}
+	return resources, nil
+}
+
+func (db *InMemoryDB) GetResourceIDs(pageSize int, offset int) ([]string, error) {
+	if err := db.rlock(); err != nil {
+		return nil, err
+	}
+	defer db.mux.RUnlock()
+	return db.resourceIDs(pageSize, offset), nil
+}
+
+func (db *InMemoryDB) GetResourceData(resourceid string) (*entities.Resource, error) {
+	if err := db.rlock(); err != nil {
+		return nil, err
+	}
+	defer db.mux.RUnlock()
+
+	if _, ok := db.resources[resourceid]; !ok {
+		return nil, database.ErrResourceMissing
+	}
+	return db.resourceData(resourceid), nil
+}
+
+/*
+GrantPermission Allows or denies the specified roles permission to perform an operation on a resource
+-Roles may be roles, KeyRoles (key ids) or the catch-all role "*"
+-Replaces any existing permission node of each role for the same resourceID and operationType
+*/
+func (db *InMemoryDB) GrantPermission(permission *entities.Permission, roles ...string) error {
+	if err := db.lock(); err != nil {
+		return err
+	}
+	defer db.mux.Unlock()
+
+	nodes := db.resourceNodes(permission.ResourceID)
+	for _, role := range roles {
+		nodes[entities.ResourceOperationAccess{ID: role, Type: permission.TypeRWMD}] = permission.Status
+	}
+	return nil
+}
+
+// RevokePermission Removes the permission nodes of the specified roles for an operation on a resource, of either status
+func (db *InMemoryDB) RevokePermission(permission *entities.Permission, roles ...string) error {
+	if err := db.lock(); err != nil {
+		return err
+	}
+	defer db.mux.Unlock()
+
+	nodes := db.nodes[permission.ResourceID]
+	for _, role := range roles {
+		delete(nodes, entities.ResourceOperationAccess{ID: role, Type: permission.TypeRWMD})
+	}
+	if len(nodes) == 0 {
+		delete(db.nodes, permission.ResourceID)
+	}
+	return nil
+}
+
+// resourceIDs returns a page of resource ids, ordered by id.
+func (db *InMemoryDB) resourceIDs(pageSize int, offset int) []string {
+	ids := make([]string, 0, len(db.resources))
+	for id := range db.resources {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return page(ids, pageSize, offset)
+}
+
+// resourceData returns a copy of a resource and its permission nodes.
+func (db *InMemoryDB) resourceData(id string) *entities.Resource {
+	res := &entities.Resource{
+		ID:             id,
+		Flags:          db.resources[id],
+		OperationNodes: make(map[entities.ResourceOperationAccess]bool, len(db.nodes[id])),
+	}
+	for node, status := range db.nodes[id] {
+		res.OperationNodes[node] = status
+	}
+	return res
+}
+
+// resourceNodes returns the permission nodes of a resource id, creating them if missing.
+func (db *InMemoryDB) resourceNodes(id string) map[entities.ResourceOperationAccess]bool {
+	nodes, ok := db.nodes[id]
+	if !ok {
+		nodes = make(map[entities.ResourceOperationAccess]bool)
+		db.nodes[id] = nodes
+	}
+	return nodes
+}
+
+// deleteRoleNodes removes the permission nodes of a role from every resource.
+func (db *InMemoryDB) deleteRoleNodes(role string) {
+	for id, nodes := range db.nodes {
+		for node := range nodes {
+			if node.ID == role {
+				delete(nodes, node)
+			}
+		}
+		if len(nodes) == 0 {
+			delete(db.nodes, id)
+		}
+	}
+}
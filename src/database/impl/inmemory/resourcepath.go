@@ -0,0 +1,84 @@
+package inmemory
+
+import (
+	"fsrv/src/database"
+	"strings"
+)
+
+func (db *InMemoryDB) SetResourcePath(path string, resourceID string) error {
+	if err := db.lock(); err != nil {
+		return err
+	}
+	defer db.mux.Unlock()
+
+	db.paths[path] = resourceID
+	return nil
+}
+
+func (db *InMemoryDB) GetPathResourceID(path string) (string, error) {
+	if err := db.rlock(); err != nil {
+		return "", err
+	}
+	defer db.mux.RUnlock()
+
+	resourceID, ok := db.paths[path]
+	if !ok {
+		return "", database.ErrResourcePathMissing
+	}
+	return resourceID, nil
+}
+
+// MoveResourcePaths moves a path and the paths of its descendants, replacing
+// any paths at their destinations.
+func (db *InMemoryDB) MoveResourcePaths(oldPath string, newPath string) error {
+	if err := db.lock(); err != nil {
+		return err
+	}
+	defer db.mux.Unlock()
+
+	if oldPath == newPath {
+		return nil
+	}
+	moved := make(map[string]string)
+	prefix := descendantPrefix(oldPath)
+	for path, resourceID := range db.paths {
+		if path == oldPath || strings.HasPrefix(path, prefix) {
+			moved[newPath+path[len(oldPath):]] = resourceID
+			delete(db.paths, path)
+		}
+	}
+	for path, resourceID := range moved {
+		db.paths[path] = resourceID
+	}
+	return nil
+}
+
+func (db *InMemoryDB) DeleteResourcePath(path string) error {
+	if err := db.lock(); err != nil {
+		return err
+	}
+	defer db.mux.Unlock()
+
+	delete(db.paths, path)
+	return nil
+}
+
+func (db *InMemoryDB) DeleteResourcePaths(path string) error {
+	if err := db.lock(); err != nil {
+		return err
+	}
+	defer db.mux.Unlock()
+
+	prefix := descendantPrefix(path)
+	for p := range db.paths {
+		if p == path || strings.HasPrefix(p, prefix) {
+			delete(db.paths, p)
+		}
+	}
+	return nil
+}
+
+// descendantPrefix returns the prefix shared by the paths of all descendants of a path.
+func descendantPrefix(path string) string {
+	return strings.TrimSuffix(path, "/") + "/"
+}
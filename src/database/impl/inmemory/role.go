@@ -0,0 +1,122 @@
+package inmemory
+
+import (
+	"fsrv/src/database"
+	"fsrv/src/database/entities"
+	"sort"
+)
+
+func (db *InMemoryDB) CreateRole(role *entities.Role) error {
+	if role.ID == "" || role.ID == "*" {
+		return database.ErrRoleNameBad
+	}
+	if err := db.lock(); err != nil {
+		return err
+	}
+	defer db.mux.Unlock()
+
+	//roles share their namespace with the KeyRoles of keys
+	_, isRole := db.roles[role.ID]
+	_, isKey := db.keys[role.ID]
+	if isRole || isKey {
+		return database.ErrRoleDuplicate
+	}
+	db.roles[role.ID] = role.Precedence
+	return nil
+}
+
+// DeleteRole deletes a role, taking it from every key and removing its permission nodes.
+func (db *InMemoryDB) DeleteRole(name string) error {
+	if err := db.lock(); err != nil {
+		return err
+	}
+	defer db.mux.Unlock()
+
+	if _, ok := db.roles[name]; !ok {
+		return database.ErrRoleMissing
+	}
+	for _, k := range db.keys {
+		delete(k.roles, name)
+	}
+	db.deleteRoleNodes(name)
+	delete(db.roles, name)
+	return nil
+}
+
+func (db *InMemoryDB) GetRoles(pageSize int, offset int) ([]string, error) {
+	if err := db.rlock(); err != nil {
+		return nil, err
+	}
+	defer db.mux.RUnlock()
+
+	roles := make([]string, 0, len(db.roles))
+	for role := range db.roles {
+		roles = append(roles, role)
+	}
+	sort.Slice(roles, func(i, j int) bool {
+		pi, pj := db.roles[roles[i]], db.roles[roles[j]]
+		if pi != pj {
+			return pi < pj
+		}
+		return roles[i] < roles[j]
+	})
+	return page(roles, pageSize, offset), nil
+}
+
+func (db *InMemoryDB) GetRoleData(roleID string) (*entities.Role, error) {
+	if err := db.rlock(); err != nil {
+		return nil, err
+	}
+	defer db.mux.RUnlock()
+
+	precedence, ok := db.roles[roleID]
+	if !ok {
+		return nil, database.ErrRoleMissing
+	}
+	return &entities.Role{ID: roleID, Precedence: precedence}, nil
+}
+
+// GiveRole gives roles to a key, which is unchanged if any role is missing.
+func (db *InMemoryDB) GiveRole(keyid string, roles ...string) error {
+	if err := db.lock(); err != nil {
+		return err
+	}
+	defer db.mux.Unlock()
+
+	k, ok := db.keys[keyid]
+	if !ok {
+		return database.ErrKeyMissing
+	}
+	for _, role := range roles {
+		if _, ok := db.roles[role]; !ok {
+			return database.ErrRoleMissing
+		}
+	}
+	for _, role := range roles {
+		k.roles[role] = true
+	}
+	return nil
+}
+
+// TakeRole takes roles from a key, which is unchanged if any role is its KeyRole.
+func (db *InMemoryDB) TakeRole(keyid string, roles ...string) error {
+	if err := db.lock(); err != nil {
+		return err
+	}
+	defer db.mux.Unlock()
+
+	k, ok := db.keys[keyid]
+	if !ok {
+		return database.ErrKeyMissing
+	}
+	for _, role := range roles {
+		if role == keyid {
+			//the KeyRole cannot be taken from its key
+			return database.ErrRoleNameBad
+		}
+	}
+	for _, role := range roles {
+		delete(k.roles, role)
+	}
+	return nil
+}
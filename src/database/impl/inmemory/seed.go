@@ -0,0 +1,90 @@
+package inmemory
+
+import (
+	"encoding/json"
+	"fmt"
+	"fsrv/src/database/entities"
+	"fsrv/utils/serde"
+	"github.com/pelletier/go-toml"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Seed is the initial contents of a database, encoded as they are by the admin API,
+// so times are in unix milliseconds and the refill of rate limits in milliseconds.
+type Seed struct {
+	RateLimits []*entities.RateLimit `json:"rate_limits"`
+	Roles      []*entities.Role      `json:"roles"`
+	// Keys are identified by the ids derived from their plaintext keys, as listed by `fsrv key list`.
+	Keys      []*entities.Key      `json:"keys"`
+	Resources []*entities.Resource `json:"resources"`
+	// Paths are the resource ids attached to paths.
+	Paths map[string]string `json:"paths"`
+}
+
+// LoadSeed loads a seed from a JSON file, or a TOML file if its extension is .toml,
+// which is read as the equivalent JSON.
+func LoadSeed(path string) (*Seed, error) {
+	var data []byte
+	var err error
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		var tree *toml.Tree
+		tree, err = toml.LoadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		data, err = json.Marshal(tree.ToMap())
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	seed := &Seed{}
+	err = json.Unmarshal(data, seed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid seed file '%s': %v", path, err)
+	}
+	return seed, nil
+}
+
+// Seed adds the contents of a seed to the database. Keys without a creation
+// time are created now, and keys without an expiry time never expire.
+func (db *InMemoryDB) Seed(seed *Seed) error {
+	for _, limit := range seed.RateLimits {
+		if err := db.CreateRateLimit(limit); err != nil {
+			return fmt.Errorf("error seeding rate limit '%s': %v", limit.ID, err)
+		}
+	}
+	for _, role := range seed.Roles {
+		if err := db.CreateRole(role); err != nil {
+			return fmt.Errorf("error seeding role '%s': %v", role.ID, err)
+		}
+	}
+	now := time.Now()
+	for _, key := range seed.Keys {
+		if time.Time(key.CreatedAt).IsZero() {
+			key.CreatedAt = serde.Time(now)
+		}
+		if time.Time(key.ExpiresAt).IsZero() {
+			key.ExpiresAt = serde.Time(time.Unix(0, 0))
+		}
+		if err := db.CreateKey(key); err != nil {
+			return fmt.Errorf("error seeding key '%s': %v", key.ID, err)
+		}
+	}
+	for _, resource := range seed.Resources {
+		if err := db.CreateResource(resource); err != nil {
+			return fmt.Errorf("error seeding resource '%s': %v", resource.ID, err)
+		}
+	}
+	for path, resourceID := range seed.Paths {
+		if err := db.SetResourcePath(path, resourceID); err != nil {
+			return fmt.Errorf("error seeding path '%s': %v", path, err)
+		}
+	}
+	return nil
+}
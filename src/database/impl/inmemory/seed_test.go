@@ -0,0 +1,107 @@
+package inmemory
+
+import (
+	"fsrv/src/config"
+	"fsrv/src/database/entities"
+	"fsrv/src/types"
+	"fsrv/utils/serde"
+	"github.com/go-playground/assert/v2"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const jsonSeed = `{
+	"rate_limits": [{"id": "slow", "limit": 10, "burst": 5, "refill": 60000}],
+	"roles": [{"id": "viewer", "precedence": 1}],
+	"keys": [{"id": "key", "comment": "demo", "roles": ["viewer"], "rate_limit_id": "slow", "created_at": 1000}],
+	"resources": [{"id": "res", "flags": 1, "nodes": {"read:viewer": true, "write:*": false}}],
+	"paths": {"/public": "res"}
+}`
+
+const tomlSeed = `
+[[rate_limits]]
+id = "slow"
+limit = 10
+burst = 5
+refill = 60000
+
+[[roles]]
+id = "viewer"
+precedence = 1
+
+[[keys]]
+id = "key"
+comment = "demo"
+roles = ["viewer"]
+rate_limit_id = "slow"
+created_at = 1000
+
+[[resources]]
+id = "res"
+flags = 1
+[resources.nodes]
+"read:viewer" = true
+"write:*" = false
+
+[paths]
+"/public" = "res"
+`
+
+func TestCreate_Seed(t *testing.T) {
+	for name, seed := range map[string]string{"seed.json": jsonSeed, "seed.toml": tomlSeed} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := os.WriteFile(path, []byte(seed), 0600); err != nil {
+				t.Fatal(err)
+			}
+			db, err := Create(&config.Database{Type: config.DatabaseInMemory, SeedFile: path})
+			assert.Equal(t, nil, err)
+
+			limit, err := db.GetRateLimitData("slow")
+			assert.Equal(t, nil, err)
+			assert.Equal(t, &entities.RateLimit{ID: "slow", Limit: 10, Burst: 5, Refill: serde.Duration(time.Minute)}, limit)
+
+			role, err := db.GetRoleData("viewer")
+			assert.Equal(t, nil, err)
+			assert.Equal(t, 1, role.Precedence)
+
+			key, err := db.GetKeyData("key")
+			assert.Equal(t, nil, err)
+			assert.Equal(t, "demo", key.Comment)
+			assert.Equal(t, []string{"viewer", "key"}, key.Roles)
+			assert.Equal(t, "slow", key.RateLimitID)
+			assert.Equal(t, int64(1000), time.Time(key.CreatedAt).UnixMilli())
+			assert.Equal(t, false, key.IsExpired())
+
+			resource, err := db.GetResourceData("res")
+			assert.Equal(t, nil, err)
+			assert.Equal(t, &entities.Resource{
+				ID:    "res",
+				Flags: entities.FlagPublicRead,
+				OperationNodes: map[entities.ResourceOperationAccess]bool{
+					{ID: "viewer", Type: types.OperationRead}: true,
+					{ID: "*", Type: types.OperationWrite}:     false,
+				},
+			}, resource)
+
+			resourceID, err := db.GetPathResourceID("/public")
+			assert.Equal(t, nil, err)
+			assert.Equal(t, "res", resourceID)
+		})
+	}
+}
+
+func TestCreate_SeedErrors(t *testing.T) {
+	_, err := Create(&config.Database{Type: config.DatabaseInMemory, SeedFile: filepath.Join(t.TempDir(), "missing.json")})
+	assert.NotEqual(t, nil, err)
+
+	// keys may only be given roles which are seeded
+	path := filepath.Join(t.TempDir(), "seed.json")
+	if err := os.WriteFile(path, []byte(`{"keys": [{"id": "key", "roles": ["missing"]}]}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	_, err = Create(&config.Database{Type: config.DatabaseInMemory, SeedFile: path})
+	assert.NotEqual(t, nil, err)
+}
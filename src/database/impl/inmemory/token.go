@@ -0,0 +1,76 @@
+package inmemory
+
+import (
+	"errors"
+	"fsrv/src/database"
+	"fsrv/src/database/entities"
+	"fsrv/utils/serde"
+	"time"
+)
+
+func (db *InMemoryDB) CreateToken(token *entities.Token) error {
+	if token.ID == "" {
+		return errors.New("required field tokenid not specified")
+	}
+	if err := db.lock(); err != nil {
+		return err
+	}
+	defer db.mux.Unlock()
+
+	if _, ok := db.tokens[token.ID]; ok {
+		return database.ErrTokenDuplicate
+	}
+	stored := *token
+	stored.ExpiresAt = serde.Time(toMillis(token.ExpiresAt))
+	stored.CreatedAt = serde.Time(toMillis(token.CreatedAt))
+	db.tokens[token.ID] = stored
+	return nil
+}
+
+func (db *InMemoryDB) GetTokens(pageSize int, offset int) ([]*entities.Token, error) {
+	if err := db.rlock(); err != nil {
+		return nil, err
+	}
+	defer db.mux.RUnlock()
+
+	ids := make([]string, 0, len(db.tokens))
+	for id := range db.tokens {
+		ids = append(ids, id)
+	}
+	sortByTime(ids, func(id string) time.Time {
+		return time.Time(db.tokens[id].CreatedAt)
+	})
+	ids = page(ids, pageSize, offset)
+	tokens := make([]*entities.Token, len(ids))
+	for i, id := range ids {
+		token := db.tokens[id]
+		tokens[i] = &token
+	}
+	return tokens, nil
+}
+
+func (db *InMemoryDB) GetTokenData(tokenID string) (*entities.Token, error) {
+	if err := db.rlock(); err != nil {
+		return nil, err
+	}
+	defer db.mux.RUnlock()
+
+	token, ok := db.tokens[tokenID]
+	if !ok {
+		return nil, database.ErrTokenMissing
+	}
+	return &token, nil
+}
+
+func (db *InMemoryDB) DeleteToken(id string) error {
+	if err := db.lock(); err != nil {
+		return err
+	}
+	defer db.mux.Unlock()
+
+	if _, ok := db.tokens[id]; !ok {
+		return database.ErrTokenMissing
+	}
+	delete(db.tokens, id)
+	return nil
+}
@@ -0,0 +1,360 @@
+package mariadb
+
+import (
+	"fsrv/src/config"
+	"fsrv/src/database"
+	"fsrv/src/database/entities"
+	"fsrv/src/types"
+	"fsrv/utils/serde"
+	"os"
+	"testing"
+	"time"
+)
+
+// testDSNEnv names the environment variable which holds the connection
+// string of a database the tests may destroy, such as
+// 'fsrv:fsrv@tcp(localhost:3306)/fsrv_test'. The tests are skipped if unset,
+// except in CI, which runs them against a MariaDB container.
+const testDSNEnv = "FSRV_TEST_MARIADB_DSN"
+
+func getDB(t *testing.T) *MariaDB {
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		if os.Getenv("CI") != "" {
+			t.Fatalf("%s must be set in CI", testDSNEnv)
+		}
+		t.Skipf("%s is not set", testDSNEnv)
+	}
+	cfg := &config.Database{Type: config.DatabaseMariaDB, ConnectionString: dsn}
+
+	//tables must exist to prepare the queries, before they can be destroyed
+	db, err := Create(cfg)
+	if err != nil {
+		t.Fatalf("FAILED TO CONNECT TO DB: %v", err)
+	}
+	err = db.Destroy()
+	if err != nil {
+		t.Fatalf("FAILED TO REMOVE EXISTING TABLES: %v", err)
+	}
+	_ = db.Close()
+
+	db, err = Create(cfg)
+	if err != nil {
+		t.Fatalf("FAILED TO INITIALIZE DB: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	return db
+}
+
+func bap(t *testing.T, errs ...error) {
+	for _, err := range errs {
+		if err != nil {
+			t.Errorf("[TEST FAILED]: %v", errs)
+			t.Fail()
+		}
+	}
+}
+
+func makeRoles(db *MariaDB) error {
+	roleTable := map[int]string{
+		100:  "stone",
+		200:  "iron",
+		250:  "gold",
+		1500: "obsidian",
+		1000: "diamond",
+	}
+
+	for k, v := range roleTable {
+		err := db.CreateRole(&entities.Role{
+			ID:         v,
+			Precedence: k,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func makeKeys(db *MariaDB) error {
+	k1 := entities.Key{
+		ID:          "key_q2w26DFu8dr5578x&4syd46e7",
+		Comment:     "rock guy",
+		Roles:       []string{"stone"},
+		RateLimitID: "DEFAULT",
+		ExpiresAt:   serde.Time(time.Now().AddDate(1, 0, 0)),
+		CreatedAt:   serde.Time(time.Now()),
+	}
+
+	k2 := entities.Key{
+		ID:          "key_dr476FXC8drUXe%&SR5ujr",
+		Comment:     "pebble person",
+		Roles:       []string{"stone"},
+		RateLimitID: "DEFAULT",
+		ExpiresAt:   serde.Time(time.Now().AddDate(0, 8, 0)),
+		CreatedAt:   serde.Time(time.Now()),
+	}
+
+	k3 := entities.Key{
+		ID:          "key_dfthcr5uyers57yerd5ydr567",
+		Comment:     "iron ingot wingnut",
+		Roles:       []string{"iron"},
+		RateLimitID: "DEFAULT",
+		ExpiresAt:   serde.Time(time.Now().AddDate(0, 6, 0)),
+		CreatedAt:   serde.Time(time.Now()),
+	}
+
+	k4 := entities.Key{
+		ID:          "key_gkfp989P$%WA$ETseTSETST$",
+		Comment:     "Roles: stone & diamond",
+		Roles:       []string{"stone", "diamond"},
+		RateLimitID: "high limit",
+		ExpiresAt:   serde.Time(time.Now().AddDate(0, 3, 0)),
+		CreatedAt:   serde.Time(time.Now()),
+	}
+
+	k5 := entities.Key{
+		ID:          "key_sdrySDRyDSrydrtyasWTT",
+		Comment:     "Roles: gold & iron",
+		Roles:       []string{"gold", "iron"},
+		RateLimitID: "LowLimitFastReset",
+		ExpiresAt:   serde.Time(time.Now().AddDate(0, 0, 4)),
+		CreatedAt:   serde.Time(time.Now()),
+	}
+
+	k6 := entities.Key{
+		ID:          "key_ancientEvil",
+		Comment:     "ancient evil must be contained through strict ratelimits and permission control",
+		Roles:       []string{"obsidian"},
+		RateLimitID: "STRICT_LIMIT",
+		ExpiresAt:   serde.Time(time.Now().AddDate(9999, 0, 0)),
+		CreatedAt:   serde.Time(time.Now()),
+	}
+
+	kValid := entities.Key{
+		ID:          "HYfe_nbGcrwPHpHvAtlUxtSdUw1GMv9QjJtlJrwae2iSIDZehr5opg",
+		Comment:     "a real, genuine key, minted right here in fsrv.",
+		Roles:       []string{"obsidian", "diamond"},
+		RateLimitID: "LowLimitFastReset",
+		ExpiresAt:   serde.Time(time.Now().AddDate(10, 0, 0)),
+		CreatedAt:   serde.Time(time.Now()),
+	}
+
+	//create keys
+	keys := []*entities.Key{&k1, &k2, &k3, &k4, &k5, &k6, &kValid}
+	for _, k := range keys {
+		err := db.CreateKey(k)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func makeResources(db *MariaDB) error {
+	res := []*entities.Resource{
+		{
+			ID:    "res_stoneWorld:WYRSRYssysysySrysrur6i98",
+			Flags: 0,
+			OperationNodes: map[entities.ResourceOperationAccess]bool{
+				{ID: "stone", Type: types.OperationRead}:   true,
+				{ID: "stone", Type: types.OperationWrite}:  false,
+				{ID: "stone", Type: types.OperationModify}: false,
+				{ID: "stone", Type: types.OperationDelete}: true,
+			},
+		},
+
+		{
+			ID:    "res_publicReadAndModDiamondDelete:pu9ipuijpj0m0uji0ji0j0ji0",
+			Flags: 0,
+			OperationNodes: map[entities.ResourceOperationAccess]bool{
+				{ID: "diamond", Type: types.OperationDelete}: true,
+			},
+		},
+
+		{
+			ID:    "res_READ:DiamondAllowStoneDeny,WRITE:IronAllowGoldDeny:qwdxqwdxawqxqwdqwd",
+			Flags: 0,
+			OperationNodes: map[entities.ResourceOperationAccess]bool{
+				{ID: "diamond", Type: types.OperationRead}: true,
+				{ID: "stone", Type: types.OperationRead}:   false,
+				{ID: "gold", Type: types.OperationWrite}:   false,
+				{ID: "iron", Type: types.OperationWrite}:   true,
+			},
+		},
+
+		{
+			ID:    "res_READ:ObsidianDenyStoneAllowStoneDeny,WRITE:Gold",
+			Flags: 0,
+			OperationNodes: map[entities.ResourceOperationAccess]bool{
+				{ID: "obsidian", Type: types.OperationRead}: false,
+				{ID: "stone", Type: types.OperationRead}:    true,
+				{ID: "gold", Type: types.OperationRead}:     false,
+				{ID: "gold", Type: types.OperationWrite}:    true,
+			},
+		},
+
+		{
+			ID:    "res_READ:fake_roles,WRITE:fakeAndReal",
+			Flags: 0,
+			OperationNodes: map[entities.ResourceOperationAccess]bool{
+				{ID: "glass", Type: types.OperationRead}:    false,
+				{ID: "coal", Type: types.OperationRead}:     true,
+				{ID: "steel", Type: types.OperationRead}:    false,
+				{ID: "mithril", Type: types.OperationWrite}: true,
+				{ID: "diamond", Type: types.OperationWrite}: true,
+			},
+		},
+		{
+			ID:    "res_C/R/U/D:[key: key_ancientEvil]",
+			Flags: 0,
+			OperationNodes: map[entities.ResourceOperationAccess]bool{
+				{ID: "key_ancientEvil", Type: types.OperationRead}:   false,
+				{ID: "key_ancientEvil", Type: types.OperationWrite}:  true,
+				{ID: "key_ancientEvil", Type: types.OperationModify}: false,
+				{ID: "key_ancientEvil", Type: types.OperationDelete}: true,
+			},
+		},
+	}
+
+	for _, r := range res {
+		err := db.CreateResource(r)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func grantPermissionsPostHoc(db *MariaDB) (errs []error) {
+	roleperms := map[*entities.Permission][]string{
+		&entities.Permission{
+			ResourceID: "res_READ:fake_roles,WRITE:fakeAndReal",
+			TypeRWMD:   0,
+			Status:     true,
+		}: {"diamond"},
+		&entities.Permission{
+			ResourceID: "res_READ:fake_roles,WRITE:fakeAndReal",
+			TypeRWMD:   0,
+			Status:     true,
+		}: {"iron, gold, pyrite"},
+		&entities.Permission{
+			ResourceID: "Bad Resource id",
+			TypeRWMD:   2,
+			Status:     false,
+		}: {"gold"},
+		&entities.Permission{
+			ResourceID: "res_READ:fake_roles,WRITE:fakeAndReal",
+			TypeRWMD:   6, //todo: fix accepting bad permission type
+			Status:     true,
+		}: {"diamond, obsidian"},
+		&entities.Permission{ //grant per key permission
+			ResourceID: "",
+			TypeRWMD:   0,
+			Status:     true,
+		}: {"key_dfthcr5uyers57yerd5ydr567"},
+	}
+
+	for k, v := range roleperms {
+		err := db.GrantPermission(k, v...)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func getResourcePermData(t *testing.T, db *MariaDB, ids []string) (errs []error) {
+	for i, id := range ids {
+		data, err := db.GetResourceData(id)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		t.Logf("%d: [FLAGS=%d, ID=%s]\n", i, data.Flags, id)
+	}
+	return errs
+}
+
+func getRatelimitsForAllKeys(t *testing.T, db *MariaDB, ids []string) (errs []error) {
+	for _, id := range ids {
+		limID, err := db.GetKeyRateLimitID(id)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		lim, err := db.GetRateLimitData(limID)
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			t.Logf("KeyID: %s, ratelimit:{id:%s, lim:%d, reset:%d }", id, lim.ID, lim.Limit, lim.Refill)
+		}
+	}
+	return errs
+}
+
+func giveRoles(t *testing.T, db *MariaDB) (errs []error) {
+
+	return errs
+}
+
+func takeRoles(t *testing.T, db *MariaDB) (errs []error) {
+
+	return errs
+}
+
+func createRateLimits(t *testing.T, db *MariaDB) (errs []error) {
+	limits := []entities.RateLimit{
+		{ID: "DEFAULT", Limit: 20, Burst: 20, Refill: serde.Duration(60 * time.Second)},
+		{ID: "high limit", Limit: 200, Burst: 200, Refill: serde.Duration(60 * time.Second)},
+		{ID: "LowLimitFastReset", Limit: 2, Burst: 10, Refill: serde.Duration(1 * time.Second)},
+		{ID: "STRICT_LIMIT", Limit: 1, Burst: 10, Refill: serde.Duration(60 * time.Second)},
+	}
+	var err error
+	for _, lim := range limits {
+		err = db.CreateRateLimit(&lim)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+func createDupeKey(db *MariaDB) error {
+	kValidDupe := entities.Key{
+		ID:          "HYfe_nbGcrwPHpHvAtlUxtSdUw1GMv9QjJtlJrwae2iSIDZehr5opg",
+		Comment:     "a poor immitation of the original, but nevertheless a valid key",
+		Roles:       []string{"jade", "diamond"},
+		RateLimitID: "STRICT_LIMIT",
+		ExpiresAt:   serde.Time(time.Now().AddDate(1, 0, 0)),
+		CreatedAt:   serde.Time(time.Now()),
+	}
+	return db.CreateKey(&kValidDupe)
+}
+
+func TestMariaDB(t *testing.T) {
+	db := getDB(t)
+	bap(t, makeRoles(db))
+	bap(t, makeResources(db))
+	bap(t, createRateLimits(t, db)...)
+	bap(t, makeKeys(db))
+	bap(t, grantPermissionsPostHoc(db)...)
+	resources, err := db.GetResourceIDs(1000, 0)
+	bap(t, err)
+	bap(t, getResourcePermData(t, db, resources)...)
+	keys, err := db.GetKeyIDs(1000, 0)
+	bap(t, err)
+	bap(t, getRatelimitsForAllKeys(t, db, keys)...)
+	//bap(t, giveRoles(t, db)...)
+	//bap(t, takeRoles(t, db)...)
+	_, err = db.GetKeyRateLimitID("idontexist")
+	t.Logf("Correct Error? %t", err == database.ErrKeyMissing)
+	t.Logf("Creating duplicate key: %t", createDupeKey(db))
+	k, _ := db.GetKeyData("HYfe_nbGcrwPHpHvAtlUxtSdUw1GMv9QjJtlJrwae2iSIDZehr5opg")
+	t.Logf("Printing key: %+v ", k)
+}
@@ -0,0 +1 @@
+SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE();
\ No newline at end of file
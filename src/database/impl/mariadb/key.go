@@ -0,0 +1,238 @@
+package mariadb
+
+import (
+	"database/sql"
+	"errors"
+	"fsrv/src/database"
+	"fsrv/src/database/entities"
+	"fsrv/utils/serde"
+	"time"
+)
+
+func (mariadb *MariaDB) CreateKey(key *entities.Key) error {
+	if key.ID == "" {
+		return errors.New("required feild keyid not specified")
+	}
+
+	//begin transaction
+	tx, err := mariadb.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	//create key record
+	stmt := tx.Stmt(mariadb.qm.InsKeyData)
+	_, err = stmt.Exec(key.ID, key.Comment, key.RateLimitID, time.Time(key.ExpiresAt).UnixMilli(), time.Time(key.CreatedAt).UnixMilli())
+	if err != nil {
+		if isDuplicateError(err) {
+			err = database.ErrKeyDuplicate
+		}
+		return rollback(tx, err)
+	}
+
+	//add Roles
+	var roleid string
+	stmtGetRoleID := tx.Stmt(mariadb.qm.GetRoleIDIfExists)
+	stmtInsKRIData := tx.Stmt(mariadb.qm.InsKeyRoleIntersectData)
+	for _, role := range key.Roles {
+		//check if key role exists
+		row := stmtGetRoleID.QueryRow(role)
+		err = row.Scan(&roleid) //produces sql.ErrNoRows if role does not exist
+		if err != nil {
+			if err == sql.ErrNoRows {
+				err = database.ErrRoleMissing
+			}
+			return rollback(tx, err)
+		}
+
+		//insert Role into KeyRoleIntersect //todo:make function
+		_, err = stmtInsKRIData.Exec(key.ID, roleid)
+		if err != nil {
+			return rollback(tx, err)
+		}
+	}
+
+	//insert KeyRole into roles //todo: ensure that precedence ordering is consistent
+	stmt = tx.Stmt(mariadb.qm.InsRoleData)
+	_, err = stmt.Exec(key.ID, 1, 10000)
+	if err != nil {
+		return rollback(tx, err)
+	}
+	//Insert KeyRole into KeyRoleIntersect
+	stmt = tx.Stmt(mariadb.qm.InsKeyRoleIntersectData)
+	_, err = stmt.Exec(key.ID, key.ID)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	//commit transaction results
+	return tx.Commit()
+}
+
+func (mariadb *MariaDB) UpdateKey(key *entities.Key) error {
+	res, err := mariadb.qm.UpdKeyData.Exec(key.Comment, key.RateLimitID, time.Time(key.ExpiresAt).UnixMilli(), key.ID)
+	if err != nil {
+		return err
+	}
+	rowNum, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowNum == 0 {
+		return database.ErrKeyMissing
+	}
+	return nil
+}
+
+func (mariadb *MariaDB) DeleteKey(id string) error {
+	//begin transaction
+	tx, err := mariadb.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	//delete key record
+	stmt := tx.Stmt(mariadb.qm.DelKeyByID)
+	res, err := stmt.Exec(id)
+	if err != nil {
+		return rollback(tx, err)
+	}
+	rowNum, err := res.RowsAffected()
+	if err != nil {
+		return rollback(tx, err)
+	}
+	if rowNum == 0 {
+		return rollback(tx, database.ErrKeyMissing)
+	}
+
+	//delete role assignments, including the KeyRole
+	stmt = tx.Stmt(mariadb.qm.DelKRIEntriesByKeyID)
+	_, err = stmt.Exec(id)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	//delete KeyRole and its permissions
+	stmt = tx.Stmt(mariadb.qm.DelRPIEntryByRoleID)
+	_, err = stmt.Exec(id)
+	if err != nil {
+		return rollback(tx, err)
+	}
+	stmt = tx.Stmt(mariadb.qm.DelRoleByID)
+	_, err = stmt.Exec(id)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	//commit
+	return tx.Commit()
+}
+
+func (mariadb *MariaDB) GetKeys(pageSize int, offset int) ([]*entities.Key, error) {
+	keyIDs, err := mariadb.GetKeyIDs(pageSize, offset)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]*entities.Key, len(keyIDs))
+	for i, keyID := range keyIDs {
+		keys[i], err = mariadb.GetKeyData(keyID)
+		if err != nil {
+			return keys[:i], err
+		}
+	}
+	return keys, nil
+}
+
+func (mariadb *MariaDB) GetKeyIDs(pageSize int, offset int) ([]string, error) {
+	//todo: determine if this is okay to do outside of a transaction
+	var keyIDs []string
+	var id string
+	rows, err := mariadb.qm.GetKeyIDs.Query(pageSize, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		err = rows.Scan(&id)
+		if err != nil {
+			return keyIDs, err
+		}
+		keyIDs = append(keyIDs, id)
+	}
+
+	return keyIDs, nil
+}
+
+func (mariadb *MariaDB) GetKeyData(keyid string) (*entities.Key, error) {
+	var key entities.Key
+	var rtlimID sql.NullString
+	var createMS, expireMS int64
+
+	//begin transaction
+	tx, err := mariadb.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	//get base key data
+	stmtGetBaseData := tx.Stmt(mariadb.qm.GetKeyData)
+	row := stmtGetBaseData.QueryRow(keyid)
+	err = row.Scan(&key.Comment, &rtlimID, &createMS, &expireMS)
+	if err != nil {
+		_ = tx.Rollback()
+		if err == sql.ErrNoRows {
+			return nil, database.ErrKeyMissing
+		}
+		return nil, err
+	}
+
+	//get Roles
+	stmtGetRoles := tx.Stmt(mariadb.qm.GetRolesByKeyIDOrdered)
+	rows, err := stmtGetRoles.Query(keyid)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	var role string
+	for rows.Next() {
+		err = rows.Scan(&role)
+		if err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+		key.Roles = append(key.Roles, role)
+	}
+
+	//finish building key
+	key.ID = keyid
+	key.RateLimitID = rtlimID.String
+	key.CreatedAt = serde.Time(time.UnixMilli(createMS))
+	key.ExpiresAt = serde.Time(time.UnixMilli(expireMS))
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (mariadb *MariaDB) GetKeyRateLimitID(keyID string) (string, error) {
+	var rateLimitID sql.NullString
+	row := mariadb.qm.GetKeyRateLimitID.QueryRow(keyID)
+	err := row.Scan(&rateLimitID)
+	if err != nil {
+		row = mariadb.qm.GetKeyIDIfExists.QueryRow(keyID)
+		err = row.Scan(&keyID)
+		if err == sql.ErrNoRows {
+			return keyID, database.ErrKeyMissing
+		} else {
+			return "", err
+		}
+	}
+	if rateLimitID.Valid {
+		return rateLimitID.String, nil
+	} else {
+		return "", err
+	}
+}
@@ -0,0 +1,310 @@
+package mariadb
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"fsrv/src/config"
+	"fsrv/src/database/entities"
+	"fsrv/src/database/migration"
+	"io"
+	"strings"
+)
+import "github.com/go-sql-driver/mysql"
+import "embed"
+
+// errDuplicateEntry is the MariaDB and MySQL error number of ER_DUP_ENTRY.
+const errDuplicateEntry = 1062
+
+type MariaDB struct {
+	db *sql.DB
+	qm *QueryManager
+}
+
+/////////////////////////////////////////
+//									   //
+/*-------------------------------------*\
+//         INTERFACE FUNCTIONS		   //
+\*-------------------------------------*/
+//									   //
+/////////////////////////////////////////
+
+/*               *\
+	 Database
+	 Functions
+\*               */
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+var migrations = migration.MustLoad(migrationFiles, "migrations")
+
+// Create connects to the database named by the connection string, such as
+// 'user:password@tcp(localhost:3306)/fsrv', and upgrades its schema to the latest version.
+func Create(cfg *config.Database) (*MariaDB, error) {
+	db, err := connect(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	_, _, err = newMigrator(db).Migrate(0)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return produceObj(db)
+}
+
+// Open connects to the database named by the connection string, whose schema is
+// upgraded automatically up to the version of cfg, or the latest version if 0,
+// and must then be the latest version.
+func Open(cfg *config.Database) (*MariaDB, error) {
+	db, err := connect(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	err = newMigrator(db).Prepare(cfg.Version)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return produceObj(db)
+}
+
+// Migrate upgrades or downgrades the schema of the database to version, or
+// to the latest version if 0, returning the versions it was migrated from and to.
+func Migrate(cfg *config.Database, version int) (from int, to int, err error) {
+	db, err := connect(cfg)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer db.Close()
+
+	return newMigrator(db).Migrate(version)
+}
+
+func newMigrator(db *sql.DB) *migration.Migrator {
+	return migration.New(db, migrations, func(tx *sql.Tx, script string) error {
+		return execScript(tx, script)
+	})
+}
+
+// connect opens a connection pool configured by cfg, and
+// verifies that the database can be reached.
+func connect(cfg *config.Database) (*sql.DB, error) {
+	_, err := mysql.ParseDSN(cfg.ConnectionString)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("mysql", cfg.ConnectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.MaxOpenConnections > 0 {
+		db.SetMaxOpenConns(cfg.MaxOpenConnections)
+	}
+	if cfg.MaxIdleConnections > 0 {
+		db.SetMaxIdleConns(cfg.MaxIdleConnections)
+	}
+	if cfg.ConnectionMaxLifetime > 0 {
+		db.SetConnMaxLifetime(cfg.ConnectionMaxLifetime)
+	}
+
+	err = db.Ping()
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+func produceObj(sqlDB *sql.DB) (dbObj *MariaDB, err error) {
+	dbObj = &MariaDB{sqlDB, nil}
+	dbObj.qm, err = NewQueryManager(sqlDB)
+	if err != nil {
+		_ = sqlDB.Close()
+		return nil, errors.New("QueryManager Init: " + err.Error())
+	}
+	return dbObj, nil
+}
+
+//go:embed dbqueries/check.sql
+var mariadbCheckQuery string
+
+func (mariadb *MariaDB) Check() error {
+	rows, err := mariadb.db.Query(mariadbCheckQuery)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	tableMap := map[string]bool{
+		"KeyRoleIntersect":  false,
+		"Keys":              false,
+		"Permissions":       false,
+		"Ratelimits":        false,
+		"Resources":         false,
+		"ResourcePaths":     false,
+		"Tokens":            false,
+		"RolePermIntersect": false,
+		"Roles":             false,
+		"schema_version":    false,
+	}
+	// table names are lowercase if the server uses lower_case_table_names
+	tableNames := make(map[string]string, len(tableMap))
+	for table := range tableMap {
+		tableNames[strings.ToLower(table)] = table
+	}
+
+	var name string
+	for rows.Next() {
+		err = rows.Scan(&name)
+		if err != nil {
+			return err
+		}
+		if table, ok := tableNames[strings.ToLower(name)]; ok {
+			tableMap[table] = true
+		} else {
+			return errors.New(fmt.Sprintf("Extraneous table \"%s\" should not exist in database", name))
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for key, val := range tableMap {
+		if !val {
+			return errors.New(fmt.Sprintf("The table \"%s\" does not exist in database", key))
+		}
+	}
+
+	return nil
+}
+
+// Close closes the prepared statements and the connection pool.
+func (mariadb *MariaDB) Close() error {
+	err := mariadb.qm.freePreparedQueries()
+	if err != nil {
+		return err
+	}
+	return mariadb.db.Close()
+}
+
+//go:embed dbqueries/destroy.sql
+var mariadbDatabaseDestructionQuery string
+
+func (mariadb *MariaDB) Destroy() error {
+	return execScript(mariadb.db, mariadbDatabaseDestructionQuery)
+}
+
+/////////////////////////////////////////
+//									   //
+/*-------------------------------------*\
+//       NON INTERFACE FUNCTIONS	   //
+\*-------------------------------------*/
+//									   //
+/////////////////////////////////////////
+
+// execer is a database or transaction which queries may be executed in.
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// execScript runs the statements of a script one at a time, since the
+// driver does not allow multiple statements in a query by default.
+// Statements must be separated by a semicolon at the end of a line.
+func execScript(db execer, script string) error {
+	for _, query := range strings.Split(script, ";\n") {
+		query = strings.TrimSuffix(strings.TrimSpace(query), ";")
+		if query == "" {
+			continue
+		}
+		_, err := db.Exec(query)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getResourceRolePermIter returns an iterator over the permission nodes of a resource,
+// which fills in the returned RolePerm and returns io.EOF once all have been read.
+func (mariadb *MariaDB) getResourceRolePermIter(tx *sql.Tx, resourceID string) (func() error, *entities.RolePerm, error) {
+	stmt := tx.Stmt(mariadb.qm.GetResourceRoles)
+	rows, err := stmt.Query(resourceID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rolePerm entities.RolePerm
+	roleIterNext := func() error {
+		if !rows.Next() {
+			err := rows.Err()
+			_ = rows.Close()
+			if err != nil {
+				return err
+			}
+			return io.EOF
+		}
+		return rows.Scan(&rolePerm.Role.ID, &rolePerm.Perm.Status, &rolePerm.Perm.TypeRWMD)
+	}
+	return roleIterNext, &rolePerm, nil
+}
+
+func (mariadb *MariaDB) createResourcePermissions(tx *sql.Tx, resource *entities.Resource) error {
+	for key, status := range resource.OperationNodes {
+		permissionID, err := mariadb.constructPermNode(tx, &entities.Permission{
+			ResourceID: resource.ID,
+			TypeRWMD:   key.Type,
+			Status:     status,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = mariadb.grantPermNode(tx, permissionID, key.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isDuplicateError returns whether an error was caused
+// by inserting a row with a primary key already in use.
+func isDuplicateError(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == errDuplicateEntry
+}
+
+// rollback rolls back a transaction after an error, and returns the error,
+// along with the error from rolling back if it fails, such as when the
+// connection to the database was lost.
+func rollback(tx *sql.Tx, err error) error {
+	rbErr := tx.Rollback()
+	if rbErr != nil {
+		return fmt.Errorf("%w (rolling back the transaction also failed: %v)", err, rbErr)
+	}
+	return err
+}
+
+func (mariadb *MariaDB) deleteObjByID(stmt *sql.Stmt, args ...any) error {
+	//begin transaction
+	tx, err := mariadb.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	//run delete statement in transaction
+	txStmt := tx.Stmt(stmt)
+	_, err = txStmt.Exec(args...)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	return tx.Commit()
+}
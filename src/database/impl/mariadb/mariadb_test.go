@@ -0,0 +1,72 @@
+package mariadb
+
+import (
+	"errors"
+	"fsrv/src/config"
+	"fsrv/src/database"
+	"fsrv/src/database/databasetest"
+	"fsrv/src/database/migration"
+	"github.com/go-playground/assert/v2"
+	"os"
+	"strconv"
+	"testing"
+)
+
+func TestMariaDB_Check(t *testing.T) {
+	db := getDB(t)
+	assert.Equal(t, nil, db.Check())
+
+	_, err := db.db.Exec("CREATE TABLE Extraneous (id INTEGER)")
+	assert.Equal(t, nil, err)
+	//the database outlives the test, unlike a sqlite file
+	t.Cleanup(func() {
+		_, _ = db.db.Exec("DROP TABLE Extraneous")
+	})
+	assert.NotEqual(t, nil, db.Check())
+}
+
+func TestMariaDB_Conformance(t *testing.T) {
+	databasetest.Run(t, func(t *testing.T) database.DBInterface {
+		return getDB(t)
+	})
+}
+
+func TestMariaDB_Close(t *testing.T) {
+	db := getDB(t)
+	assert.Equal(t, nil, db.Close())
+
+	_, err := db.GetKeyIDs(10, 0)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestMariaDB_Migrate(t *testing.T) {
+	db := getDB(t)
+	assert.Equal(t, nil, db.Close())
+	cfg := &config.Database{Type: config.DatabaseMariaDB, ConnectionString: os.Getenv(testDSNEnv)}
+
+	latest := len(migrations)
+	from, to, err := Migrate(cfg, 1)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, latest, from)
+	assert.Equal(t, 1, to)
+
+	// outdated schemas are only upgraded automatically up to the configured version
+	cfg.Version = 1
+	_, err = Open(cfg)
+	assert.Equal(t, true, errors.Is(err, migration.ErrSchemaOutdated))
+	cfg.Version = 0
+	db, err = Open(cfg)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, db.Check())
+
+	// newer schemas are refused
+	_, err = db.db.Exec("UPDATE schema_version SET version = " + strconv.Itoa(latest+1))
+	assert.Equal(t, nil, err)
+	//the database outlives the test, unlike a sqlite file
+	t.Cleanup(func() {
+		_, _ = db.db.Exec("UPDATE schema_version SET version = " + strconv.Itoa(latest))
+		_ = db.Close()
+	})
+	_, err = Open(cfg)
+	assert.Equal(t, true, errors.Is(err, migration.ErrSchemaNewer))
+}
@@ -0,0 +1,89 @@
+-- identifiers and paths are binary strings, so that they are compared byte
+-- for byte, as in sqlite. references between tables are not enforced, as in
+-- sqlite, since permissions may be granted to roles which do not exist yet.
+CREATE TABLE IF NOT EXISTS Ratelimits
+(
+    ratelimitid VARBINARY(255) PRIMARY KEY,
+    requests    BIGINT NOT NULL, -- number of requests in a given period
+    burst       BIGINT NOT NULL, -- number of requests allowed in a short burst
+    reset       BIGINT NOT NULL  -- timestamp for reset (unix millis)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+
+
+CREATE TABLE IF NOT EXISTS `Keys`
+(
+    keyid       VARBINARY(255) PRIMARY KEY,
+    note        TEXT,
+    ratelimitid VARBINARY(255),
+    expires     BIGINT NOT NULL, -- unix millis
+    created     BIGINT NOT NULL  -- unix millis
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+
+
+CREATE TABLE IF NOT EXISTS Resources
+(
+    resourceid VARBINARY(255) PRIMARY KEY,
+    flags      TINYINT UNSIGNED NOT NULL
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+
+CREATE TABLE IF NOT EXISTS Roles
+(
+    roleid         VARBINARY(255) PRIMARY KEY,
+    rolePrecedence INT     NOT NULL DEFAULT 0, -- note, roleid is tiebreaker
+    roleTypeRK     TINYINT NOT NULL,           -- 0=role 1=keyrole
+
+    INDEX RolesByPrecedence (rolePrecedence), -- for ordering
+    INDEX RolesByRoleType (roleTypeRK)        -- for lookups
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+
+CREATE TABLE IF NOT EXISTS Permissions
+(
+    permissionid      BIGINT PRIMARY KEY AUTO_INCREMENT, -- file / dir permission id
+    resourceid        VARBINARY(255) NOT NULL,           -- type of permission granted
+    permTypeRWMD      TINYINT        NOT NULL,           -- 0=read 1=write
+    permTypeDenyAllow TINYINT        NOT NULL,           -- -0=deny 1=allow
+
+    INDEX PermissionsPerResource (resourceid)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+
+
+CREATE TABLE IF NOT EXISTS KeyRoleIntersect
+(
+    keyid  VARBINARY(255),
+    roleid VARBINARY(255),
+
+    INDEX RolesPerKey (keyid) -- create index for most commonly accessed direction
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+
+CREATE TABLE IF NOT EXISTS RolePermIntersect
+(
+    roleid       VARBINARY(255),
+    permissionid BIGINT,
+
+    INDEX PermissionsPerRole (roleid)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+
+CREATE TABLE IF NOT EXISTS ResourcePaths
+(
+    path       VARBINARY(3072) PRIMARY KEY, -- slash separated, relative to the file manager path
+    resourceid VARBINARY(255) NOT NULL,
+
+    INDEX ResourcePathsByResource (resourceid)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
+
+
+
+CREATE TABLE IF NOT EXISTS Tokens
+(
+    tokenid VARBINARY(255) PRIMARY KEY, -- hash of the token
+    note    TEXT,
+    expires BIGINT NOT NULL, -- unix millis
+    created BIGINT NOT NULL  -- unix millis
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4;
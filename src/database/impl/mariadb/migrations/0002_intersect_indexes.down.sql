@@ -0,0 +1,2 @@
+DROP INDEX KeysPerRole ON KeyRoleIntersect;
+DROP INDEX RolesPerPermission ON RolePermIntersect;
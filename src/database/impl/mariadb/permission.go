@@ -0,0 +1,109 @@
+package mariadb
+
+import (
+	"database/sql"
+	"fsrv/src/database/entities"
+)
+
+/*
+RevokePermission Removes the permission nodes of the specified roles for an operation on a resource
+-Removes the RolePermIntersect entries of each role for the resourceID and operationType, of either denyAllow status
+-Removes the permission nodes of the resource which no longer have any associated roles
+*/
+func (mariadb *MariaDB) RevokePermission(permission *entities.Permission, roles ...string) error {
+	//begin transaction
+	tx, err := mariadb.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	//delete RolePermIntersect entries
+	stmt := tx.Stmt(mariadb.qm.DelRPIEntryByRoleAndOperation)
+	for _, role := range roles {
+		_, err = stmt.Exec(role, permission.ResourceID, permission.TypeRWMD)
+		if err != nil {
+			return rollback(tx, err)
+		}
+	}
+
+	//delete orphaned permission nodes
+	_, err = tx.Stmt(mariadb.qm.DelOrphanedPermissionsByResourceID).Exec(permission.ResourceID)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	return tx.Commit()
+}
+
+/*
+GrantPermission Allows or denies the specified roles permission to perform an operation on a resource
+-Roles may be roles, KeyRoles (key ids) or the catch-all role "*"
+-Replaces any existing permission node of each role for the same resourceID and operationType
+*/
+func (mariadb *MariaDB) GrantPermission(permission *entities.Permission, roles ...string) error {
+	//begin transaction
+	tx, err := mariadb.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	//get permissionid of existing/new permission node
+	permissionID, err := mariadb.constructPermNode(tx, permission)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	//replace existing nodes of roles with the permission node
+	stmtDel := tx.Stmt(mariadb.qm.DelRPIEntryByRoleAndOperation)
+	for _, role := range roles {
+		_, err = stmtDel.Exec(role, permission.ResourceID, permission.TypeRWMD)
+		if err != nil {
+			return rollback(tx, err)
+		}
+		err = mariadb.grantPermNode(tx, permissionID, role)
+		if err != nil {
+			return rollback(tx, err)
+		}
+	}
+
+	//delete permission nodes orphaned by replacement
+	_, err = tx.Stmt(mariadb.qm.DelOrphanedPermissionsByResourceID).Exec(permission.ResourceID)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	return tx.Commit()
+}
+
+/////////////////////////////////////////
+//									   //
+/*-------------------------------------*\
+//       NON INTERFACE FUNCTIONS	   //
+\*-------------------------------------*/
+//									   //
+/////////////////////////////////////////
+
+// get permissionID of existing node or construct new permission node
+func (mariadb *MariaDB) constructPermNode(tx *sql.Tx, permission *entities.Permission) (permissionID int64, err error) {
+	stmt := tx.Stmt(mariadb.qm.GetPermissionIDByData)
+	row := stmt.QueryRow(permission.ResourceID, permission.TypeRWMD, permission.Status)
+	err = row.Scan(&permissionID)
+	if err == sql.ErrNoRows {
+		stmt = tx.Stmt(mariadb.qm.InsPermissionData)
+		res, err := stmt.Exec(permission.ResourceID, permission.TypeRWMD, permission.Status)
+		if err != nil {
+			return -1, err
+		}
+		return res.LastInsertId()
+	} else if err != nil {
+		return -1, err
+	}
+	return permissionID, nil
+}
+
+// add role to permission node
+func (mariadb *MariaDB) grantPermNode(tx *sql.Tx, permissionID int64, role string) error {
+	stmt := tx.Stmt(mariadb.qm.InsRolePermIntersectData)
+	_, err := stmt.Exec(role, permissionID)
+	return err
+}
@@ -0,0 +1,306 @@
+package mariadb
+
+import (
+	"database/sql"
+	_ "embed"
+	"reflect"
+)
+
+type QueryManager struct {
+	InsKeyData                                   *sql.Stmt
+	GetRateLimitIDIfExists                       *sql.Stmt
+	InsRateLimitData                             *sql.Stmt
+	GetRoleIDIfExists                            *sql.Stmt
+	GetKeyIDIfExists                             *sql.Stmt
+	InsKeyRoleIntersectData                      *sql.Stmt
+	InsRoleData                                  *sql.Stmt
+	InsResourceData                              *sql.Stmt
+	GetKeyIDs                                    *sql.Stmt
+	GetKeyData                                   *sql.Stmt
+	GetRateLimitDataByID                         *sql.Stmt
+	GetRolesByKeyIDOrdered                       *sql.Stmt
+	GetResourceIDs                               *sql.Stmt
+	GetResourceFlagsByID                         *sql.Stmt
+	GetRoleIDs                                   *sql.Stmt
+	GetPermissionIDByData                        *sql.Stmt
+	InsPermissionData                            *sql.Stmt
+	GetPKOfLastInserted                          *sql.Stmt
+	GetRolePermIntersectReferencesByPermissionID *sql.Stmt
+	InsRolePermIntersectData                     *sql.Stmt
+	GetResourceRoles                             *sql.Stmt
+	GetKeyRateLimitID                            *sql.Stmt
+	UpdRateLimitData                             *sql.Stmt
+	UpdKeyRateLimitID                            *sql.Stmt
+	DelPermissionByID                            *sql.Stmt
+	DelRateLimitByID                             *sql.Stmt
+	DelKeyByID                                   *sql.Stmt
+	DelResourceByID                              *sql.Stmt
+	DelRoleByID                                  *sql.Stmt
+	DelPermissionByResourceID                    *sql.Stmt
+	DelRPIEntryByRoleID                          *sql.Stmt
+	UpdKeyData                                   *sql.Stmt
+	DelKRIEntriesByKeyID                         *sql.Stmt
+	GetRoleData                                  *sql.Stmt
+	InsKeyRoleIntersectIfMissing                 *sql.Stmt
+	DelKRIEntry                                  *sql.Stmt
+	DelKRIEntriesByRoleID                        *sql.Stmt
+	DelRPIEntryByRoleAndOperation                *sql.Stmt
+	DelOrphanedPermissionsByResourceID           *sql.Stmt
+	DelRPIEntriesByResourceID                    *sql.Stmt
+	UpdKeysRateLimitID                           *sql.Stmt
+	InsTokenData                                 *sql.Stmt
+	GetTokens                                    *sql.Stmt
+	GetTokenData                                 *sql.Stmt
+	DelTokenByID                                 *sql.Stmt
+	InsResourcePath                              *sql.Stmt
+	GetPathResourceID                            *sql.Stmt
+	GetResourcePathsByPrefix                     *sql.Stmt
+	DelResourcePath                              *sql.Stmt
+	DelResourcePathPrefix                        *sql.Stmt
+	DelResourcePathByResourceID                  *sql.Stmt
+}
+
+//go:embed readqueries/getResourceRoles.sql
+var mariadbGetResourceRoles string
+
+func NewQueryManager(db *sql.DB) (qm *QueryManager, err error) {
+	//create obj
+	var queryManager QueryManager
+	qm = &queryManager
+
+	//Insert Operations
+	qm.InsKeyData, err = db.Prepare("INSERT INTO `Keys` (keyid, note, ratelimitid, expires, created) VALUES (?, ?, ?, ?, ?)") //CreateKey
+	if err != nil {
+		return qm, err
+	}
+	qm.InsRateLimitData, err = db.Prepare("INSERT INTO Ratelimits (ratelimitid, requests, burst, reset) VALUES (?, ?, ?, ?)") //CreateKey
+	if err != nil {
+		return qm, err
+	}
+	qm.InsKeyRoleIntersectData, err = db.Prepare("INSERT INTO KeyRoleIntersect (keyid, roleid) VALUES (?, ?)") //CreateKey
+	if err != nil {
+		return qm, err
+	}
+	qm.InsRoleData, err = db.Prepare("INSERT INTO Roles (roleid, roleTypeRK, rolePrecedence) VALUES (?, ?, ?)") //CreateKey, CreateRole
+	if err != nil {
+		return qm, err
+	}
+	qm.InsResourceData, err = db.Prepare("INSERT INTO Resources (resourceid,flags) VALUES (?, ?)") //CreateResource
+	if err != nil {
+		return qm, err
+	}
+	qm.InsPermissionData, err = db.Prepare("INSERT INTO Permissions (resourceid, permTypeRWMD, permTypeDenyAllow) VALUES (?, ?, ?)") //GrantPermission
+	if err != nil {
+		return qm, err
+	}
+	qm.InsKeyRoleIntersectIfMissing, err = db.Prepare("INSERT INTO KeyRoleIntersect (keyid, roleid) SELECT ?, ? FROM DUAL WHERE NOT EXISTS (SELECT 1 FROM KeyRoleIntersect WHERE keyid = ? AND roleid = ?)") //GiveRole
+	if err != nil {
+		return qm, err
+	}
+	qm.InsTokenData, err = db.Prepare("INSERT INTO Tokens (tokenid, note, expires, created) VALUES (?, ?, ?, ?)") //CreateToken
+	if err != nil {
+		return qm, err
+	}
+	qm.InsResourcePath, err = db.Prepare("REPLACE INTO ResourcePaths (path, resourceid) VALUES (?, ?)") //SetResourcePath
+	if err != nil {
+		return qm, err
+	}
+
+	//Get operations
+	qm.GetRateLimitIDIfExists, err = db.Prepare("SELECT ratelimitid FROM Ratelimits WHERE ratelimitid = ?") //CreateKey
+	if err != nil {
+		return qm, err
+	}
+	qm.GetRoleIDIfExists, err = db.Prepare("SELECT roleid FROM Roles WHERE roleid = ? AND roleTypeRK = 0") //CreateKey, GiveRole
+	if err != nil {
+		return qm, err
+	}
+	qm.GetKeyIDIfExists, err = db.Prepare("SELECT keyid FROM `Keys` WHERE keyid = ?")
+	if err != nil {
+		return qm, err
+	}
+	qm.GetKeyIDs, err = db.Prepare("SELECT keyid FROM `Keys` ORDER BY created, keyid LIMIT ? OFFSET ?") //GetKeyIDs
+	if err != nil {
+		return qm, err
+	}
+	qm.GetKeyData, err = db.Prepare("SELECT note, ratelimitid, created, expires FROM `Keys` WHERE keyid = ?") //GetKeyData
+	if err != nil {
+		return qm, err
+	}
+	qm.GetRateLimitDataByID, err = db.Prepare("SELECT requests, burst, reset FROM Ratelimits WHERE ratelimitid = ?") //GetKeyData
+	if err != nil {
+		return qm, err
+	}
+	qm.GetRolesByKeyIDOrdered, err = db.Prepare("SELECT Roles.roleid FROM Roles JOIN KeyRoleIntersect KRI on Roles.roleid = KRI.roleid WHERE keyid = ? ORDER BY rolePrecedence") //GetKeyData
+	if err != nil {
+		return qm, err
+	}
+	qm.GetResourceIDs, err = db.Prepare("SELECT resourceid FROM Resources ORDER BY resourceid LIMIT ? OFFSET ?") //GetResourceIDs
+	if err != nil {
+		return qm, err
+	}
+	qm.GetResourceFlagsByID, err = db.Prepare("SELECT flags FROM Resources WHERE resourceid = ?") //GetResourceData
+	if err != nil {
+		return qm, err
+	}
+	qm.GetRoleIDs, err = db.Prepare("SELECT roleid FROM Roles WHERE roleTypeRK = 0 ORDER BY rolePrecedence, roleid LIMIT ? OFFSET ?") //GetRoles
+	if err != nil {
+		return qm, err
+	}
+	qm.GetPermissionIDByData, err = db.Prepare("SELECT permissionid FROM Permissions WHERE resourceid = ? AND permTypeRWMD = ? AND permTypeDenyAllow = ?") //GrantPermission, RevokePermission
+	if err != nil {
+		return qm, err
+	}
+	qm.GetPKOfLastInserted, err = db.Prepare("SELECT LAST_INSERT_ID()") //GrantPermission
+	if err != nil {
+		return qm, err
+	}
+	qm.GetRolePermIntersectReferencesByPermissionID, err = db.Prepare("SELECT COUNT(permissionid) FROM RolePermIntersect WHERE permissionid = ?") //RevokePermission
+	if err != nil {
+		return qm, err
+	}
+	qm.InsRolePermIntersectData, err = db.Prepare("INSERT INTO RolePermIntersect (roleid, permissionid) VALUES (?,?)")
+	if err != nil {
+		return qm, err
+	}
+	qm.GetResourceRoles, err = db.Prepare(mariadbGetResourceRoles)
+	if err != nil {
+		return qm, err
+	}
+	qm.GetKeyRateLimitID, err = db.Prepare("SELECT ratelimitid FROM `Keys` WHERE keyid=?")
+	if err != nil {
+		return qm, err
+	}
+	qm.UpdRateLimitData, err = db.Prepare("UPDATE Ratelimits SET ratelimitid = ?, requests = ?, burst = ?, reset = ? WHERE ratelimitid = ?")
+	if err != nil {
+		return qm, err
+	}
+	qm.UpdKeyRateLimitID, err = db.Prepare("UPDATE `Keys` SET ratelimitid = ? WHERE keyid = ?")
+	if err != nil {
+		return qm, err
+	}
+	qm.UpdKeyData, err = db.Prepare("UPDATE `Keys` SET note = ?, ratelimitid = ?, expires = ? WHERE keyid = ?") //UpdateKey
+	if err != nil {
+		return qm, err
+	}
+	qm.GetRoleData, err = db.Prepare("SELECT rolePrecedence FROM Roles WHERE roleid = ? AND roleTypeRK = 0") //GetRoleData
+	if err != nil {
+		return qm, err
+	}
+	qm.GetTokens, err = db.Prepare("SELECT tokenid, note, expires, created FROM Tokens ORDER BY created, tokenid LIMIT ? OFFSET ?") //GetTokens
+	if err != nil {
+		return qm, err
+	}
+	qm.GetTokenData, err = db.Prepare("SELECT note, expires, created FROM Tokens WHERE tokenid = ?") //GetTokenData
+	if err != nil {
+		return qm, err
+	}
+	qm.GetPathResourceID, err = db.Prepare("SELECT resourceid FROM ResourcePaths WHERE path = ?") //GetPathResourceID
+	if err != nil {
+		return qm, err
+	}
+	// LEFT is used rather than LIKE, so that paths do not need to be escaped
+	qm.GetResourcePathsByPrefix, err = db.Prepare("SELECT path, resourceid FROM ResourcePaths WHERE path = ? OR LEFT(path, ?) = ?") //MoveResourcePaths
+	if err != nil {
+		return qm, err
+	}
+
+	//Delete operations
+	qm.DelPermissionByID, err = db.Prepare("DELETE FROM Permissions WHERE permissionid = ?") //RevokePermission
+	if err != nil {
+		return qm, err
+	}
+	qm.DelRateLimitByID, err = db.Prepare("DELETE FROM Ratelimits WHERE ratelimitid = ?") //DeleteRateLimit
+	if err != nil {
+		return qm, err
+	}
+	qm.DelKeyByID, err = db.Prepare("DELETE FROM `Keys` WHERE keyid = ?") //DeleteKey
+	if err != nil {
+		return qm, err
+	}
+	qm.DelResourceByID, err = db.Prepare("DELETE FROM Resources WHERE resourceid = ?")
+	if err != nil {
+		return qm, err
+	}
+	qm.DelRoleByID, err = db.Prepare("DELETE FROM Roles WHERE roleid = ?")
+	if err != nil {
+		return qm, err
+	}
+	qm.DelPermissionByResourceID, err = db.Prepare("DELETE FROM Permissions WHERE resourceid = ?")
+	if err != nil {
+		return qm, err
+	}
+	qm.DelRPIEntryByRoleID, err = db.Prepare("DELETE FROM RolePermIntersect WHERE roleid = ?")
+	if err != nil {
+		return qm, err
+	}
+	qm.DelKRIEntriesByKeyID, err = db.Prepare("DELETE FROM KeyRoleIntersect WHERE keyid = ?") //DeleteKey
+	if err != nil {
+		return qm, err
+	}
+	qm.DelKRIEntry, err = db.Prepare("DELETE FROM KeyRoleIntersect WHERE keyid = ? AND roleid = ?") //TakeRole
+	if err != nil {
+		return qm, err
+	}
+	qm.DelKRIEntriesByRoleID, err = db.Prepare("DELETE FROM KeyRoleIntersect WHERE roleid = ?") //DeleteRole
+	if err != nil {
+		return qm, err
+	}
+	qm.DelRPIEntryByRoleAndOperation, err = db.Prepare("DELETE FROM RolePermIntersect WHERE roleid = ? AND permissionid IN (SELECT permissionid FROM Permissions WHERE resourceid = ? AND permTypeRWMD = ?)") //GrantPermission, RevokePermission
+	if err != nil {
+		return qm, err
+	}
+	qm.DelOrphanedPermissionsByResourceID, err = db.Prepare("DELETE FROM Permissions WHERE resourceid = ? AND permissionid NOT IN (SELECT permissionid FROM RolePermIntersect)") //GrantPermission, RevokePermission
+	if err != nil {
+		return qm, err
+	}
+	qm.DelRPIEntriesByResourceID, err = db.Prepare("DELETE FROM RolePermIntersect WHERE permissionid IN (SELECT permissionid FROM Permissions WHERE resourceid = ?)") //DeleteResource
+	if err != nil {
+		return qm, err
+	}
+	qm.UpdKeysRateLimitID, err = db.Prepare("UPDATE `Keys` SET ratelimitid = ? WHERE ratelimitid = ?") //UpdateRateLimit
+	if err != nil {
+		return qm, err
+	}
+	qm.DelTokenByID, err = db.Prepare("DELETE FROM Tokens WHERE tokenid = ?") //DeleteToken
+	if err != nil {
+		return qm, err
+	}
+	qm.DelResourcePath, err = db.Prepare("DELETE FROM ResourcePaths WHERE path = ?") //DeleteResourcePath
+	if err != nil {
+		return qm, err
+	}
+	qm.DelResourcePathPrefix, err = db.Prepare("DELETE FROM ResourcePaths WHERE path = ? OR LEFT(path, ?) = ?") //DeleteResourcePaths, MoveResourcePaths
+	if err != nil {
+		return qm, err
+	}
+	qm.DelResourcePathByResourceID, err = db.Prepare("DELETE FROM ResourcePaths WHERE resourceid = ?") //DeleteResource
+	if err != nil {
+		return qm, err
+	}
+
+	//qm.q, err = db.Prepare("")
+	//if err != nil {
+	//	return qm, err
+	//}
+
+	return qm, nil
+}
+
+// freePreparedQueries closes every prepared statement of the query manager.
+func (qm *QueryManager) freePreparedQueries() error {
+	v := reflect.ValueOf(qm).Elem()
+	count := v.NumField()
+
+	for i := 0; i < count; i++ {
+		stmt, ok := v.Field(i).Interface().(*sql.Stmt)
+		if !ok || stmt == nil {
+			continue
+		}
+		err := stmt.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package mariadb
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"fsrv/src/database"
+	"fsrv/src/database/entities"
+	"fsrv/utils/serde"
+	"time"
+)
+
+func (mariadb *MariaDB) CreateRateLimit(limit *entities.RateLimit) error {
+	reset := time.Duration(limit.Refill).Milliseconds()
+	_, err := mariadb.qm.InsRateLimitData.Exec(limit.ID, limit.Limit, limit.Burst, reset)
+	if isDuplicateError(err) {
+		return database.ErrRateLimitDuplicate
+	}
+	return err
+}
+
+func (mariadb *MariaDB) DeleteRateLimit(rateLimitID string) error {
+	return mariadb.deleteObjByID(mariadb.qm.DelRateLimitByID, rateLimitID)
+}
+
+func (mariadb *MariaDB) SetRateLimit(key *entities.Key, limitID string) error {
+	tx, err := mariadb.db.Begin()
+	if err != nil {
+		return err
+	}
+	stmt := tx.Stmt(mariadb.qm.UpdKeyRateLimitID)
+	_, err = stmt.Exec(limitID, key.ID)
+	if err != nil {
+		return rollback(tx, err)
+	}
+	return tx.Commit()
+}
+
+func (mariadb *MariaDB) GetRateLimitData(ratelimitid string) (*entities.RateLimit, error) {
+	row := mariadb.qm.GetRateLimitDataByID.QueryRow(ratelimitid)
+	var rateLimit entities.RateLimit
+	var reset int64
+	err := row.Scan(&rateLimit.Limit, &rateLimit.Burst, &reset)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, database.ErrRateLimitMissing
+		}
+		return nil, err
+	}
+	rateLimit.ID = ratelimitid
+	rateLimit.Refill = serde.Duration(reset * int64(time.Millisecond))
+
+	return &rateLimit, nil
+}
+
+func (mariadb *MariaDB) UpdateRateLimit(rateLimitID string, rateLimit *entities.RateLimit) error {
+	tx, err := mariadb.db.Begin()
+	if err != nil {
+		return err
+	}
+	stmt := tx.Stmt(mariadb.qm.UpdRateLimitData)
+	reset := time.Duration(rateLimit.Refill).Milliseconds()
+	res, err := stmt.Exec(rateLimit.ID, rateLimit.Limit, rateLimit.Burst, reset, rateLimitID)
+	if err != nil {
+		if isDuplicateError(err) {
+			err = database.ErrRateLimitDuplicate
+		}
+		return rollback(tx, err)
+	}
+	rowNum, err := res.RowsAffected()
+	if err != nil {
+		return rollback(tx, err)
+	}
+	if rowNum == 0 {
+		return rollback(tx, database.ErrRateLimitMissing)
+	}
+	if rowNum != 1 {
+		return rollback(tx, errors.New(fmt.Sprintf("Failed to update rateLimit %d rows affected", rowNum)))
+	}
+
+	//keys using the rate limit follow it when it is renamed
+	if rateLimit.ID != rateLimitID {
+		_, err = tx.Stmt(mariadb.qm.UpdKeysRateLimitID).Exec(rateLimit.ID, rateLimitID)
+		if err != nil {
+			return rollback(tx, err)
+		}
+	}
+	return tx.Commit()
+}
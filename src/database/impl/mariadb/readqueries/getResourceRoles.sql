@@ -0,0 +1,7 @@
+-- get allowed/denied roles, keys and the catch-all by precedence for a given resource
+SELECT RPI.roleid, P.permTypeDenyAllow, P.permTypeRWMD
+FROM Permissions P
+         JOIN RolePermIntersect RPI ON P.permissionid = RPI.permissionid
+         LEFT JOIN Roles ON RPI.roleid = Roles.roleid
+WHERE P.resourceid = ?
+ORDER BY Roles.rolePrecedence, RPI.roleid;
\ No newline at end of file
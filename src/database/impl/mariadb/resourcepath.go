@@ -0,0 +1,90 @@
+package mariadb
+
+import (
+	"database/sql"
+	"fsrv/src/database"
+	"strings"
+)
+
+func (mariadb *MariaDB) SetResourcePath(path string, resourceID string) error {
+	_, err := mariadb.qm.InsResourcePath.Exec(path, resourceID)
+	return err
+}
+
+func (mariadb *MariaDB) GetPathResourceID(path string) (string, error) {
+	var resourceID string
+	row := mariadb.qm.GetPathResourceID.QueryRow(path)
+	err := row.Scan(&resourceID)
+	if err == sql.ErrNoRows {
+		return "", database.ErrResourcePathMissing
+	}
+	return resourceID, err
+}
+
+// MoveResourcePaths reads the paths to move, deletes them, and inserts them
+// under the new path, replacing any already there, since an UPDATE cannot
+// replace the rows whose primary keys it conflicts with.
+func (mariadb *MariaDB) MoveResourcePaths(oldPath string, newPath string) error {
+	if oldPath == newPath {
+		return nil
+	}
+	prefix := descendantPrefix(oldPath)
+
+	//begin transaction
+	tx, err := mariadb.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	//read paths to move, which are binary, so LEFT counts bytes
+	rows, err := tx.Stmt(mariadb.qm.GetResourcePathsByPrefix).Query(oldPath, len(prefix), prefix)
+	if err != nil {
+		return rollback(tx, err)
+	}
+	resourceIDs := make(map[string]string)
+	var path, resourceID string
+	for rows.Next() {
+		err = rows.Scan(&path, &resourceID)
+		if err != nil {
+			_ = rows.Close()
+			return rollback(tx, err)
+		}
+		resourceIDs[newPath+strings.TrimPrefix(path, oldPath)] = resourceID
+	}
+	if err = rows.Err(); err != nil {
+		return rollback(tx, err)
+	}
+
+	//delete old paths
+	_, err = tx.Stmt(mariadb.qm.DelResourcePathPrefix).Exec(oldPath, len(prefix), prefix)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	//insert new paths
+	stmt := tx.Stmt(mariadb.qm.InsResourcePath)
+	for path, resourceID := range resourceIDs {
+		_, err = stmt.Exec(path, resourceID)
+		if err != nil {
+			return rollback(tx, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (mariadb *MariaDB) DeleteResourcePath(path string) error {
+	_, err := mariadb.qm.DelResourcePath.Exec(path)
+	return err
+}
+
+func (mariadb *MariaDB) DeleteResourcePaths(path string) error {
+	prefix := descendantPrefix(path)
+	_, err := mariadb.qm.DelResourcePathPrefix.Exec(path, len(prefix), prefix)
+	return err
+}
+
+// descendantPrefix returns the prefix shared by the paths of all descendants of a path.
+func descendantPrefix(path string) string {
+	return strings.TrimSuffix(path, "/") + "/"
+}
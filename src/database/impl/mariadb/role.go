@@ -0,0 +1,181 @@
+package mariadb
+
+import (
+	"database/sql"
+	"fsrv/src/database"
+	"fsrv/src/database/entities"
+)
+
+func (mariadb *MariaDB) CreateRole(role *entities.Role) error {
+	if role.ID == "" || role.ID == "*" {
+		return database.ErrRoleNameBad
+	}
+
+	tx, err := mariadb.db.Begin()
+	if err != nil {
+		return err
+	}
+	stmt := tx.Stmt(mariadb.qm.InsRoleData)
+	res, err := stmt.Exec(role.ID, 0, role.Precedence)
+	if err != nil {
+		if isDuplicateError(err) {
+			err = database.ErrRoleDuplicate
+		}
+		return rollback(tx, err)
+	}
+	rowsInserted, err := res.RowsAffected()
+	if err != nil {
+		return rollback(tx, err)
+	}
+	if rowsInserted == 0 {
+		return rollback(tx, sql.ErrNoRows)
+	}
+	return tx.Commit()
+}
+
+func (mariadb *MariaDB) DeleteRole(name string) error {
+	//begin transaction
+	tx, err := mariadb.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	//ensure the role exists, and is not a KeyRole
+	err = mariadb.checkRoleExists(tx, name)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	//delete associated RolePermIntersect entries
+	stmt := tx.Stmt(mariadb.qm.DelRPIEntryByRoleID)
+	_, err = stmt.Exec(name)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	//delete associated KeyRoleIntersect entries
+	stmt = tx.Stmt(mariadb.qm.DelKRIEntriesByRoleID)
+	_, err = stmt.Exec(name)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	//delete underlying role
+	stmt = tx.Stmt(mariadb.qm.DelRoleByID)
+	_, err = stmt.Exec(name)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	//commit
+	return tx.Commit()
+}
+
+func (mariadb *MariaDB) GetRoles(pageSize int, offset int) ([]string, error) {
+	var role string
+	var roles []string
+	rows, err := mariadb.qm.GetRoleIDs.Query(pageSize, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		err = rows.Scan(&role)
+		if err != nil {
+			return roles, err
+		}
+		roles = append(roles, role)
+	}
+
+	return roles, rows.Err()
+}
+
+func (mariadb *MariaDB) GetRoleData(roleID string) (*entities.Role, error) {
+	role := entities.Role{ID: roleID}
+	row := mariadb.qm.GetRoleData.QueryRow(roleID)
+	err := row.Scan(&role.Precedence)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, database.ErrRoleMissing
+		}
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (mariadb *MariaDB) GiveRole(keyid string, roles ...string) error {
+	//begin transaction
+	tx, err := mariadb.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	err = mariadb.checkKeyExists(tx, keyid)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	stmtIns := tx.Stmt(mariadb.qm.InsKeyRoleIntersectIfMissing)
+	for _, role := range roles {
+		err = mariadb.checkRoleExists(tx, role)
+		if err != nil {
+			return rollback(tx, err)
+		}
+
+		_, err = stmtIns.Exec(keyid, role, keyid, role)
+		if err != nil {
+			return rollback(tx, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (mariadb *MariaDB) TakeRole(keyid string, roles ...string) error {
+	//begin transaction
+	tx, err := mariadb.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	err = mariadb.checkKeyExists(tx, keyid)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	stmtDel := tx.Stmt(mariadb.qm.DelKRIEntry)
+	for _, role := range roles {
+		if role == keyid {
+			//the KeyRole cannot be taken from its key
+			return rollback(tx, database.ErrRoleNameBad)
+		}
+
+		_, err = stmtDel.Exec(keyid, role)
+		if err != nil {
+			return rollback(tx, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// checkRoleExists returns ErrRoleMissing if a role does not exist, or is a KeyRole.
+func (mariadb *MariaDB) checkRoleExists(tx *sql.Tx, role string) error {
+	row := tx.Stmt(mariadb.qm.GetRoleIDIfExists).QueryRow(role)
+	err := row.Scan(&role)
+	if err == sql.ErrNoRows {
+		return database.ErrRoleMissing
+	}
+	return err
+}
+
+// checkKeyExists returns ErrKeyMissing if a key does not exist.
+func (mariadb *MariaDB) checkKeyExists(tx *sql.Tx, keyid string) error {
+	row := tx.Stmt(mariadb.qm.GetKeyIDIfExists).QueryRow(keyid)
+	err := row.Scan(&keyid)
+	if err == sql.ErrNoRows {
+		return database.ErrKeyMissing
+	}
+	return err
+}
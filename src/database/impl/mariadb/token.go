@@ -0,0 +1,80 @@
+package mariadb
+
+import (
+	"database/sql"
+	"errors"
+	"fsrv/src/database"
+	"fsrv/src/database/entities"
+	"fsrv/utils/serde"
+	"time"
+)
+
+func (mariadb *MariaDB) CreateToken(token *entities.Token) error {
+	if token.ID == "" {
+		return errors.New("required feild tokenid not specified")
+	}
+
+	_, err := mariadb.qm.InsTokenData.Exec(token.ID, token.Comment, time.Time(token.ExpiresAt).UnixMilli(), time.Time(token.CreatedAt).UnixMilli())
+	if err != nil {
+		if isDuplicateError(err) {
+			return database.ErrTokenDuplicate
+		}
+		return err
+	}
+	return nil
+}
+
+func (mariadb *MariaDB) GetTokens(pageSize int, offset int) ([]*entities.Token, error) {
+	rows, err := mariadb.qm.GetTokens.Query(pageSize, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []*entities.Token
+	for rows.Next() {
+		var token entities.Token
+		var expireMS, createMS int64
+		err = rows.Scan(&token.ID, &token.Comment, &expireMS, &createMS)
+		if err != nil {
+			return tokens, err
+		}
+		token.ExpiresAt = serde.Time(time.UnixMilli(expireMS))
+		token.CreatedAt = serde.Time(time.UnixMilli(createMS))
+		tokens = append(tokens, &token)
+	}
+	return tokens, rows.Err()
+}
+
+func (mariadb *MariaDB) GetTokenData(tokenID string) (*entities.Token, error) {
+	var token entities.Token
+	var expireMS, createMS int64
+	row := mariadb.qm.GetTokenData.QueryRow(tokenID)
+	err := row.Scan(&token.Comment, &expireMS, &createMS)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, database.ErrTokenMissing
+		}
+		return nil, err
+	}
+
+	token.ID = tokenID
+	token.ExpiresAt = serde.Time(time.UnixMilli(expireMS))
+	token.CreatedAt = serde.Time(time.UnixMilli(createMS))
+	return &token, nil
+}
+
+func (mariadb *MariaDB) DeleteToken(id string) error {
+	res, err := mariadb.qm.DelTokenByID.Exec(id)
+	if err != nil {
+		return err
+	}
+	rowNum, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowNum == 0 {
+		return database.ErrTokenMissing
+	}
+	return nil
+}
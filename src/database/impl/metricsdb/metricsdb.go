@@ -0,0 +1,206 @@
+// Package metricsdb measures the duration of the calls to a database,
+// recording them in metrics.DatabaseDuration by method.
+package metricsdb
+
+import (
+	"fsrv/src/database"
+	"fsrv/src/database/entities"
+	"fsrv/src/metrics"
+	"time"
+)
+
+type MetricsDB struct {
+	db database.DBInterface
+}
+
+// New wraps a database, measuring the duration of its calls. It
+// should wrap the database directly, beneath any cache, so that
+// only the calls which reach the database are measured.
+func New(db database.DBInterface) *MetricsDB {
+	return &MetricsDB{db}
+}
+
+// observe records the duration of a call since start.
+func observe(method string, start time.Time) {
+	metrics.ObserveSince(metrics.DatabaseDuration.With(method), start)
+}
+
+func (m *MetricsDB) Check() error {
+	defer observe("Check", time.Now())
+	return m.db.Check()
+}
+
+func (m *MetricsDB) Close() error {
+	defer observe("Close", time.Now())
+	return m.db.Close()
+}
+
+func (m *MetricsDB) CreateKey(key *entities.Key) error {
+	defer observe("CreateKey", time.Now())
+	return m.db.CreateKey(key)
+}
+
+func (m *MetricsDB) CreateResource(resource *entities.Resource) error {
+	defer observe("CreateResource", time.Now())
+	return m.db.CreateResource(resource)
+}
+
+func (m *MetricsDB) CreateRole(role *entities.Role) error {
+	defer observe("CreateRole", time.Now())
+	return m.db.CreateRole(role)
+}
+
+func (m *MetricsDB) CreateRateLimit(limit *entities.RateLimit) error {
+	defer observe("CreateRateLimit", time.Now())
+	return m.db.CreateRateLimit(limit)
+}
+
+func (m *MetricsDB) CreateToken(token *entities.Token) error {
+	defer observe("CreateToken", time.Now())
+	return m.db.CreateToken(token)
+}
+
+func (m *MetricsDB) GetKeys(pageSize int, offset int) ([]*entities.Key, error) {
+	defer observe("GetKeys", time.Now())
+	return m.db.GetKeys(pageSize, offset)
+}
+
+func (m *MetricsDB) GetKeyIDs(pageSize int, offset int) ([]string, error) {
+	defer observe("GetKeyIDs", time.Now())
+	return m.db.GetKeyIDs(pageSize, offset)
+}
+
+func (m *MetricsDB) GetKeyData(keyID string) (*entities.Key, error) {
+	defer observe("GetKeyData", time.Now())
+	return m.db.GetKeyData(keyID)
+}
+
+func (m *MetricsDB) GetResources(pageSize int, offset int) ([]*entities.Resource, error) {
+	defer observe("GetResources", time.Now())
+	return m.db.GetResources(pageSize, offset)
+}
+
+func (m *MetricsDB) GetResourceIDs(pageSize int, offset int) ([]string, error) {
+	defer observe("GetResourceIDs", time.Now())
+	return m.db.GetResourceIDs(pageSize, offset)
+}
+
+func (m *MetricsDB) GetResourceData(resourceID string) (*entities.Resource, error) {
+	defer observe("GetResourceData", time.Now())
+	return m.db.GetResourceData(resourceID)
+}
+
+func (m *MetricsDB) GetRoles(pageSize int, offset int) ([]string, error) {
+	defer observe("GetRoles", time.Now())
+	return m.db.GetRoles(pageSize, offset)
+}
+
+func (m *MetricsDB) GetRoleData(roleID string) (*entities.Role, error) {
+	defer observe("GetRoleData", time.Now())
+	return m.db.GetRoleData(roleID)
+}
+
+func (m *MetricsDB) GetTokens(pageSize int, offset int) ([]*entities.Token, error) {
+	defer observe("GetTokens", time.Now())
+	return m.db.GetTokens(pageSize, offset)
+}
+
+func (m *MetricsDB) GetTokenData(tokenID string) (*entities.Token, error) {
+	defer observe("GetTokenData", time.Now())
+	return m.db.GetTokenData(tokenID)
+}
+
+func (m *MetricsDB) UpdateKey(key *entities.Key) error {
+	defer observe("UpdateKey", time.Now())
+	return m.db.UpdateKey(key)
+}
+
+func (m *MetricsDB) GiveRole(keyID string, role ...string) error {
+	defer observe("GiveRole", time.Now())
+	return m.db.GiveRole(keyID, role...)
+}
+
+func (m *MetricsDB) TakeRole(keyID string, role ...string) error {
+	defer observe("TakeRole", time.Now())
+	return m.db.TakeRole(keyID, role...)
+}
+
+func (m *MetricsDB) GrantPermission(permission *entities.Permission, role ...string) error {
+	defer observe("GrantPermission", time.Now())
+	return m.db.GrantPermission(permission, role...)
+}
+
+func (m *MetricsDB) RevokePermission(permission *entities.Permission, role ...string) error {
+	defer observe("RevokePermission", time.Now())
+	return m.db.RevokePermission(permission, role...)
+}
+
+func (m *MetricsDB) SetRateLimit(key *entities.Key, limitID string) error {
+	defer observe("SetRateLimit", time.Now())
+	return m.db.SetRateLimit(key, limitID)
+}
+
+func (m *MetricsDB) GetRateLimitData(rateLimitID string) (*entities.RateLimit, error) {
+	defer observe("GetRateLimitData", time.Now())
+	return m.db.GetRateLimitData(rateLimitID)
+}
+
+func (m *MetricsDB) GetKeyRateLimitID(keyID string) (string, error) {
+	defer observe("GetKeyRateLimitID", time.Now())
+	return m.db.GetKeyRateLimitID(keyID)
+}
+
+func (m *MetricsDB) UpdateRateLimit(rateLimitID string, rateLimit *entities.RateLimit) error {
+	defer observe("UpdateRateLimit", time.Now())
+	return m.db.UpdateRateLimit(rateLimitID, rateLimit)
+}
+
+func (m *MetricsDB) DeleteRateLimit(rateLimitID string) error {
+	defer observe("DeleteRateLimit", time.Now())
+	return m.db.DeleteRateLimit(rateLimitID)
+}
+
+func (m *MetricsDB) SetResourcePath(path string, resourceID string) error {
+	defer observe("SetResourcePath", time.Now())
+	return m.db.SetResourcePath(path, resourceID)
+}
+
+func (m *MetricsDB) GetPathResourceID(path string) (string, error) {
+	defer observe("GetPathResourceID", time.Now())
+	return m.db.GetPathResourceID(path)
+}
+
+func (m *MetricsDB) MoveResourcePaths(oldPath string, newPath string) error {
+	defer observe("MoveResourcePaths", time.Now())
+	return m.db.MoveResourcePaths(oldPath, newPath)
+}
+
+func (m *MetricsDB) DeleteResourcePath(path string) error {
+	defer observe("DeleteResourcePath", time.Now())
+	return m.db.DeleteResourcePath(path)
+}
+
+func (m *MetricsDB) DeleteResourcePaths(path string) error {
+	defer observe("DeleteResourcePaths", time.Now())
+	return m.db.DeleteResourcePaths(path)
+}
+
+func (m *MetricsDB) DeleteRole(name string) error {
+	defer observe("DeleteRole", time.Now())
+	return m.db.DeleteRole(name)
+}
+
+func (m *MetricsDB) DeleteKey(id string) error {
+	defer observe("DeleteKey", time.Now())
+	return m.db.DeleteKey(id)
+}
+
+func (m *MetricsDB) DeleteResource(id string) error {
+	defer observe("DeleteResource", time.Now())
+	return m.db.DeleteResource(id)
+}
+
+func (m *MetricsDB) DeleteToken(id string) error {
+	defer observe("DeleteToken", time.Now())
+	return m.db.DeleteToken(id)
+}
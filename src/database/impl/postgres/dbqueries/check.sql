@@ -0,0 +1 @@
+SELECT table_name FROM information_schema.tables WHERE table_schema = current_schema();
\ No newline at end of file
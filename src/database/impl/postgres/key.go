@@ -0,0 +1,237 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fsrv/src/database"
+	"fsrv/src/database/entities"
+	"time"
+)
+
+func (postgres *PostgresDB) CreateKey(key *entities.Key) error {
+	if key.ID == "" {
+		return errors.New("required feild keyid not specified")
+	}
+
+	//begin transaction
+	tx, err := postgres.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	//create key record
+	stmt := tx.Stmt(postgres.qm.InsKeyData)
+	_, err = stmt.Exec(key.ID, key.Comment, key.RateLimitID, time.Time(key.ExpiresAt), time.Time(key.CreatedAt))
+	if err != nil {
+		if isDuplicateError(err) {
+			err = database.ErrKeyDuplicate
+		}
+		return rollback(tx, err)
+	}
+
+	//add Roles
+	var roleid string
+	stmtGetRoleID := tx.Stmt(postgres.qm.GetRoleIDIfExists)
+	stmtInsKRIData := tx.Stmt(postgres.qm.InsKeyRoleIntersectData)
+	for _, role := range key.Roles {
+		//check if key role exists
+		row := stmtGetRoleID.QueryRow(role)
+		err = row.Scan(&roleid) //produces sql.ErrNoRows if role does not exist
+		if err != nil {
+			if err == sql.ErrNoRows {
+				err = database.ErrRoleMissing
+			}
+			return rollback(tx, err)
+		}
+
+		//insert Role into KeyRoleIntersect //todo:make function
+		_, err = stmtInsKRIData.Exec(key.ID, roleid)
+		if err != nil {
+			return rollback(tx, err)
+		}
+	}
+
+	//insert KeyRole into roles //todo: ensure that precedence ordering is consistent
+	stmt = tx.Stmt(postgres.qm.InsRoleData)
+	_, err = stmt.Exec(key.ID, 1, 10000)
+	if err != nil {
+		return rollback(tx, err)
+	}
+	//Insert KeyRole into KeyRoleIntersect
+	stmt = tx.Stmt(postgres.qm.InsKeyRoleIntersectData)
+	_, err = stmt.Exec(key.ID, key.ID)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	//commit transaction results
+	return tx.Commit()
+}
+
+func (postgres *PostgresDB) UpdateKey(key *entities.Key) error {
+	res, err := postgres.qm.UpdKeyData.Exec(key.Comment, key.RateLimitID, time.Time(key.ExpiresAt), key.ID)
+	if err != nil {
+		return err
+	}
+	rowNum, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowNum == 0 {
+		return database.ErrKeyMissing
+	}
+	return nil
+}
+
+func (postgres *PostgresDB) DeleteKey(id string) error {
+	//begin transaction
+	tx, err := postgres.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	//delete key record
+	stmt := tx.Stmt(postgres.qm.DelKeyByID)
+	res, err := stmt.Exec(id)
+	if err != nil {
+		return rollback(tx, err)
+	}
+	rowNum, err := res.RowsAffected()
+	if err != nil {
+		return rollback(tx, err)
+	}
+	if rowNum == 0 {
+		return rollback(tx, database.ErrKeyMissing)
+	}
+
+	//delete role assignments, including the KeyRole
+	stmt = tx.Stmt(postgres.qm.DelKRIEntriesByKeyID)
+	_, err = stmt.Exec(id)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	//delete KeyRole and its permissions
+	stmt = tx.Stmt(postgres.qm.DelRPIEntryByRoleID)
+	_, err = stmt.Exec(id)
+	if err != nil {
+		return rollback(tx, err)
+	}
+	stmt = tx.Stmt(postgres.qm.DelRoleByID)
+	_, err = stmt.Exec(id)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	//commit
+	return tx.Commit()
+}
+
+func (postgres *PostgresDB) GetKeys(pageSize int, offset int) ([]*entities.Key, error) {
+	keyIDs, err := postgres.GetKeyIDs(pageSize, offset)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]*entities.Key, len(keyIDs))
+	for i, keyID := range keyIDs {
+		keys[i], err = postgres.GetKeyData(keyID)
+		if err != nil {
+			return keys[:i], err
+		}
+	}
+	return keys, nil
+}
+
+func (postgres *PostgresDB) GetKeyIDs(pageSize int, offset int) ([]string, error) {
+	//todo: determine if this is okay to do outside of a transaction
+	var keyIDs []string
+	var id string
+	rows, err := postgres.qm.GetKeyIDs.Query(pageSize, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		err = rows.Scan(&id)
+		if err != nil {
+			return keyIDs, err
+		}
+		keyIDs = append(keyIDs, id)
+	}
+
+	return keyIDs, nil
+}
+
+func (postgres *PostgresDB) GetKeyData(keyid string) (*entities.Key, error) {
+	var key entities.Key
+	var rtlimID sql.NullString
+	var created, expires time.Time
+
+	//begin transaction
+	tx, err := postgres.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	//get base key data
+	stmtGetBaseData := tx.Stmt(postgres.qm.GetKeyData)
+	row := stmtGetBaseData.QueryRow(keyid)
+	err = row.Scan(&key.Comment, &rtlimID, &created, &expires)
+	if err != nil {
+		_ = tx.Rollback()
+		if err == sql.ErrNoRows {
+			return nil, database.ErrKeyMissing
+		}
+		return nil, err
+	}
+
+	//get Roles
+	stmtGetRoles := tx.Stmt(postgres.qm.GetRolesByKeyIDOrdered)
+	rows, err := stmtGetRoles.Query(keyid)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+	var role string
+	for rows.Next() {
+		err = rows.Scan(&role)
+		if err != nil {
+			_ = tx.Rollback()
+			return nil, err
+		}
+		key.Roles = append(key.Roles, role)
+	}
+
+	//finish building key
+	key.ID = keyid
+	key.RateLimitID = rtlimID.String
+	key.CreatedAt = fromTimestamp(created)
+	key.ExpiresAt = fromTimestamp(expires)
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (postgres *PostgresDB) GetKeyRateLimitID(keyID string) (string, error) {
+	var rateLimitID sql.NullString
+	row := postgres.qm.GetKeyRateLimitID.QueryRow(keyID)
+	err := row.Scan(&rateLimitID)
+	if err != nil {
+		row = postgres.qm.GetKeyIDIfExists.QueryRow(keyID)
+		err = row.Scan(&keyID)
+		if err == sql.ErrNoRows {
+			return keyID, database.ErrKeyMissing
+		} else {
+			return "", err
+		}
+	}
+	if rateLimitID.Valid {
+		return rateLimitID.String, nil
+	} else {
+		return "", err
+	}
+}
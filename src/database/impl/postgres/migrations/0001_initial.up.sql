@@ -0,0 +1,95 @@
+-- identifiers and paths use the "C" collation, so that they are ordered
+-- byte for byte, as in sqlite. references between tables are not enforced,
+-- as in sqlite, since permissions may be granted to roles which do not exist yet.
+DO
+$$
+    BEGIN
+        CREATE TYPE OperationType AS ENUM ('read', 'write', 'modify', 'delete');
+    EXCEPTION
+        WHEN duplicate_object THEN NULL;
+    END
+$$;
+
+
+
+CREATE TABLE IF NOT EXISTS Ratelimits
+(
+    ratelimitid TEXT COLLATE "C" PRIMARY KEY,
+    requests    BIGINT NOT NULL, -- number of requests in a given period
+    burst       BIGINT NOT NULL, -- number of requests allowed in a short burst
+    reset       BIGINT NOT NULL  -- timestamp for reset (millis)
+);
+
+
+
+CREATE TABLE IF NOT EXISTS Keys
+(
+    keyid       TEXT COLLATE "C" PRIMARY KEY,
+    note        TEXT,
+    ratelimitid TEXT COLLATE "C",
+    expires     TIMESTAMPTZ NOT NULL,
+    created     TIMESTAMPTZ NOT NULL
+);
+
+
+
+CREATE TABLE IF NOT EXISTS Resources
+(
+    resourceid TEXT COLLATE "C" PRIMARY KEY,
+    flags      SMALLINT NOT NULL
+);
+
+
+CREATE TABLE IF NOT EXISTS Roles
+(
+    roleid         TEXT COLLATE "C" PRIMARY KEY,
+    rolePrecedence INTEGER  NOT NULL DEFAULT 0, -- note, roleid is tiebreaker
+    roleTypeRK     SMALLINT NOT NULL            -- 0=role 1=keyrole
+);
+CREATE INDEX IF NOT EXISTS RolesByPrecedence ON Roles (rolePrecedence); -- for ordering
+CREATE INDEX IF NOT EXISTS RolesByRoleType ON Roles (roleTypeRK); -- for lookups
+
+
+CREATE TABLE IF NOT EXISTS Permissions
+(
+    permissionid      BIGSERIAL PRIMARY KEY,           -- file / dir permission id
+    resourceid        TEXT COLLATE "C" NOT NULL,
+    permTypeRWMD      OperationType    NOT NULL,
+    permTypeDenyAllow BOOLEAN          NOT NULL        -- false=deny true=allow
+);
+CREATE INDEX IF NOT EXISTS PermissionsPerResource ON Permissions (resourceid);
+
+
+
+CREATE TABLE IF NOT EXISTS KeyRoleIntersect
+(
+    keyid  TEXT COLLATE "C",
+    roleid TEXT COLLATE "C"
+);
+CREATE INDEX IF NOT EXISTS RolesPerKey ON KeyRoleIntersect (keyid); -- create index for most commonly accessed direction
+
+
+CREATE TABLE IF NOT EXISTS RolePermIntersect
+(
+    roleid       TEXT COLLATE "C",
+    permissionid BIGINT
+);
+CREATE INDEX IF NOT EXISTS PermissionsPerRole ON RolePermIntersect (roleid);
+
+
+CREATE TABLE IF NOT EXISTS ResourcePaths
+(
+    path       TEXT COLLATE "C" PRIMARY KEY, -- slash separated, relative to the file manager path
+    resourceid TEXT COLLATE "C" NOT NULL
+);
+CREATE INDEX IF NOT EXISTS ResourcePathsByResource ON ResourcePaths (resourceid);
+
+
+
+CREATE TABLE IF NOT EXISTS Tokens
+(
+    tokenid TEXT COLLATE "C" PRIMARY KEY, -- hash of the token
+    note    TEXT,
+    expires TIMESTAMPTZ NOT NULL,
+    created TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,2 @@
+DROP INDEX KeysPerRole;
+DROP INDEX RolesPerPermission;
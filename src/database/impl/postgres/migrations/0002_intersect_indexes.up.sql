@@ -0,0 +1,3 @@
+-- index the other direction of the intersect tables, for the resource roles query and DeleteRole
+CREATE INDEX KeysPerRole ON KeyRoleIntersect (roleid);
+CREATE INDEX RolesPerPermission ON RolePermIntersect (permissionid);
@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"fsrv/src/database"
+	"fsrv/src/database/entities"
+	"fsrv/utils/serde"
+	"time"
+)
+
+func (postgres *PostgresDB) CreateRateLimit(limit *entities.RateLimit) error {
+	reset := time.Duration(limit.Refill).Milliseconds()
+	_, err := postgres.qm.InsRateLimitData.Exec(limit.ID, limit.Limit, limit.Burst, reset)
+	if isDuplicateError(err) {
+		return database.ErrRateLimitDuplicate
+	}
+	return err
+}
+
+func (postgres *PostgresDB) DeleteRateLimit(rateLimitID string) error {
+	return postgres.deleteObjByID(postgres.qm.DelRateLimitByID, rateLimitID)
+}
+
+func (postgres *PostgresDB) SetRateLimit(key *entities.Key, limitID string) error {
+	tx, err := postgres.db.Begin()
+	if err != nil {
+		return err
+	}
+	stmt := tx.Stmt(postgres.qm.UpdKeyRateLimitID)
+	_, err = stmt.Exec(limitID, key.ID)
+	if err != nil {
+		return rollback(tx, err)
+	}
+	return tx.Commit()
+}
+
+func (postgres *PostgresDB) GetRateLimitData(ratelimitid string) (*entities.RateLimit, error) {
+	row := postgres.qm.GetRateLimitDataByID.QueryRow(ratelimitid)
+	var rateLimit entities.RateLimit
+	var reset int64
+	err := row.Scan(&rateLimit.Limit, &rateLimit.Burst, &reset)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, database.ErrRateLimitMissing
+		}
+		return nil, err
+	}
+	rateLimit.ID = ratelimitid
+	rateLimit.Refill = serde.Duration(reset * int64(time.Millisecond))
+
+	return &rateLimit, nil
+}
+
+func (postgres *PostgresDB) UpdateRateLimit(rateLimitID string, rateLimit *entities.RateLimit) error {
+	tx, err := postgres.db.Begin()
+	if err != nil {
+		return err
+	}
+	stmt := tx.Stmt(postgres.qm.UpdRateLimitData)
+	reset := time.Duration(rateLimit.Refill).Milliseconds()
+	res, err := stmt.Exec(rateLimit.ID, rateLimit.Limit, rateLimit.Burst, reset, rateLimitID)
+	if err != nil {
+		if isDuplicateError(err) {
+			err = database.ErrRateLimitDuplicate
+		}
+		return rollback(tx, err)
+	}
+	rowNum, err := res.RowsAffected()
+	if err != nil {
+		return rollback(tx, err)
+	}
+	if rowNum == 0 {
+		return rollback(tx, database.ErrRateLimitMissing)
+	}
+	if rowNum != 1 {
+		return rollback(tx, errors.New(fmt.Sprintf("Failed to update rateLimit %d rows affected", rowNum)))
+	}
+
+	//keys using the rate limit follow it when it is renamed
+	if rateLimit.ID != rateLimitID {
+		_, err = tx.Stmt(postgres.qm.UpdKeysRateLimitID).Exec(rateLimit.ID, rateLimitID)
+		if err != nil {
+			return rollback(tx, err)
+		}
+	}
+	return tx.Commit()
+}
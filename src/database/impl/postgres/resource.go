@@ -0,0 +1,172 @@
+package postgres
+
+import (
+	"database/sql"
+	"fsrv/src/database"
+	"fsrv/src/database/entities"
+	"io"
+)
+
+func (postgres *PostgresDB) CreateResource(resource *entities.Resource) error {
+	if resource.ID == "" {
+		return database.ErrResourceNameBad
+	}
+
+	//begin transaction
+	tx, err := postgres.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	//insert resource with flags
+	stmt := tx.Stmt(postgres.qm.InsResourceData)
+	_, err = stmt.Exec(resource.ID, resource.Flags)
+	if err != nil {
+		if isDuplicateError(err) {
+			err = database.ErrResourceDuplicate
+		}
+		return rollback(tx, err)
+	}
+
+	//insert permissions
+	err = postgres.createResourcePermissions(tx, resource)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	//commit transaction results
+	return tx.Commit()
+}
+
+func (postgres *PostgresDB) DeleteResource(id string) error {
+	//begin transaction
+	tx, err := postgres.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	//delete associated RolePermIntersect entries
+	stmt := tx.Stmt(postgres.qm.DelRPIEntriesByResourceID)
+	_, err = stmt.Exec(id)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	//delete associated permissions
+	stmt = tx.Stmt(postgres.qm.DelPermissionByResourceID)
+	_, err = stmt.Exec(id)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	//detach from paths
+	stmt = tx.Stmt(postgres.qm.DelResourcePathByResourceID)
+	_, err = stmt.Exec(id)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	//delete underlying resource
+	stmt = tx.Stmt(postgres.qm.DelResourceByID)
+	res, err := stmt.Exec(id)
+	if err != nil {
+		return rollback(tx, err)
+	}
+	rowsDeleted, err := res.RowsAffected()
+	if err != nil {
+		return rollback(tx, err)
+	}
+	if rowsDeleted == 0 {
+		return rollback(tx, database.ErrResourceMissing)
+	}
+
+	//commit
+	return tx.Commit()
+}
+
+func (postgres *PostgresDB) GetResources(pageSize int, offset int) ([]*entities.Resource, error) {
+	resourceIDs, err := postgres.GetResourceIDs(pageSize, offset)
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]*entities.Resource, len(resourceIDs))
+
+	for i, id := range resourceIDs {
+		resources[i], err = postgres.GetResourceData(id)
+		if err != nil {
+			return resources[:i], err
+		}
+	}
+
+	return resources, nil
+}
+
+func (postgres *PostgresDB) GetResourceIDs(pageSize int, offset int) ([]string, error) {
+	var resourceIDs []string
+	var id string
+	rows, err := postgres.qm.GetResourceIDs.Query(pageSize, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		err = rows.Scan(&id)
+		if err != nil {
+			return resourceIDs, err
+		}
+		resourceIDs = append(resourceIDs, id)
+	}
+
+	return resourceIDs, rows.Err()
+}
+
+func (postgres *PostgresDB) GetResourceData(resourceid string) (*entities.Resource, error) {
+	//begin transaction
+	tx, err := postgres.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	res := entities.Resource{
+		ID:             resourceid,
+		OperationNodes: make(map[entities.ResourceOperationAccess]bool),
+	}
+
+	//get flags
+	stmt := tx.Stmt(postgres.qm.GetResourceFlagsByID)
+	row := stmt.QueryRow(resourceid)
+
+	err = row.Scan(&res.Flags)
+	if err != nil {
+		_ = tx.Rollback()
+		if err == sql.ErrNoRows {
+			return nil, database.ErrResourceMissing
+		}
+		return nil, err
+	}
+
+	//get permission iterator
+	iter, roleperm, err := postgres.getResourceRolePermIter(tx, resourceid)
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	//get permissions
+	for err = iter(); err == nil; err = iter() {
+		key := entities.ResourceOperationAccess{
+			ID:   roleperm.Role.ID,
+			Type: roleperm.Perm.TypeRWMD,
+		}
+		res.OperationNodes[key] = roleperm.Perm.Status
+	}
+	if err != io.EOF {
+		_ = tx.Rollback()
+		return nil, err
+	}
+
+	_ = tx.Commit()
+
+	return &res, nil
+}
@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"database/sql"
+	"fsrv/src/database"
+	"strings"
+	"unicode/utf8"
+)
+
+func (postgres *PostgresDB) SetResourcePath(path string, resourceID string) error {
+	_, err := postgres.qm.InsResourcePath.Exec(path, resourceID)
+	return err
+}
+
+func (postgres *PostgresDB) GetPathResourceID(path string) (string, error) {
+	var resourceID string
+	row := postgres.qm.GetPathResourceID.QueryRow(path)
+	err := row.Scan(&resourceID)
+	if err == sql.ErrNoRows {
+		return "", database.ErrResourcePathMissing
+	}
+	return resourceID, err
+}
+
+func (postgres *PostgresDB) MoveResourcePaths(oldPath string, newPath string) error {
+	if oldPath == newPath {
+		return nil
+	}
+	prefix := descendantPrefix(oldPath)
+	// substr counts characters, and is indexed from 1
+	n := utf8.RuneCountInString(oldPath)
+	_, err := postgres.qm.UpdResourcePathPrefix.Exec(oldPath, utf8.RuneCountInString(prefix), prefix, newPath, n+1)
+	return err
+}
+
+func (postgres *PostgresDB) DeleteResourcePath(path string) error {
+	_, err := postgres.qm.DelResourcePath.Exec(path)
+	return err
+}
+
+func (postgres *PostgresDB) DeleteResourcePaths(path string) error {
+	prefix := descendantPrefix(path)
+	_, err := postgres.qm.DelResourcePathPrefix.Exec(path, utf8.RuneCountInString(prefix), prefix)
+	return err
+}
+
+// descendantPrefix returns the prefix shared by the paths of all descendants of a path.
+func descendantPrefix(path string) string {
+	return strings.TrimSuffix(path, "/") + "/"
+}
@@ -0,0 +1,181 @@
+package postgres
+
+import (
+	"database/sql"
+	"fsrv/src/database"
+	"fsrv/src/database/entities"
+)
+
+func (postgres *PostgresDB) CreateRole(role *entities.Role) error {
+	if role.ID == "" || role.ID == "*" {
+		return database.ErrRoleNameBad
+	}
+
+	tx, err := postgres.db.Begin()
+	if err != nil {
+		return err
+	}
+	stmt := tx.Stmt(postgres.qm.InsRoleData)
+	res, err := stmt.Exec(role.ID, 0, role.Precedence)
+	if err != nil {
+		if isDuplicateError(err) {
+			err = database.ErrRoleDuplicate
+		}
+		return rollback(tx, err)
+	}
+	rowsInserted, err := res.RowsAffected()
+	if err != nil {
+		return rollback(tx, err)
+	}
+	if rowsInserted == 0 {
+		return rollback(tx, sql.ErrNoRows)
+	}
+	return tx.Commit()
+}
+
+func (postgres *PostgresDB) DeleteRole(name string) error {
+	//begin transaction
+	tx, err := postgres.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	//ensure the role exists, and is not a KeyRole
+	err = postgres.checkRoleExists(tx, name)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	//delete associated RolePermIntersect entries
+	stmt := tx.Stmt(postgres.qm.DelRPIEntryByRoleID)
+	_, err = stmt.Exec(name)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	//delete associated KeyRoleIntersect entries
+	stmt = tx.Stmt(postgres.qm.DelKRIEntriesByRoleID)
+	_, err = stmt.Exec(name)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	//delete underlying role
+	stmt = tx.Stmt(postgres.qm.DelRoleByID)
+	_, err = stmt.Exec(name)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	//commit
+	return tx.Commit()
+}
+
+func (postgres *PostgresDB) GetRoles(pageSize int, offset int) ([]string, error) {
+	var role string
+	var roles []string
+	rows, err := postgres.qm.GetRoleIDs.Query(pageSize, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		err = rows.Scan(&role)
+		if err != nil {
+			return roles, err
+		}
+		roles = append(roles, role)
+	}
+
+	return roles, rows.Err()
+}
+
+func (postgres *PostgresDB) GetRoleData(roleID string) (*entities.Role, error) {
+	role := entities.Role{ID: roleID}
+	row := postgres.qm.GetRoleData.QueryRow(roleID)
+	err := row.Scan(&role.Precedence)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, database.ErrRoleMissing
+		}
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (postgres *PostgresDB) GiveRole(keyid string, roles ...string) error {
+	//begin transaction
+	tx, err := postgres.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	err = postgres.checkKeyExists(tx, keyid)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	stmtIns := tx.Stmt(postgres.qm.InsKeyRoleIntersectIfMissing)
+	for _, role := range roles {
+		err = postgres.checkRoleExists(tx, role)
+		if err != nil {
+			return rollback(tx, err)
+		}
+
+		_, err = stmtIns.Exec(keyid, role)
+		if err != nil {
+			return rollback(tx, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (postgres *PostgresDB) TakeRole(keyid string, roles ...string) error {
+	//begin transaction
+	tx, err := postgres.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	err = postgres.checkKeyExists(tx, keyid)
+	if err != nil {
+		return rollback(tx, err)
+	}
+
+	stmtDel := tx.Stmt(postgres.qm.DelKRIEntry)
+	for _, role := range roles {
+		if role == keyid {
+			//the KeyRole cannot be taken from its key
+			return rollback(tx, database.ErrRoleNameBad)
+		}
+
+		_, err = stmtDel.Exec(keyid, role)
+		if err != nil {
+			return rollback(tx, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// checkRoleExists returns ErrRoleMissing if a role does not exist, or is a KeyRole.
+func (postgres *PostgresDB) checkRoleExists(tx *sql.Tx, role string) error {
+	row := tx.Stmt(postgres.qm.GetRoleIDIfExists).QueryRow(role)
+	err := row.Scan(&role)
+	if err == sql.ErrNoRows {
+		return database.ErrRoleMissing
+	}
+	return err
+}
+
+// checkKeyExists returns ErrKeyMissing if a key does not exist.
+func (postgres *PostgresDB) checkKeyExists(tx *sql.Tx, keyid string) error {
+	row := tx.Stmt(postgres.qm.GetKeyIDIfExists).QueryRow(keyid)
+	err := row.Scan(&keyid)
+	if err == sql.ErrNoRows {
+		return database.ErrKeyMissing
+	}
+	return err
+}
@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fsrv/src/database"
+	"fsrv/src/database/entities"
+	"time"
+)
+
+func (postgres *PostgresDB) CreateToken(token *entities.Token) error {
+	if token.ID == "" {
+		return errors.New("required feild tokenid not specified")
+	}
+
+	_, err := postgres.qm.InsTokenData.Exec(token.ID, token.Comment, time.Time(token.ExpiresAt), time.Time(token.CreatedAt))
+	if err != nil {
+		if isDuplicateError(err) {
+			return database.ErrTokenDuplicate
+		}
+		return err
+	}
+	return nil
+}
+
+func (postgres *PostgresDB) GetTokens(pageSize int, offset int) ([]*entities.Token, error) {
+	rows, err := postgres.qm.GetTokens.Query(pageSize, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []*entities.Token
+	for rows.Next() {
+		var token entities.Token
+		var expires, created time.Time
+		err = rows.Scan(&token.ID, &token.Comment, &expires, &created)
+		if err != nil {
+			return tokens, err
+		}
+		token.ExpiresAt = fromTimestamp(expires)
+		token.CreatedAt = fromTimestamp(created)
+		tokens = append(tokens, &token)
+	}
+	return tokens, rows.Err()
+}
+
+func (postgres *PostgresDB) GetTokenData(tokenID string) (*entities.Token, error) {
+	var token entities.Token
+	var expires, created time.Time
+	row := postgres.qm.GetTokenData.QueryRow(tokenID)
+	err := row.Scan(&token.Comment, &expires, &created)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, database.ErrTokenMissing
+		}
+		return nil, err
+	}
+
+	token.ID = tokenID
+	token.ExpiresAt = fromTimestamp(expires)
+	token.CreatedAt = fromTimestamp(created)
+	return &token, nil
+}
+
+func (postgres *PostgresDB) DeleteToken(id string) error {
+	res, err := postgres.qm.DelTokenByID.Exec(id)
+	if err != nil {
+		return err
+	}
+	rowNum, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowNum == 0 {
+		return database.ErrTokenMissing
+	}
+	return nil
+}
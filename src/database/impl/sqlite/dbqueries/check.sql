@@ -1 +1 @@
-SELECT name FROM sqlite_master WHERE type='table' AND name='table_name';
\ No newline at end of file
+SELECT name FROM sqlite_master WHERE type='table';
\ No newline at end of file
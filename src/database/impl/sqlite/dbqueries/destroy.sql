@@ -5,6 +5,9 @@ DROP TABLE IF EXISTS Resources;
 DROP TABLE IF EXISTS Roles;
 DROP TABLE IF EXISTS KeyRoleIntersect;
 DROP TABLE IF EXISTS RolePermIntersect;
+DROP TABLE IF EXISTS ResourcePaths;
+DROP TABLE IF EXISTS Tokens;
+DROP TABLE IF EXISTS schema_version;
 
 DROP INDEX IF EXISTS RolesByID;
 DROP INDEX IF EXISTS RolesByPrecedence;
@@ -12,4 +15,7 @@ DROP INDEX IF EXISTS PermissionsPerResource;
 DROP INDEX IF EXISTS RolesPerKey;
 DROP INDEX IF EXISTS PermissionsPerRole;
 DROP INDEX IF EXISTS KeysPerPermission;
-DROP INDEX IF EXISTS RolesByRoleType;
\ No newline at end of file
+DROP INDEX IF EXISTS RolesByRoleType;
+DROP INDEX IF EXISTS ResourcePathsByResource;
+DROP INDEX IF EXISTS KeysPerRole;
+DROP INDEX IF EXISTS RolesPerPermission;
\ No newline at end of file
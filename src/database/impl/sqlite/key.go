@@ -6,7 +6,6 @@ import (
 	"fsrv/src/database"
 	"fsrv/src/database/entities"
 	"fsrv/utils/serde"
-	"github.com/mattn/go-sqlite3"
 	"time"
 )
 
@@ -26,7 +25,7 @@ func (sqlite *SQLiteDB) CreateKey(key *entities.Key) error {
 	_, err = stmt.Exec(key.ID, key.Comment, key.RateLimitID, time.Time(key.ExpiresAt).UnixMilli(), time.Time(key.CreatedAt).UnixMilli())
 	if err != nil {
 		rollbackOrPanic(tx)
-		if err == sqlite3.ErrConstraintUnique {
+		if isDuplicateError(err) {
 			return database.ErrKeyDuplicate
 		}
 		return err
@@ -42,6 +41,9 @@ func (sqlite *SQLiteDB) CreateKey(key *entities.Key) error {
 		err = row.Scan(&roleid) //produces sql.ErrNoRows if role does not exist
 		if err != nil {
 			rollbackOrPanic(tx)
+			if err == sql.ErrNoRows {
+				return database.ErrRoleMissing
+			}
 			return err
 		}
 
@@ -74,20 +76,82 @@ func (sqlite *SQLiteDB) CreateKey(key *entities.Key) error {
 	return nil
 }
 
+func (sqlite *SQLiteDB) UpdateKey(key *entities.Key) error {
+	res, err := sqlite.qm.UpdKeyData.Exec(key.Comment, key.RateLimitID, time.Time(key.ExpiresAt).UnixMilli(), key.ID)
+	if err != nil {
+		return err
+	}
+	rowNum, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowNum == 0 {
+		return database.ErrKeyMissing
+	}
+	return nil
+}
+
 func (sqlite *SQLiteDB) DeleteKey(id string) error {
-	return sqlite.deleteObjByID(sqlite.qm.DelKeyByID, id)
+	//begin transaction
+	tx, err := sqlite.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	//delete key record
+	stmt := tx.Stmt(sqlite.qm.DelKeyByID)
+	res, err := stmt.Exec(id)
+	if err != nil {
+		rollbackOrPanic(tx)
+		return err
+	}
+	rowNum, err := res.RowsAffected()
+	if err != nil {
+		rollbackOrPanic(tx)
+		return err
+	}
+	if rowNum == 0 {
+		rollbackOrPanic(tx)
+		return database.ErrKeyMissing
+	}
+
+	//delete role assignments, including the KeyRole
+	stmt = tx.Stmt(sqlite.qm.DelKRIEntriesByKeyID)
+	_, err = stmt.Exec(id)
+	if err != nil {
+		rollbackOrPanic(tx)
+		return err
+	}
+
+	//delete KeyRole and its permissions
+	stmt = tx.Stmt(sqlite.qm.DelRPIEntryByRoleID)
+	_, err = stmt.Exec(id)
+	if err != nil {
+		rollbackOrPanic(tx)
+		return err
+	}
+	stmt = tx.Stmt(sqlite.qm.DelRoleByID)
+	_, err = stmt.Exec(id)
+	if err != nil {
+		rollbackOrPanic(tx)
+		return err
+	}
+
+	//commit
+	commitOrPanic(tx)
+	return nil
 }
 
 func (sqlite *SQLiteDB) GetKeys(pageSize int, offset int) ([]*entities.Key, error) {
-	var keys []*entities.Key
 	keyIDs, err := sqlite.GetKeyIDs(pageSize, offset)
 	if err != nil {
-		return keys, err
+		return nil, err
 	}
+	keys := make([]*entities.Key, len(keyIDs))
 	for i, keyID := range keyIDs {
 		keys[i], err = sqlite.GetKeyData(keyID)
 		if err != nil {
-			return keys, nil
+			return keys[:i], err
 		}
 	}
 	return keys, nil
@@ -121,7 +185,6 @@ func (sqlite *SQLiteDB) GetKeyData(keyid string) (*entities.Key, error) {
 	//begin transaction
 	tx, err := sqlite.db.Begin()
 	if err != nil {
-		_ = tx.Rollback()
 		return nil, err
 	}
 
@@ -156,6 +219,7 @@ func (sqlite *SQLiteDB) GetKeyData(keyid string) (*entities.Key, error) {
 
 	//finish building key
 	key.ID = keyid
+	key.RateLimitID = rtlimID.String
 	key.CreatedAt = serde.Time(time.UnixMilli(createMS))
 	key.ExpiresAt = serde.Time(time.UnixMilli(expireMS))
 
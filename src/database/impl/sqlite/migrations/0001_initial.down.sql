@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS Ratelimits;
+DROP TABLE IF EXISTS Keys;
+DROP TABLE IF EXISTS Permissions;
+DROP TABLE IF EXISTS Resources;
+DROP TABLE IF EXISTS Roles;
+DROP TABLE IF EXISTS KeyRoleIntersect;
+DROP TABLE IF EXISTS RolePermIntersect;
+DROP TABLE IF EXISTS ResourcePaths;
+DROP TABLE IF EXISTS Tokens;
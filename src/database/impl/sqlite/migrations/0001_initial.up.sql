@@ -0,0 +1,97 @@
+-- the tables are only created if missing, so that databases created
+-- before the schema was versioned are adopted at this version
+CREATE TABLE IF NOT EXISTS Ratelimits
+(
+    ratelimitid TEXT PRIMARY KEY,
+    requests    INTEGER NOT NULL, -- number of requests in a given period
+    burst       INTEGER NOT NULL, -- number of requests allowed in a short burst
+    reset       INTEGER NOT NULL  -- timestamp for reset (unix millis)
+);
+
+
+
+CREATE TABLE IF NOT EXISTS Keys
+(
+    keyid       TEXT PRIMARY KEY,
+    note        TEXT,
+    ratelimitid TEXT,
+    expires     INTEGER NOT NULL, -- unix millis
+    created     INTEGER NOT NULL, -- unix millis
+
+    FOREIGN KEY (ratelimitid) REFERENCES Ratelimits (ratelimitid)
+);
+
+
+
+CREATE TABLE IF NOT EXISTS Resources
+(
+    resourceid TEXT PRIMARY KEY,
+    flags      INTEGER(1) NOT NULL
+);
+CREATE INDEX IF NOT EXISTS ResourcesByID on Resources (resourceid);
+
+
+CREATE TABLE IF NOT EXISTS Roles
+(
+    roleid         TEXT PRIMARY KEY,
+    rolePrecedence INTEGER    NOT NULL DEFAULT 0, -- note, roleid is tiebreaker
+    roleTypeRK     INTEGER(1) NOT NULL            -- 0=role 1=keyrole
+);
+CREATE INDEX IF NOT EXISTS RolesByID ON Roles (roleid); -- for lookups
+CREATE INDEX IF NOT EXISTS RolesByPrecedence ON Roles (rolePrecedence); -- for ordering
+CREATE INDEX IF NOT EXISTS RolesByRoleType ON Roles (roleTypeRK); -- for lookups
+
+
+CREATE TABLE IF NOT EXISTS Permissions
+(
+    permissionid      INTEGER PRIMARY KEY AUTOINCREMENT,             -- file / dir permission id
+    resourceid        TEXT       NOT NULL, -- type of permission granted
+    permTypeRWMD      INTEGER(1) NOT NULL, -- 0=read 1=write
+    permTypeDenyAllow INTEGER(1) NOT NULL, -- -0=deny 1=allow
+
+    FOREIGN KEY (resourceid) REFERENCES Resources (resourceid)
+);
+CREATE INDEX IF NOT EXISTS PermissionsPerResource ON Permissions (resourceid);
+
+
+
+CREATE TABLE IF NOT EXISTS KeyRoleIntersect
+(
+    keyid  TEXT,
+    roleid TEXT,
+
+    FOREIGN KEY (keyid) REFERENCES Keys (keyid),
+    FOREIGN KEY (roleid) REFERENCES Roles (roleid)
+);
+CREATE INDEX IF NOT EXISTS RolesPerKey ON KeyRoleIntersect (keyid); -- create index for most commonly accessed direction
+
+
+CREATE TABLE IF NOT EXISTS RolePermIntersect
+(
+    roleid       TEXT,
+    permissionid INTEGER,
+
+    FOREIGN KEY (roleid) REFERENCES Roles (roleid),
+    FOREIGN KEY (permissionid) REFERENCES Permissions (permissionid)
+);
+CREATE INDEX IF NOT EXISTS PermissionsPerRole ON RolePermIntersect (roleid);
+
+
+CREATE TABLE IF NOT EXISTS ResourcePaths
+(
+    path       TEXT PRIMARY KEY, -- slash separated, relative to the file manager path
+    resourceid TEXT NOT NULL,
+
+    FOREIGN KEY (resourceid) REFERENCES Resources (resourceid)
+);
+CREATE INDEX IF NOT EXISTS ResourcePathsByResource ON ResourcePaths (resourceid);
+
+
+
+CREATE TABLE IF NOT EXISTS Tokens
+(
+    tokenid TEXT PRIMARY KEY, -- hash of the token
+    note    TEXT,
+    expires INTEGER NOT NULL, -- unix millis
+    created INTEGER NOT NULL  -- unix millis
+);
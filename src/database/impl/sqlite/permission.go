@@ -6,62 +6,47 @@ import (
 )
 
 /*
-RevokePermission Removes a permission from the specified roles
--Retrieves a permissionID for the given resourceID, operationType, and denyAllow status
--Removes the specified role(s) from the RolePermIntersect
--Checks if any roles are still associated with that permission node
--Removes the permission node by id if it has no associated roles
+RevokePermission Removes the permission nodes of the specified roles for an operation on a resource
+-Removes the RolePermIntersect entries of each role for the resourceID and operationType, of either denyAllow status
+-Removes the permission nodes of the resource which no longer have any associated roles
 */
 func (sqlite *SQLiteDB) RevokePermission(permission *entities.Permission, roles ...string) error {
-	tx, e := sqlite.db.Begin()
-	if e != nil {
-		return e
-	}
-
-	var permissionID string
-
-	//get permissionID from database
-	row := tx.QueryRow("SELECT permissionid FROM Permissions WHERE resourceid = ? AND permTypeRWMD = ? AND permTypeDenyAllow = ?", permission.ResourceID, permission.TypeRWMD, permission.Status)
-	err := row.Scan(permissionID)
+	//begin transaction
+	tx, err := sqlite.db.Begin()
 	if err != nil {
-		commitOrPanic(tx)
 		return err
 	}
 
 	//delete RolePermIntersect entries
-	params := getNParams("?,", len(roles))
-	_, err = tx.Query("DELETE FROM RolePermIntersect WHERE permissionid = ? AND roleid IN ("+params+")", permissionID, roles)
-	if err != nil {
-		rollbackOrPanic(tx)
-		return err
+	stmt := tx.Stmt(sqlite.qm.DelRPIEntryByRoleAndOperation)
+	for _, role := range roles {
+		_, err = stmt.Exec(role, permission.ResourceID, permission.TypeRWMD)
+		if err != nil {
+			rollbackOrPanic(tx)
+			return err
+		}
 	}
 
-	//count remaining references
-	row = tx.QueryRow("SELECT COUNT(permissionid) FROM RolePermIntersect WHERE permissionid = ?", permissionID)
-	var references int
-	err = row.Scan(references)
+	//delete orphaned permission nodes
+	_, err = tx.Stmt(sqlite.qm.DelOrphanedPermissionsByResourceID).Exec(permission.ResourceID)
 	if err != nil {
 		rollbackOrPanic(tx)
 		return err
 	}
-	if references < 1 { //if no permissions reference the given permission
-		_, err = tx.Query("DELETE FROM Permissions WHERE permissionid = ?", permissionID) //delete orphaned permission node
-		if err != nil {
-			rollbackOrPanic(tx)
-			return err
-		}
-	}
 
 	commitOrPanic(tx)
-
 	return nil
 }
 
+/*
+GrantPermission Allows or denies the specified roles permission to perform an operation on a resource
+-Roles may be roles, KeyRoles (key ids) or the catch-all role "*"
+-Replaces any existing permission node of each role for the same resourceID and operationType
+*/
 func (sqlite *SQLiteDB) GrantPermission(permission *entities.Permission, roles ...string) error {
 	//begin transaction
 	tx, err := sqlite.db.Begin()
 	if err != nil {
-		rollbackOrPanic(tx)
 		return err
 	}
 
@@ -72,8 +57,14 @@ func (sqlite *SQLiteDB) GrantPermission(permission *entities.Permission, roles .
 		return err
 	}
 
-	//add roles to permission node
+	//replace existing nodes of roles with the permission node
+	stmtDel := tx.Stmt(sqlite.qm.DelRPIEntryByRoleAndOperation)
 	for _, role := range roles {
+		_, err = stmtDel.Exec(role, permission.ResourceID, permission.TypeRWMD)
+		if err != nil {
+			rollbackOrPanic(tx)
+			return err
+		}
 		err = sqlite.grantPermNode(tx, permissionID, role)
 		if err != nil {
 			rollbackOrPanic(tx)
@@ -81,8 +72,14 @@ func (sqlite *SQLiteDB) GrantPermission(permission *entities.Permission, roles .
 		}
 	}
 
-	commitOrPanic(tx)
+	//delete permission nodes orphaned by replacement
+	_, err = tx.Stmt(sqlite.qm.DelOrphanedPermissionsByResourceID).Exec(permission.ResourceID)
+	if err != nil {
+		rollbackOrPanic(tx)
+		return err
+	}
 
+	commitOrPanic(tx)
 	return nil
 }
 
@@ -103,26 +100,18 @@ func (sqlite *SQLiteDB) constructPermNode(tx *sql.Tx, permission *entities.Permi
 		stmt = tx.Stmt(sqlite.qm.InsPermissionData)
 		res, err := stmt.Exec(permission.ResourceID, permission.TypeRWMD, permission.Status)
 		if err != nil {
-			rollbackOrPanic(tx)
 			return -1, err
 		}
-		permissionID, err = res.LastInsertId()
+		return res.LastInsertId()
 	} else if err != nil {
-		rollbackOrPanic(tx)
 		return -1, err
 	}
 	return permissionID, nil
 }
 
-// add roles to permission node
-//
-//todo:consider using in GrantPermission
+// add role to permission node
 func (sqlite *SQLiteDB) grantPermNode(tx *sql.Tx, permissionID int64, role string) error {
 	stmt := tx.Stmt(sqlite.qm.InsRolePermIntersectData)
 	_, err := stmt.Exec(role, permissionID)
-	if err != nil {
-		rollbackOrPanic(tx)
-		return err
-	}
-	return nil
+	return err
 }
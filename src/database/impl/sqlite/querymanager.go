@@ -39,6 +39,26 @@ type QueryManager struct {
 	DelRoleByID                                  *sql.Stmt
 	DelPermissionByResourceID                    *sql.Stmt
 	DelRPIEntryByRoleID                          *sql.Stmt
+	UpdKeyData                                   *sql.Stmt
+	DelKRIEntriesByKeyID                         *sql.Stmt
+	GetRoleData                                  *sql.Stmt
+	InsKeyRoleIntersectIfMissing                 *sql.Stmt
+	DelKRIEntry                                  *sql.Stmt
+	DelKRIEntriesByRoleID                        *sql.Stmt
+	DelRPIEntryByRoleAndOperation                *sql.Stmt
+	DelOrphanedPermissionsByResourceID           *sql.Stmt
+	DelRPIEntriesByResourceID                    *sql.Stmt
+	UpdKeysRateLimitID                           *sql.Stmt
+	InsTokenData                                 *sql.Stmt
+	GetTokens                                    *sql.Stmt
+	GetTokenData                                 *sql.Stmt
+	DelTokenByID                                 *sql.Stmt
+	InsResourcePath                              *sql.Stmt
+	GetPathResourceID                            *sql.Stmt
+	UpdResourcePathPrefix                        *sql.Stmt
+	DelResourcePath                              *sql.Stmt
+	DelResourcePathPrefix                        *sql.Stmt
+	DelResourcePathByResourceID                  *sql.Stmt
 }
 
 //go:embed readqueries/getResourceRoles.sql
@@ -74,13 +94,25 @@ func NewQueryManager(db *sql.DB) (qm *QueryManager, err error) {
 	if err != nil {
 		return qm, err
 	}
+	qm.InsKeyRoleIntersectIfMissing, err = db.Prepare("INSERT INTO KeyRoleIntersect (keyid, roleid) SELECT ?1, ?2 WHERE NOT EXISTS (SELECT 1 FROM KeyRoleIntersect WHERE keyid = ?1 AND roleid = ?2)") //GiveRole
+	if err != nil {
+		return qm, err
+	}
+	qm.InsTokenData, err = db.Prepare("INSERT INTO Tokens (tokenid, note, expires, created) VALUES (?, ?, ?, ?)") //CreateToken
+	if err != nil {
+		return qm, err
+	}
+	qm.InsResourcePath, err = db.Prepare("INSERT OR REPLACE INTO ResourcePaths (path, resourceid) VALUES (?, ?)") //SetResourcePath
+	if err != nil {
+		return qm, err
+	}
 
 	//Get operations
 	qm.GetRateLimitIDIfExists, err = db.Prepare("SELECT ratelimitid FROM main.Ratelimits WHERE ratelimitid = ?") //CreateKey
 	if err != nil {
 		return qm, err
 	}
-	qm.GetRoleIDIfExists, err = db.Prepare("SELECT roleid FROM Roles WHERE roleid = ?") //CreateKey
+	qm.GetRoleIDIfExists, err = db.Prepare("SELECT roleid FROM Roles WHERE roleid = ? AND roleTypeRK = 0") //CreateKey, GiveRole
 	if err != nil {
 		return qm, err
 	}
@@ -88,7 +120,7 @@ func NewQueryManager(db *sql.DB) (qm *QueryManager, err error) {
 	if err != nil {
 		return qm, err
 	}
-	qm.GetKeyIDs, err = db.Prepare("SELECT keyid FROM Keys LIMIT ? OFFSET ?") //GetKeyIDs
+	qm.GetKeyIDs, err = db.Prepare("SELECT keyid FROM Keys ORDER BY created, keyid LIMIT ? OFFSET ?") //GetKeyIDs
 	if err != nil {
 		return qm, err
 	}
@@ -112,7 +144,7 @@ func NewQueryManager(db *sql.DB) (qm *QueryManager, err error) {
 	if err != nil {
 		return qm, err
 	}
-	qm.GetRoleIDs, err = db.Prepare("SELECT roleid FROM Roles WHERE roleTypeRK=0 LIMIT ? OFFSET ?") //GetRoles
+	qm.GetRoleIDs, err = db.Prepare("SELECT roleid FROM Roles WHERE roleTypeRK = 0 ORDER BY rolePrecedence, roleid LIMIT ? OFFSET ?") //GetRoles
 	if err != nil {
 		return qm, err
 	}
@@ -148,6 +180,31 @@ func NewQueryManager(db *sql.DB) (qm *QueryManager, err error) {
 	if err != nil {
 		return qm, err
 	}
+	qm.UpdKeyData, err = db.Prepare("UPDATE Keys SET note = ?, ratelimitid = ?, expires = ? WHERE keyid = ?") //UpdateKey
+	if err != nil {
+		return qm, err
+	}
+	qm.GetRoleData, err = db.Prepare("SELECT rolePrecedence FROM Roles WHERE roleid = ? AND roleTypeRK = 0") //GetRoleData
+	if err != nil {
+		return qm, err
+	}
+	qm.GetTokens, err = db.Prepare("SELECT tokenid, note, expires, created FROM Tokens ORDER BY created, tokenid LIMIT ? OFFSET ?") //GetTokens
+	if err != nil {
+		return qm, err
+	}
+	qm.GetTokenData, err = db.Prepare("SELECT note, expires, created FROM Tokens WHERE tokenid = ?") //GetTokenData
+	if err != nil {
+		return qm, err
+	}
+	qm.GetPathResourceID, err = db.Prepare("SELECT resourceid FROM ResourcePaths WHERE path = ?") //GetPathResourceID
+	if err != nil {
+		return qm, err
+	}
+	// substr is used rather than LIKE, so that paths do not need to be escaped
+	qm.UpdResourcePathPrefix, err = db.Prepare("UPDATE OR REPLACE ResourcePaths SET path = ? || substr(path, ?) WHERE path = ? OR substr(path, 1, ?) = ?") //MoveResourcePaths
+	if err != nil {
+		return qm, err
+	}
 
 	//Delete operations
 	qm.DelPermissionByID, err = db.Prepare("DELETE FROM Permissions WHERE permissionid = ?") //RevokePermission
@@ -178,6 +235,50 @@ func NewQueryManager(db *sql.DB) (qm *QueryManager, err error) {
 	if err != nil {
 		return qm, err
 	}
+	qm.DelKRIEntriesByKeyID, err = db.Prepare("DELETE FROM KeyRoleIntersect WHERE keyid = ?") //DeleteKey
+	if err != nil {
+		return qm, err
+	}
+	qm.DelKRIEntry, err = db.Prepare("DELETE FROM KeyRoleIntersect WHERE keyid = ? AND roleid = ?") //TakeRole
+	if err != nil {
+		return qm, err
+	}
+	qm.DelKRIEntriesByRoleID, err = db.Prepare("DELETE FROM KeyRoleIntersect WHERE roleid = ?") //DeleteRole
+	if err != nil {
+		return qm, err
+	}
+	qm.DelRPIEntryByRoleAndOperation, err = db.Prepare("DELETE FROM RolePermIntersect WHERE roleid = ? AND permissionid IN (SELECT permissionid FROM Permissions WHERE resourceid = ? AND permTypeRWMD = ?)") //GrantPermission, RevokePermission
+	if err != nil {
+		return qm, err
+	}
+	qm.DelOrphanedPermissionsByResourceID, err = db.Prepare("DELETE FROM Permissions WHERE resourceid = ? AND permissionid NOT IN (SELECT permissionid FROM RolePermIntersect)") //GrantPermission, RevokePermission
+	if err != nil {
+		return qm, err
+	}
+	qm.DelRPIEntriesByResourceID, err = db.Prepare("DELETE FROM RolePermIntersect WHERE permissionid IN (SELECT permissionid FROM Permissions WHERE resourceid = ?)") //DeleteResource
+	if err != nil {
+		return qm, err
+	}
+	qm.UpdKeysRateLimitID, err = db.Prepare("UPDATE Keys SET ratelimitid = ? WHERE ratelimitid = ?") //UpdateRateLimit
+	if err != nil {
+		return qm, err
+	}
+	qm.DelTokenByID, err = db.Prepare("DELETE FROM Tokens WHERE tokenid = ?") //DeleteToken
+	if err != nil {
+		return qm, err
+	}
+	qm.DelResourcePath, err = db.Prepare("DELETE FROM ResourcePaths WHERE path = ?") //DeleteResourcePath
+	if err != nil {
+		return qm, err
+	}
+	qm.DelResourcePathPrefix, err = db.Prepare("DELETE FROM ResourcePaths WHERE path = ? OR substr(path, 1, ?) = ?") //DeleteResourcePaths
+	if err != nil {
+		return qm, err
+	}
+	qm.DelResourcePathByResourceID, err = db.Prepare("DELETE FROM ResourcePaths WHERE resourceid = ?") //DeleteResource
+	if err != nil {
+		return qm, err
+	}
 
 	//qm.q, err = db.Prepare("")
 	//if err != nil {
@@ -187,18 +288,19 @@ func NewQueryManager(db *sql.DB) (qm *QueryManager, err error) {
 	return qm, nil
 }
 
-// todo: test
+// freePreparedQueries closes every prepared statement of the query manager.
 func (qm *QueryManager) freePreparedQueries() error {
 	v := reflect.ValueOf(qm).Elem()
 	count := v.NumField()
 
 	for i := 0; i < count; i++ {
-		vi := v.Index(i)
-		if vi.Type().Name() == "*sql.Stmt" {
-			err := vi.Interface().(*sql.Stmt).Close()
-			if err != nil {
-				return err
-			}
+		stmt, ok := v.Field(i).Interface().(*sql.Stmt)
+		if !ok || stmt == nil {
+			continue
+		}
+		err := stmt.Close()
+		if err != nil {
+			return err
 		}
 	}
 	return nil
@@ -1,15 +1,21 @@
 package sqlite
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
+	"fsrv/src/database"
 	"fsrv/src/database/entities"
 	"fsrv/utils/serde"
 	"time"
 )
 
 func (sqlite *SQLiteDB) CreateRateLimit(limit *entities.RateLimit) error {
-	_, err := sqlite.qm.InsRateLimitData.Exec(limit.ID, limit.Limit, limit.Burst, limit.Refill)
+	reset := time.Duration(limit.Refill).Milliseconds()
+	_, err := sqlite.qm.InsRateLimitData.Exec(limit.ID, limit.Limit, limit.Burst, reset)
+	if isDuplicateError(err) {
+		return database.ErrRateLimitDuplicate
+	}
 	return err
 }
 
@@ -23,7 +29,7 @@ func (sqlite *SQLiteDB) SetRateLimit(key *entities.Key, limitID string) error {
 		return err
 	}
 	stmt := tx.Stmt(sqlite.qm.UpdKeyRateLimitID)
-	_, err = stmt.Exec(key.ID, limitID)
+	_, err = stmt.Exec(limitID, key.ID)
 	if err != nil {
 		rollbackOrPanic(tx)
 		return err
@@ -32,13 +38,15 @@ func (sqlite *SQLiteDB) SetRateLimit(key *entities.Key, limitID string) error {
 	return nil
 }
 
-// GetRateLimitData todo: wrap sql: error no rows
 func (sqlite *SQLiteDB) GetRateLimitData(ratelimitid string) (*entities.RateLimit, error) {
 	row := sqlite.qm.GetRateLimitDataByID.QueryRow(ratelimitid)
 	var rateLimit entities.RateLimit
 	var reset int64
 	err := row.Scan(&rateLimit.Limit, &rateLimit.Burst, &reset)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, database.ErrRateLimitMissing
+		}
 		return nil, err
 	}
 	rateLimit.ID = ratelimitid
@@ -53,16 +61,37 @@ func (sqlite *SQLiteDB) UpdateRateLimit(rateLimitID string, rateLimit *entities.
 		return err
 	}
 	stmt := tx.Stmt(sqlite.qm.UpdRateLimitData)
-	res, err := stmt.Exec(rateLimitID, rateLimit.ID, rateLimit.Limit, rateLimit.Burst, rateLimit.Refill)
+	reset := time.Duration(rateLimit.Refill).Milliseconds()
+	res, err := stmt.Exec(rateLimit.ID, rateLimit.Limit, rateLimit.Burst, reset, rateLimitID)
 	if err != nil {
+		rollbackOrPanic(tx)
+		if isDuplicateError(err) {
+			return database.ErrRateLimitDuplicate
+		}
 		return err
 	}
 	rowNum, err := res.RowsAffected()
 	if err != nil {
+		rollbackOrPanic(tx)
 		return err
 	}
+	if rowNum == 0 {
+		rollbackOrPanic(tx)
+		return database.ErrRateLimitMissing
+	}
 	if rowNum != 1 {
+		rollbackOrPanic(tx)
 		return errors.New(fmt.Sprintf("Failed to update rateLimit %d rows affected", rowNum))
 	}
+
+	//keys using the rate limit follow it when it is renamed
+	if rateLimit.ID != rateLimitID {
+		_, err = tx.Stmt(sqlite.qm.UpdKeysRateLimitID).Exec(rateLimit.ID, rateLimitID)
+		if err != nil {
+			rollbackOrPanic(tx)
+			return err
+		}
+	}
+	commitOrPanic(tx)
 	return nil
 }
@@ -0,0 +1,30 @@
+package sqlite
+
+import (
+	"fsrv/src/database"
+	"fsrv/src/database/entities"
+	"fsrv/utils/serde"
+	"github.com/go-playground/assert/v2"
+	"testing"
+	"time"
+)
+
+func TestSQLite_RateLimits(t *testing.T) {
+	db := getDB()
+	limit := &entities.RateLimit{ID: "default", Limit: 10, Burst: 5, Refill: serde.Duration(90 * time.Second)}
+	assert.Equal(t, nil, db.CreateRateLimit(limit))
+	assert.Equal(t, database.ErrRateLimitDuplicate, db.CreateRateLimit(limit))
+
+	got, err := db.GetRateLimitData("default")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, limit, got)
+
+	renamed := &entities.RateLimit{ID: "renamed", Limit: 20, Burst: 10, Refill: serde.Duration(time.Second)}
+	assert.Equal(t, nil, db.UpdateRateLimit("default", renamed))
+	got, err = db.GetRateLimitData("renamed")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, renamed, got)
+	_, err = db.GetRateLimitData("default")
+	assert.Equal(t, database.ErrRateLimitMissing, err)
+	assert.Equal(t, database.ErrRateLimitMissing, db.UpdateRateLimit("default", renamed))
+}
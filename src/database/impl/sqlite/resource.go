@@ -1,8 +1,17 @@
 package sqlite
 
-import "fsrv/src/database/entities"
+import (
+	"database/sql"
+	"fsrv/src/database"
+	"fsrv/src/database/entities"
+	"io"
+)
 
 func (sqlite *SQLiteDB) CreateResource(resource *entities.Resource) error {
+	if resource.ID == "" {
+		return database.ErrResourceNameBad
+	}
+
 	//begin transaction
 	tx, err := sqlite.db.Begin()
 	if err != nil {
@@ -14,6 +23,9 @@ func (sqlite *SQLiteDB) CreateResource(resource *entities.Resource) error {
 	_, err = stmt.Exec(resource.ID, resource.Flags)
 	if err != nil {
 		rollbackOrPanic(tx)
+		if isDuplicateError(err) {
+			return database.ErrResourceDuplicate
+		}
 		return err
 	}
 
@@ -36,8 +48,24 @@ func (sqlite *SQLiteDB) DeleteResource(id string) error {
 		return err
 	}
 
+	//delete associated RolePermIntersect entries
+	stmt := tx.Stmt(sqlite.qm.DelRPIEntriesByResourceID)
+	_, err = stmt.Exec(id)
+	if err != nil {
+		rollbackOrPanic(tx)
+		return err
+	}
+
 	//delete associated permissions
-	stmt := tx.Stmt(sqlite.qm.DelPermissionByResourceID)
+	stmt = tx.Stmt(sqlite.qm.DelPermissionByResourceID)
+	_, err = stmt.Exec(id)
+	if err != nil {
+		rollbackOrPanic(tx)
+		return err
+	}
+
+	//detach from paths
+	stmt = tx.Stmt(sqlite.qm.DelResourcePathByResourceID)
 	_, err = stmt.Exec(id)
 	if err != nil {
 		rollbackOrPanic(tx)
@@ -46,11 +74,20 @@ func (sqlite *SQLiteDB) DeleteResource(id string) error {
 
 	//delete underlying resource
 	stmt = tx.Stmt(sqlite.qm.DelResourceByID)
-	_, err = stmt.Exec(id)
+	res, err := stmt.Exec(id)
 	if err != nil {
 		rollbackOrPanic(tx)
 		return err
 	}
+	rowsDeleted, err := res.RowsAffected()
+	if err != nil {
+		rollbackOrPanic(tx)
+		return err
+	}
+	if rowsDeleted == 0 {
+		rollbackOrPanic(tx)
+		return database.ErrResourceMissing
+	}
 
 	//commit
 	commitOrPanic(tx)
@@ -60,14 +97,14 @@ func (sqlite *SQLiteDB) DeleteResource(id string) error {
 func (sqlite *SQLiteDB) GetResources(pageSize int, offset int) ([]*entities.Resource, error) {
 	resourceIDs, err := sqlite.GetResourceIDs(pageSize, offset)
 	if err != nil {
-		return nil, nil
+		return nil, err
 	}
-	resources := make([]*entities.Resource, 0, len(resourceIDs))
+	resources := make([]*entities.Resource, len(resourceIDs))
 
 	for i, id := range resourceIDs {
 		resources[i], err = sqlite.GetResourceData(id)
 		if err != nil {
-			return resources, err
+			return resources[:i], err
 		}
 	}
 
@@ -100,7 +137,10 @@ func (sqlite *SQLiteDB) GetResourceData(resourceid string) (*entities.Resource,
 		return nil, err
 	}
 
-	var res entities.Resource
+	res := entities.Resource{
+		ID:             resourceid,
+		OperationNodes: make(map[entities.ResourceOperationAccess]bool),
+	}
 
 	//get flags
 	stmt := tx.Stmt(sqlite.qm.GetResourceFlagsByID)
@@ -109,6 +149,9 @@ func (sqlite *SQLiteDB) GetResourceData(resourceid string) (*entities.Resource,
 	err = row.Scan(&res.Flags)
 	if err != nil {
 		_ = tx.Rollback()
+		if err == sql.ErrNoRows {
+			return nil, database.ErrResourceMissing
+		}
 		return nil, err
 	}
 
@@ -120,13 +163,17 @@ func (sqlite *SQLiteDB) GetResourceData(resourceid string) (*entities.Resource,
 	}
 
 	//get permissions
-	for iter() == nil {
+	for err = iter(); err == nil; err = iter() {
 		key := entities.ResourceOperationAccess{
 			ID:   roleperm.Role.ID,
 			Type: roleperm.Perm.TypeRWMD,
 		}
 		res.OperationNodes[key] = roleperm.Perm.Status
 	}
+	if err != io.EOF {
+		_ = tx.Rollback()
+		return nil, err
+	}
 
 	_ = tx.Commit()
 
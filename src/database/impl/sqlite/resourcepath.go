@@ -0,0 +1,47 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fsrv/src/database"
+	"strings"
+	"unicode/utf8"
+)
+
+func (sqlite *SQLiteDB) SetResourcePath(path string, resourceID string) error {
+	_, err := sqlite.qm.InsResourcePath.Exec(path, resourceID)
+	return err
+}
+
+func (sqlite *SQLiteDB) GetPathResourceID(path string) (string, error) {
+	var resourceID string
+	row := sqlite.qm.GetPathResourceID.QueryRow(path)
+	err := row.Scan(&resourceID)
+	if err == sql.ErrNoRows {
+		return "", database.ErrResourcePathMissing
+	}
+	return resourceID, err
+}
+
+func (sqlite *SQLiteDB) MoveResourcePaths(oldPath string, newPath string) error {
+	prefix := descendantPrefix(oldPath)
+	// substr counts characters, and is indexed from 1
+	n := utf8.RuneCountInString(oldPath)
+	_, err := sqlite.qm.UpdResourcePathPrefix.Exec(newPath, n+1, oldPath, utf8.RuneCountInString(prefix), prefix)
+	return err
+}
+
+func (sqlite *SQLiteDB) DeleteResourcePath(path string) error {
+	_, err := sqlite.qm.DelResourcePath.Exec(path)
+	return err
+}
+
+func (sqlite *SQLiteDB) DeleteResourcePaths(path string) error {
+	prefix := descendantPrefix(path)
+	_, err := sqlite.qm.DelResourcePathPrefix.Exec(path, utf8.RuneCountInString(prefix), prefix)
+	return err
+}
+
+// descendantPrefix returns the prefix shared by the paths of all descendants of a path.
+func descendantPrefix(path string) string {
+	return strings.TrimSuffix(path, "/") + "/"
+}
@@ -0,0 +1,45 @@
+package sqlite
+
+import (
+	"fsrv/src/database"
+	"github.com/go-playground/assert/v2"
+	"testing"
+)
+
+func TestSQLite_ResourcePaths(t *testing.T) {
+	db := getDB()
+	paths := map[string]string{
+		"/dir":           "a",
+		"/dir/file.txt":  "b",
+		"/dir/ü/ö.txt":   "c",
+		"/directory.txt": "d",
+	}
+	for path, id := range paths {
+		assert.Equal(t, nil, db.SetResourcePath(path, id))
+	}
+
+	assert.Equal(t, nil, db.MoveResourcePaths("/dir", "/.trash/1/data"))
+	for path, id := range map[string]string{
+		"/.trash/1/data":          "a",
+		"/.trash/1/data/file.txt": "b",
+		"/.trash/1/data/ü/ö.txt":  "c",
+		"/directory.txt":          "d",
+	} {
+		got, err := db.GetPathResourceID(path)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, id, got)
+	}
+	_, err := db.GetPathResourceID("/dir/file.txt")
+	assert.Equal(t, database.ErrResourcePathMissing, err)
+
+	assert.Equal(t, nil, db.DeleteResourcePaths("/.trash/1"))
+	_, err = db.GetPathResourceID("/.trash/1/data/ü/ö.txt")
+	assert.Equal(t, database.ErrResourcePathMissing, err)
+	got, err := db.GetPathResourceID("/directory.txt")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "d", got)
+
+	assert.Equal(t, nil, db.DeleteResourcePath("/directory.txt"))
+	_, err = db.GetPathResourceID("/directory.txt")
+	assert.Equal(t, database.ErrResourcePathMissing, err)
+}
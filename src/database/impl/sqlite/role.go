@@ -2,10 +2,15 @@ package sqlite
 
 import (
 	"database/sql"
+	"fsrv/src/database"
 	"fsrv/src/database/entities"
 )
 
 func (sqlite *SQLiteDB) CreateRole(role *entities.Role) error {
+	if role.ID == "" || role.ID == "*" {
+		return database.ErrRoleNameBad
+	}
+
 	tx, err := sqlite.db.Begin()
 	if err != nil {
 		return err
@@ -14,6 +19,9 @@ func (sqlite *SQLiteDB) CreateRole(role *entities.Role) error {
 	res, err := stmt.Exec(role.ID, 0, role.Precedence)
 	if err != nil {
 		rollbackOrPanic(tx)
+		if isDuplicateError(err) {
+			return database.ErrRoleDuplicate
+		}
 		return err
 	}
 	rowsInserted, err := res.RowsAffected()
@@ -36,6 +44,13 @@ func (sqlite *SQLiteDB) DeleteRole(name string) error {
 		return err
 	}
 
+	//ensure the role exists, and is not a KeyRole
+	err = sqlite.checkRoleExists(tx, name)
+	if err != nil {
+		rollbackOrPanic(tx)
+		return err
+	}
+
 	//delete associated RolePermIntersect entries
 	stmt := tx.Stmt(sqlite.qm.DelRPIEntryByRoleID)
 	_, err = stmt.Exec(name)
@@ -44,6 +59,14 @@ func (sqlite *SQLiteDB) DeleteRole(name string) error {
 		return err
 	}
 
+	//delete associated KeyRoleIntersect entries
+	stmt = tx.Stmt(sqlite.qm.DelKRIEntriesByRoleID)
+	_, err = stmt.Exec(name)
+	if err != nil {
+		rollbackOrPanic(tx)
+		return err
+	}
+
 	//delete underlying role
 	stmt = tx.Stmt(sqlite.qm.DelRoleByID)
 	_, err = stmt.Exec(name)
@@ -60,54 +83,116 @@ func (sqlite *SQLiteDB) DeleteRole(name string) error {
 func (sqlite *SQLiteDB) GetRoles(pageSize int, offset int) ([]string, error) {
 	var role string
 	var roles []string
-	rows, err := sqlite.db.Query("SELECT roleid FROM Roles WHERE roleTypeRK=0 LIMIT ? OFFSET ?", pageSize, offset)
+	rows, err := sqlite.qm.GetRoleIDs.Query(pageSize, offset)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	for rows.Next() {
-		err = rows.Scan(role)
+		err = rows.Scan(&role)
 		if err != nil {
 			return roles, err
 		}
 		roles = append(roles, role)
 	}
 
-	return roles, nil
+	return roles, rows.Err()
 }
 
-func (sqlite *SQLiteDB) GiveRole(keyid string, roles ...string) error {
-	query := ""
-	params := make([]string, len(roles)*2)
-	for i, role := range roles {
-		query += "(?, ?),"
-
-		params[i*2] = keyid
-		params[i*2+1] = role
+func (sqlite *SQLiteDB) GetRoleData(roleID string) (*entities.Role, error) {
+	role := entities.Role{ID: roleID}
+	row := sqlite.qm.GetRoleData.QueryRow(roleID)
+	err := row.Scan(&role.Precedence)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, database.ErrRoleMissing
+		}
+		return nil, err
 	}
+	return &role, nil
+}
 
-	query = query[:len(query)-1]
+func (sqlite *SQLiteDB) GiveRole(keyid string, roles ...string) error {
+	//begin transaction
+	tx, err := sqlite.db.Begin()
+	if err != nil {
+		return err
+	}
 
-	_, err := sqlite.db.Query("INSERT INTO KeyRoleIntersect VALUES "+query, params)
+	err = sqlite.checkKeyExists(tx, keyid)
 	if err != nil {
+		rollbackOrPanic(tx)
 		return err
 	}
+
+	stmtIns := tx.Stmt(sqlite.qm.InsKeyRoleIntersectIfMissing)
+	for _, role := range roles {
+		err = sqlite.checkRoleExists(tx, role)
+		if err != nil {
+			rollbackOrPanic(tx)
+			return err
+		}
+
+		_, err = stmtIns.Exec(keyid, role)
+		if err != nil {
+			rollbackOrPanic(tx)
+			return err
+		}
+	}
+
+	commitOrPanic(tx)
 	return nil
 }
 
 func (sqlite *SQLiteDB) TakeRole(keyid string, roles ...string) error {
-	query := ""
-	params := make([]string, len(roles))
-	for i, role := range roles {
-		query += "?,"
-
-		params[i] = role
+	//begin transaction
+	tx, err := sqlite.db.Begin()
+	if err != nil {
+		return err
 	}
-	query = query[:len(query)-1]
 
-	_, err := sqlite.db.Query("DELETE FROM KeyRoleIntersect WHERE keyid = ? AND roleid IN ("+query+")", keyid, params)
+	err = sqlite.checkKeyExists(tx, keyid)
 	if err != nil {
+		rollbackOrPanic(tx)
 		return err
 	}
+
+	stmtDel := tx.Stmt(sqlite.qm.DelKRIEntry)
+	for _, role := range roles {
+		if role == keyid {
+			//the KeyRole cannot be taken from its key
+			rollbackOrPanic(tx)
+			return database.ErrRoleNameBad
+		}
+
+		_, err = stmtDel.Exec(keyid, role)
+		if err != nil {
+			rollbackOrPanic(tx)
+			return err
+		}
+	}
+
+	commitOrPanic(tx)
 	return nil
 }
+
+// checkRoleExists returns ErrRoleMissing if a role does not exist, or is a KeyRole.
+func (sqlite *SQLiteDB) checkRoleExists(tx *sql.Tx, role string) error {
+	row := tx.Stmt(sqlite.qm.GetRoleIDIfExists).QueryRow(role)
+	err := row.Scan(&role)
+	if err == sql.ErrNoRows {
+		return database.ErrRoleMissing
+	}
+	return err
+}
+
+// checkKeyExists returns ErrKeyMissing if a key does not exist.
+func (sqlite *SQLiteDB) checkKeyExists(tx *sql.Tx, keyid string) error {
+	row := tx.Stmt(sqlite.qm.GetKeyIDIfExists).QueryRow(keyid)
+	err := row.Scan(&keyid)
+	if err == sql.ErrNoRows {
+		return database.ErrKeyMissing
+	}
+	return err
+}
@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"fsrv/src/database/entities"
 	"fsrv/src/database/impl"
+	"fsrv/src/database/migration"
+	"io"
 )
-import _ "github.com/mattn/go-sqlite3"
-import _ "embed"
+import "github.com/mattn/go-sqlite3"
+import "embed"
 
 type SQLiteDB struct {
 	db *sql.DB
@@ -28,32 +30,67 @@ type SQLiteDB struct {
 	 Functions
 \*               */
 
-//go:embed dbqueries/create.sql
-var sqliteDatabaseCreationQuery string
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
 
+var migrations = migration.MustLoad(migrationFiles, "migrations")
+
+// Create opens a database file, and upgrades its schema to the latest version.
 func Create(databaseFile string) (*SQLiteDB, error) {
-	db, err := sql.Open("sqlite3", databaseFile)
+	db, err := openFile(databaseFile)
 	if err != nil {
 		return nil, err
 	}
 
-	_, err = db.Exec(sqliteDatabaseCreationQuery)
+	_, _, err = newMigrator(db).Migrate(0)
 	if err != nil {
+		_ = db.Close()
 		return nil, err
 	}
 
 	return produceObj(db)
 }
 
-func Open(databaseFile string) (*SQLiteDB, error) {
-	db, err := sql.Open("sqlite3", databaseFile)
+// Open opens a database file, whose schema is upgraded automatically up to
+// version, or the latest version if 0, and must then be the latest version.
+func Open(databaseFile string, version int) (*SQLiteDB, error) {
+	db, err := openFile(databaseFile)
+	if err != nil {
+		return nil, err
+	}
+
+	err = newMigrator(db).Prepare(version)
 	if err != nil {
+		_ = db.Close()
 		return nil, err
 	}
 
 	return produceObj(db)
 }
 
+// Migrate upgrades or downgrades the schema of a database file to version, or
+// to the latest version if 0, returning the versions it was migrated from and to.
+func Migrate(databaseFile string, version int) (from int, to int, err error) {
+	db, err := openFile(databaseFile)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer db.Close()
+
+	return newMigrator(db).Migrate(version)
+}
+
+// openFile opens a database file. Writers wait for each other rather than
+// failing while the file is locked, and transactions take the write lock as
+// they begin, since one which upgrades its read lock could not wait.
+func openFile(databaseFile string) (*sql.DB, error) {
+	return sql.Open("sqlite3", databaseFile+"?_busy_timeout=10000&_txlock=immediate")
+}
+
+func newMigrator(db *sql.DB) *migration.Migrator {
+	return migration.New(db, migrations, migration.Exec)
+}
+
 func produceObj(sqlDB *sql.DB) (dbObj *SQLiteDB, err error) {
 	dbObj = &SQLiteDB{sqlDB, nil}
 	dbObj.qm, err = NewQueryManager(sqlDB)
@@ -75,29 +112,42 @@ func (sqlite *SQLiteDB) Check() error {
 	if err != nil {
 		return err
 	}
+	defer rows.Close()
 	tableMap := map[string]bool{
-		"KeyRoleIntersect": false,
-		"Keys":             false,
-		"Permissions":      false,
-		"Ratelimits":       false,
-		"Resources":        false,
-		"KeyPermIntersect": false,
-		"Roles":            false,
-		"sqlite_master":    false,
+		"KeyRoleIntersect":  false,
+		"Keys":              false,
+		"Permissions":       false,
+		"Ratelimits":        false,
+		"Resources":         false,
+		"ResourcePaths":     false,
+		"Tokens":            false,
+		"RolePermIntersect": false,
+		"Roles":             false,
+		"schema_version":    false,
+	}
+	// internal tables, which sqlite creates as needed
+	internalTables := map[string]bool{
+		"sqlite_sequence": true,
 	}
 
 	var name string
 	for rows.Next() {
-		err = rows.Scan(name)
+		err = rows.Scan(&name)
 		if err != nil {
 			return err
 		}
+		if internalTables[name] {
+			continue
+		}
 		if _, ok := tableMap[name]; ok {
 			tableMap[name] = true
 		} else {
 			return errors.New(fmt.Sprintf("Extraneous table \"%s\" should not exist in database", name))
 		}
 	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
 
 	for key, val := range tableMap {
 		if !val {
@@ -108,11 +158,20 @@ func (sqlite *SQLiteDB) Check() error {
 	return nil
 }
 
+// Close closes the prepared statements and the underlying database handle.
+func (sqlite *SQLiteDB) Close() error {
+	err := sqlite.qm.freePreparedQueries()
+	if err != nil {
+		return err
+	}
+	return sqlite.db.Close()
+}
+
 //go:embed dbqueries/destroy.sql
 var sqliteDatabaseDestructionQuery string
 
 func (sqlite *SQLiteDB) Destroy() error {
-	_, err := sqlite.db.Query(sqliteDatabaseDestructionQuery)
+	_, err := sqlite.db.Exec(sqliteDatabaseDestructionQuery)
 	return err
 }
 
@@ -124,7 +183,8 @@ func (sqlite *SQLiteDB) Destroy() error {
 //									   //
 /////////////////////////////////////////
 
-// getResourceRoles
+// getResourceRolePermIter returns an iterator over the permission nodes of a resource,
+// which fills in the returned RolePerm and returns io.EOF once all have been read.
 func (sqlite *SQLiteDB) getResourceRolePermIter(tx *sql.Tx, resourceID string) (func() error, *entities.RolePerm, error) {
 	stmt := tx.Stmt(sqlite.qm.GetResourceRoles)
 	rows, err := stmt.Query(resourceID)
@@ -134,50 +194,47 @@ func (sqlite *SQLiteDB) getResourceRolePermIter(tx *sql.Tx, resourceID string) (
 
 	var rolePerm entities.RolePerm
 	roleIterNext := func() error {
-		rows.Next()
-		return rows.Scan(&rolePerm.Role, &rolePerm.Perm.Status, &rolePerm.Perm.TypeRWMD)
+		if !rows.Next() {
+			err := rows.Err()
+			_ = rows.Close()
+			if err != nil {
+				return err
+			}
+			return io.EOF
+		}
+		return rows.Scan(&rolePerm.Role.ID, &rolePerm.Perm.Status, &rolePerm.Perm.TypeRWMD)
 	}
 	return roleIterNext, &rolePerm, nil
 }
 
 func (sqlite *SQLiteDB) createResourcePermissions(tx *sql.Tx, resource *entities.Resource) error {
-	var err error
-	var allowID int64 = -1
-	var denyID int64 = -1
-
 	for key, status := range resource.OperationNodes {
-		if status && allowID == -1 {
-			allowID, err = sqlite.constructPermNode(tx, &entities.Permission{
-				ResourceID: resource.ID,
-				TypeRWMD:   key.Type,
-				Status:     status,
-			})
-			if err != nil {
-				return err
-			}
-			err = sqlite.grantPermNode(tx, allowID, key.ID)
-			if err != nil {
-				return err
-			}
-		} else if denyID == -1 {
-			denyID, err = sqlite.constructPermNode(tx, &entities.Permission{
-				ResourceID: resource.ID,
-				TypeRWMD:   key.Type,
-				Status:     status,
-			})
-			if err != nil {
-				return err
-			}
-			err = sqlite.grantPermNode(tx, denyID, key.ID)
-			if err != nil {
-				return err
-			}
+		permissionID, err := sqlite.constructPermNode(tx, &entities.Permission{
+			ResourceID: resource.ID,
+			TypeRWMD:   key.Type,
+			Status:     status,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = sqlite.grantPermNode(tx, permissionID, key.ID)
+		if err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
+// isDuplicateError returns whether an error was caused
+// by inserting a row with a primary key already in use.
+func isDuplicateError(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && (sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey ||
+		sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique)
+}
+
 func rollbackOrPanic(tx *sql.Tx) {
 	err := tx.Rollback()
 	if err != nil {
@@ -219,7 +276,7 @@ func (sqlite *SQLiteDB) deleteObjByID(stmt *sql.Stmt, args ...any) error {
 
 	//run delete statement in transaction
 	txStmt := tx.Stmt(stmt)
-	_, err = txStmt.Exec(args)
+	_, err = txStmt.Exec(args...)
 	if err != nil {
 		rollbackOrPanic(tx)
 		return err
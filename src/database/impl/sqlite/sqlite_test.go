@@ -0,0 +1,87 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fsrv/src/database"
+	"fsrv/src/database/databasetest"
+	"fsrv/src/database/migration"
+	"github.com/go-playground/assert/v2"
+	"path/filepath"
+	"testing"
+)
+
+func TestSQLite_Check(t *testing.T) {
+	db := getDB()
+	assert.Equal(t, nil, db.Check())
+
+	_, err := db.db.Exec("CREATE TABLE Extraneous (id INTEGER)")
+	assert.Equal(t, nil, err)
+	assert.NotEqual(t, nil, db.Check())
+}
+
+func TestSQLite_Conformance(t *testing.T) {
+	databasetest.Run(t, func(t *testing.T) database.DBInterface {
+		db, err := Create(filepath.Join(t.TempDir(), "fsrv.sqlite"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			_ = db.Close()
+		})
+		return db
+	})
+}
+
+func TestSQLite_Close(t *testing.T) {
+	db := getDB()
+	assert.Equal(t, nil, db.Close())
+
+	_, err := db.GetKeyIDs(10, 0)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestSQLite_Migrate(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "fsrv.sqlite")
+	db, err := Open(file, 0)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, db.Check())
+	assert.Equal(t, nil, db.Close())
+
+	latest := len(migrations)
+	from, to, err := Migrate(file, 1)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, latest, from)
+	assert.Equal(t, 1, to)
+
+	// outdated schemas are only upgraded automatically up to the configured version
+	_, err = Open(file, 1)
+	assert.Equal(t, true, errors.Is(err, migration.ErrSchemaOutdated))
+	db, err = Open(file, latest)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, db.Check())
+
+	// newer schemas are refused
+	_, err = db.db.Exec("UPDATE schema_version SET version = ?", latest+1)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, db.Close())
+	_, err = Open(file, 0)
+	assert.Equal(t, true, errors.Is(err, migration.ErrSchemaNewer))
+}
+
+func TestSQLite_OpenUnversioned(t *testing.T) {
+	// databases created before the schema was versioned are adopted
+	file := filepath.Join(t.TempDir(), "fsrv.sqlite")
+	sqlDB, err := sql.Open("sqlite3", file)
+	assert.Equal(t, nil, err)
+	_, err = sqlDB.Exec(migrations[0].Up)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, sqlDB.Close())
+
+	db, err := Open(file, 1)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, db.Check())
+	version, err := newMigrator(db.db).Version()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, len(migrations), version)
+}
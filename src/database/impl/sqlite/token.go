@@ -0,0 +1,80 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fsrv/src/database"
+	"fsrv/src/database/entities"
+	"fsrv/utils/serde"
+	"time"
+)
+
+func (sqlite *SQLiteDB) CreateToken(token *entities.Token) error {
+	if token.ID == "" {
+		return errors.New("required feild tokenid not specified")
+	}
+
+	_, err := sqlite.qm.InsTokenData.Exec(token.ID, token.Comment, time.Time(token.ExpiresAt).UnixMilli(), time.Time(token.CreatedAt).UnixMilli())
+	if err != nil {
+		if isDuplicateError(err) {
+			return database.ErrTokenDuplicate
+		}
+		return err
+	}
+	return nil
+}
+
+func (sqlite *SQLiteDB) GetTokens(pageSize int, offset int) ([]*entities.Token, error) {
+	rows, err := sqlite.qm.GetTokens.Query(pageSize, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []*entities.Token
+	for rows.Next() {
+		var token entities.Token
+		var expireMS, createMS int64
+		err = rows.Scan(&token.ID, &token.Comment, &expireMS, &createMS)
+		if err != nil {
+			return tokens, err
+		}
+		token.ExpiresAt = serde.Time(time.UnixMilli(expireMS))
+		token.CreatedAt = serde.Time(time.UnixMilli(createMS))
+		tokens = append(tokens, &token)
+	}
+	return tokens, rows.Err()
+}
+
+func (sqlite *SQLiteDB) GetTokenData(tokenID string) (*entities.Token, error) {
+	var token entities.Token
+	var expireMS, createMS int64
+	row := sqlite.qm.GetTokenData.QueryRow(tokenID)
+	err := row.Scan(&token.Comment, &expireMS, &createMS)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, database.ErrTokenMissing
+		}
+		return nil, err
+	}
+
+	token.ID = tokenID
+	token.ExpiresAt = serde.Time(time.UnixMilli(expireMS))
+	token.CreatedAt = serde.Time(time.UnixMilli(createMS))
+	return &token, nil
+}
+
+func (sqlite *SQLiteDB) DeleteToken(id string) error {
+	res, err := sqlite.qm.DelTokenByID.Exec(id)
+	if err != nil {
+		return err
+	}
+	rowNum, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowNum == 0 {
+		return database.ErrTokenMissing
+	}
+	return nil
+}
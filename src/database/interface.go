@@ -5,10 +5,16 @@ import (
 )
 
 type DBInterface interface {
+	// Check verifies that the schema of the database is complete.
+	Check() error
+	// Close closes the database. It must not be used afterwards.
+	Close() error
+
 	CreateKey(key *entities.Key) error
 	CreateResource(resource *entities.Resource) error
 	CreateRole(role *entities.Role) error
 	CreateRateLimit(limit *entities.RateLimit) error
+	CreateToken(token *entities.Token) error
 
 	GetKeys(pageSize int, offset int) ([]*entities.Key, error)
 	GetKeyIDs(pageSize int, offset int) ([]string, error)
@@ -17,7 +23,12 @@ type DBInterface interface {
 	GetResourceIDs(pageSize int, offset int) ([]string, error)
 	GetResourceData(resourceID string) (*entities.Resource, error)
 	GetRoles(pageSize int, offset int) ([]string, error)
+	GetRoleData(roleID string) (*entities.Role, error)
+	GetTokens(pageSize int, offset int) ([]*entities.Token, error)
+	GetTokenData(tokenID string) (*entities.Token, error)
 
+	// UpdateKey updates the comment, rate limit id and expiry of a key.
+	UpdateKey(key *entities.Key) error
 	GiveRole(keyID string, role ...string) error
 	TakeRole(keyID string, role ...string) error
 	GrantPermission(permission *entities.Permission, role ...string) error
@@ -28,7 +39,17 @@ type DBInterface interface {
 	UpdateRateLimit(rateLimitID string, rateLimit *entities.RateLimit) error
 	DeleteRateLimit(rateLimitID string) error
 
+	// SetResourcePath attaches a resource to a path, replacing any already attached.
+	SetResourcePath(path string, resourceID string) error
+	GetPathResourceID(path string) (string, error)
+	// MoveResourcePaths moves the resources attached to a path and its descendants to a new path.
+	MoveResourcePaths(oldPath string, newPath string) error
+	DeleteResourcePath(path string) error
+	// DeleteResourcePaths detaches the resources attached to a path and its descendants.
+	DeleteResourcePaths(path string) error
+
 	DeleteRole(name string) error
 	DeleteKey(id string) error
 	DeleteResource(id string) error
+	DeleteToken(id string) error
 }
@@ -0,0 +1,240 @@
+// Package migration upgrades and downgrades the schemas of the sql databases.
+// Each backend embeds numbered scripts named "<version>_<name>.up.sql" and
+// "<version>_<name>.down.sql", starting from version 1, and the version of a
+// database's schema is stored in its schema_version table.
+package migration
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+var (
+	// ErrSchemaNewer is returned when the schema of a database is newer than
+	// the latest migration, such as when an older build of fsrv is started.
+	ErrSchemaNewer = errors.New("the database schema is newer than this build of fsrv supports")
+	// ErrSchemaOutdated is returned when the schema of a database is older than
+	// the latest migration, and may not be upgraded automatically.
+	ErrSchemaOutdated = errors.New("the database schema is outdated, upgrade it with 'fsrv db migrate'")
+)
+
+// Migration is a numbered change to the schema of a database.
+type Migration struct {
+	// Version is the version of the schema once the migration is applied.
+	Version int
+	// Name describes the migration.
+	Name string
+	// Up is the script which applies the migration.
+	Up string
+	// Down is the script which reverts the migration.
+	Down string
+}
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Load loads the migrations in the root of fsys. Every version from 1 to
+// the latest must have both an up and a down script.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		version, err := strconv.Atoi(match[1])
+		if err != nil {
+			return nil, err
+		}
+		script, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if match[3] == "up" {
+			m.Up = string(script)
+		} else {
+			m.Down = string(script)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			return nil, fmt.Errorf("missing migration for version %d", i+1)
+		}
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d (%s) requires both an up and a down script", m.Version, m.Name)
+		}
+	}
+	return migrations, nil
+}
+
+// MustLoad loads migrations as Load does, and panics if they are invalid.
+// It is used for the migrations embedded in each backend.
+func MustLoad(fsys fs.FS, dir string) []Migration {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	migrations, err := Load(sub)
+	if err != nil {
+		panic("invalid migrations: " + err.Error())
+	}
+	return migrations
+}
+
+// ExecFunc executes a script of one or more statements in a transaction.
+type ExecFunc func(tx *sql.Tx, script string) error
+
+// Exec executes a script in a single query, for drivers which
+// allow multiple statements in a query.
+func Exec(tx *sql.Tx, script string) error {
+	_, err := tx.Exec(script)
+	return err
+}
+
+// Migrator migrates the schema of a database.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+	exec       ExecFunc
+}
+
+func New(db *sql.DB, migrations []Migration, exec ExecFunc) *Migrator {
+	return &Migrator{db: db, migrations: migrations, exec: exec}
+}
+
+// Latest returns the version of the latest migration.
+func (m *Migrator) Latest() int {
+	return len(m.migrations)
+}
+
+// Version returns the version of the schema of the database, which is
+// 0 if it was created before its schema was versioned, or is empty.
+func (m *Migrator) Version() (int, error) {
+	_, err := m.db.Exec("CREATE TABLE IF NOT EXISTS schema_version (version INTEGER NOT NULL)")
+	if err != nil {
+		return 0, err
+	}
+
+	var version int
+	row := m.db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_version")
+	err = row.Scan(&version)
+	return version, err
+}
+
+// Migrate upgrades or downgrades the schema of the database to a version,
+// or to the latest version if 0, returning the versions it was migrated from
+// and to. Each migration is applied in a transaction, though databases such
+// as MariaDB commit changes to tables immediately.
+func (m *Migrator) Migrate(version int) (from int, to int, err error) {
+	if version == 0 {
+		version = m.Latest()
+	}
+	if version < 0 || version > m.Latest() {
+		return 0, 0, fmt.Errorf("unknown schema version %d, the latest is %d", version, m.Latest())
+	}
+
+	from, err = m.Version()
+	if err != nil {
+		return from, from, err
+	}
+	if from > m.Latest() {
+		return from, from, m.newerError(from)
+	}
+
+	for to = from; to < version; to++ {
+		err = m.apply(m.migrations[to].Up, to+1)
+		if err != nil {
+			return from, to, fmt.Errorf("error applying migration %d (%s): %v", to+1, m.migrations[to].Name, err)
+		}
+	}
+	for ; to > version; to-- {
+		err = m.apply(m.migrations[to-1].Down, to-1)
+		if err != nil {
+			return from, to, fmt.Errorf("error reverting migration %d (%s): %v", to, m.migrations[to-1].Name, err)
+		}
+	}
+	return from, to, nil
+}
+
+/*
+Prepare brings the schema of the database to the latest version, as it is opened
+-Empty and unversioned databases are upgraded to the latest version
+-Other databases are upgraded automatically up to the configured version, or the latest if 0
+-Returns ErrSchemaOutdated if the schema is still older than the latest version
+-Returns ErrSchemaNewer if the schema is newer than the latest version
+*/
+func (m *Migrator) Prepare(version int) error {
+	current, err := m.Version()
+	if err != nil {
+		return err
+	}
+	latest := m.Latest()
+	if current > latest {
+		return m.newerError(current)
+	}
+
+	if current == 0 || version == 0 || version > latest {
+		version = latest
+	}
+	if current < version {
+		_, _, err = m.Migrate(version)
+		if err != nil {
+			return err
+		}
+		current = version
+	}
+
+	if current < latest {
+		return fmt.Errorf("%w: the schema is version %d, and this build requires version %d", ErrSchemaOutdated, current, latest)
+	}
+	return nil
+}
+
+// apply runs a script and records the resulting version in a transaction.
+func (m *Migrator) apply(script string, version int) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	err = m.exec(tx, script)
+	if err == nil {
+		_, err = tx.Exec("DELETE FROM schema_version")
+	}
+	if err == nil {
+		// the version is formatted into the query, since drivers use different placeholders
+		_, err = tx.Exec("INSERT INTO schema_version (version) VALUES (" + strconv.Itoa(version) + ")")
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (m *Migrator) newerError(version int) error {
+	return fmt.Errorf("%w: the schema is version %d, and the latest known is version %d", ErrSchemaNewer, version, m.Latest())
+}
@@ -0,0 +1,124 @@
+package migration
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/go-playground/assert/v2"
+	_ "github.com/mattn/go-sqlite3"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+var testFiles = fstest.MapFS{
+	"0001_items.up.sql":     {Data: []byte("CREATE TABLE Items (id TEXT);")},
+	"0001_items.down.sql":   {Data: []byte("DROP TABLE Items;")},
+	"0002_owners.up.sql":    {Data: []byte("ALTER TABLE Items ADD COLUMN owner TEXT;")},
+	"0002_owners.down.sql":  {Data: []byte("ALTER TABLE Items DROP COLUMN owner;")},
+	"0003_invalid.up.sql":   {Data: []byte("CREATE TABLE Items (id TEXT);")},
+	"0003_invalid.down.sql": {Data: []byte("SELECT 1;")},
+	"README.md":             {Data: []byte("not a migration")},
+}
+
+func newTestMigrator(t *testing.T, versions int) *Migrator {
+	migrations, err := Load(testFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	return New(db, migrations[:versions], Exec)
+}
+
+func TestLoad(t *testing.T) {
+	migrations, err := Load(testFiles)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, len(migrations))
+	assert.Equal(t, Migration{
+		Version: 1,
+		Name:    "items",
+		Up:      "CREATE TABLE Items (id TEXT);",
+		Down:    "DROP TABLE Items;",
+	}, migrations[0])
+	assert.Equal(t, "owners", migrations[1].Name)
+
+	_, err = Load(fstest.MapFS{
+		"0001_items.up.sql":   {Data: []byte("CREATE TABLE Items (id TEXT);")},
+		"0001_items.down.sql": {Data: []byte("DROP TABLE Items;")},
+		"0003_gap.up.sql":     {Data: []byte("SELECT 1;")},
+		"0003_gap.down.sql":   {Data: []byte("SELECT 1;")},
+	})
+	assert.NotEqual(t, nil, err)
+
+	_, err = Load(fstest.MapFS{
+		"0001_items.up.sql": {Data: []byte("CREATE TABLE Items (id TEXT);")},
+	})
+	assert.NotEqual(t, nil, err)
+}
+
+func TestMigrator_Migrate(t *testing.T) {
+	m := newTestMigrator(t, 2)
+	version, err := m.Version()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, version)
+
+	from, to, err := m.Migrate(0)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, from)
+	assert.Equal(t, 2, to)
+	_, err = m.db.Exec("INSERT INTO Items (id, owner) VALUES ('a', 'b')")
+	assert.Equal(t, nil, err)
+
+	from, to, err = m.Migrate(1)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, from)
+	assert.Equal(t, 1, to)
+	_, err = m.db.Exec("INSERT INTO Items (id, owner) VALUES ('a', 'b')")
+	assert.NotEqual(t, nil, err)
+
+	_, _, err = m.Migrate(3)
+	assert.NotEqual(t, nil, err)
+	version, err = m.Version()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 1, version)
+}
+
+func TestMigrator_MigrateFailed(t *testing.T) {
+	m := newTestMigrator(t, 3)
+
+	// the failed migration is rolled back, leaving the previous version
+	from, to, err := m.Migrate(3)
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, 0, from)
+	assert.Equal(t, 2, to)
+	version, err := m.Version()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, version)
+}
+
+func TestMigrator_Prepare(t *testing.T) {
+	// empty databases are created at the latest version, whatever is configured
+	m := newTestMigrator(t, 1)
+	assert.Equal(t, nil, m.Prepare(1))
+
+	// outdated databases are only upgraded up to the configured version
+	m.migrations = append(m.migrations, Migration{Version: 2, Name: "owners", Up: "ALTER TABLE Items ADD COLUMN owner TEXT;", Down: "SELECT 1;"})
+	err := m.Prepare(1)
+	assert.Equal(t, true, errors.Is(err, ErrSchemaOutdated))
+	assert.Equal(t, nil, m.Prepare(2))
+	version, err := m.Version()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, version)
+
+	// databases newer than the migrations are refused
+	m.migrations = m.migrations[:1]
+	err = m.Prepare(0)
+	assert.Equal(t, true, errors.Is(err, ErrSchemaNewer))
+	_, _, err = m.Migrate(1)
+	assert.Equal(t, true, errors.Is(err, ErrSchemaNewer))
+}
@@ -0,0 +1,47 @@
+package filemanager
+
+import (
+	"fsrv/src/database"
+	"path/filepath"
+)
+
+// DatabaseLocator is a ResourceLocator which stores resource ids in the
+// database, by path relative to a base directory. It works on file
+// systems without extended attribute support, but resources only follow
+// files which are moved or deleted by the file manager itself.
+type DatabaseLocator struct {
+	database database.DBInterface
+	baseDir  string
+}
+
+// NewDatabaseLocator creates a DatabaseLocator for paths under baseDir.
+func NewDatabaseLocator(db database.DBInterface, baseDir string) *DatabaseLocator {
+	return &DatabaseLocator{
+		database: db,
+		baseDir:  filepath.Clean(baseDir),
+	}
+}
+
+func (l *DatabaseLocator) Get(name string) (string, error) {
+	id, err := l.database.GetPathResourceID(relPath(l.baseDir, name))
+	if err == database.ErrResourcePathMissing {
+		return "", ErrNotAttached
+	}
+	return id, err
+}
+
+func (l *DatabaseLocator) Set(name, resourceID string) error {
+	return l.database.SetResourcePath(relPath(l.baseDir, name), resourceID)
+}
+
+func (l *DatabaseLocator) Remove(name string) error {
+	return l.database.DeleteResourcePath(relPath(l.baseDir, name))
+}
+
+func (l *DatabaseLocator) RemoveAll(name string) error {
+	return l.database.DeleteResourcePaths(relPath(l.baseDir, name))
+}
+
+func (l *DatabaseLocator) Move(oldName, newName string) error {
+	return l.database.MoveResourcePaths(relPath(l.baseDir, oldName), relPath(l.baseDir, newName))
+}
@@ -0,0 +1,15 @@
+package filemanager
+
+import (
+	"os"
+	"strconv"
+)
+
+// ETag returns a strong entity tag for a file, derived from
+// its modification time and size. Any write to the file made
+// through the file manager changes at least one of the two.
+func ETag(info os.FileInfo) string {
+	mtime := strconv.FormatInt(info.ModTime().UnixNano(), 16)
+	size := strconv.FormatInt(info.Size(), 16)
+	return `"` + mtime + "-" + size + `"`
+}
@@ -1,24 +1,96 @@
 package filemanager
 
 import (
+	"errors"
 	"fmt"
 	"fsrv/src/config"
+	"fsrv/src/database"
+	"fsrv/src/logging"
+	"fsrv/utils"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 type FileManager struct {
 	baseDir  string
 	maxDepth int
+	locks    *pathLocks
+
+	resources ResourceLocator
+
+	trash          bool
+	trashDir       string
+	trashRetention time.Duration
+	trashPurge     chan struct{}
 }
 
-// New creates a new FileManager.
-func New(cfg *config.FileManager) *FileManager {
-	return &FileManager{
-		baseDir:  cfg.Path,
-		maxDepth: cfg.MaxDepth,
+// New creates a new FileManager. The database is only
+// used if the resource locator is set to 'database'.
+func New(cfg *config.FileManager, db database.DBInterface) *FileManager {
+	baseDir := filepath.Clean(cfg.Path)
+	f := &FileManager{
+		baseDir:        baseDir,
+		maxDepth:       cfg.MaxDepth,
+		locks:          newPathLocks(),
+		resources:      XAttrLocator{},
+		trash:          cfg.Trash,
+		trashDir:       filepath.Join(baseDir, trashDirName),
+		trashRetention: cfg.TrashRetention,
+	}
+	if cfg.ResourceLocator == config.ResourceLocatorDatabase {
+		f.resources = NewDatabaseLocator(db, baseDir)
+	}
+
+	if f.trash && f.trashRetention > 0 {
+		f.trashPurge = utils.Executor(trashPurgeInterval, func() {
+			err := f.PurgeTrash()
+			if err != nil {
+				logging.Errorf("error purging trash: %v", err)
+			}
+		})
 	}
+	return f
+}
+
+// Close stops purging the trash, and removes the temporary files of
+// uploads which did not finish. It must only be called once no other
+// operations are in progress.
+func (f *FileManager) Close() error {
+	if f.trashPurge != nil {
+		f.trashPurge <- struct{}{}
+		f.trashPurge = nil
+	}
+	return f.removeTemp()
+}
+
+// removeTemp removes every temporary file under the base directory.
+func (f *FileManager) removeTemp() error {
+	return filepath.WalkDir(f.baseDir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || !IsTemp(d.Name()) {
+			return nil
+		}
+
+		err = os.Remove(name)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return nil
+	})
+}
+
+// BaseDir returns the base directory of the file manager.
+func (f *FileManager) BaseDir() string {
+	return f.baseDir
 }
 
 // CleanPath returns a path which is guaranteed to be at the level
@@ -33,6 +105,32 @@ func (f *FileManager) CleanPath(name string) string {
 	return cleaned
 }
 
+// RelPath returns the path of a file relative to the base directory of
+// the file manager, as it is seen by clients: slash-separated and with a
+// leading slash. The input must begin with the base path of the file manager.
+func (f *FileManager) RelPath(name string) string {
+	return relPath(f.baseDir, name)
+}
+
+func relPath(baseDir, name string) string {
+	rel, err := filepath.Rel(baseDir, name)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		panic(fmt.Sprintf("path '%s' is not within '%s'", name, baseDir))
+	}
+	return path.Clean("/" + filepath.ToSlash(rel))
+}
+
+// IsReserved returns whether a path is used by the file manager
+// itself, such as the trash or a temporary file being written, and
+// so must not be accessed by clients. The input must begin with the
+// base path of the file manager.
+func (f *FileManager) IsReserved(name string) bool {
+	if IsTemp(filepath.Base(name)) {
+		return true
+	}
+	return name == f.trashDir || strings.HasPrefix(name, f.trashDir+string(os.PathSeparator))
+}
+
 // CheckDepth takes a path and returns whether the depth of
 // the path, not including the base directory, is less than
 // the maximum depth allowed by the file manager. The input
@@ -3,21 +3,24 @@ package filemanager
 import (
 	"fsrv/src/config"
 	"github.com/go-playground/assert/v2"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestNew(t *testing.T) {
 	New(&config.FileManager{
 		Path:     "/home/fsrv",
 		MaxDepth: 5,
-	})
+	}, nil)
 }
 
 func TestFileSystem_Clean(t *testing.T) {
 	f := New(&config.FileManager{
 		Path:     "/home/fsrv",
 		MaxDepth: 5,
-	})
+	}, nil)
 
 	assert.Equal(t, f.CleanPath("/dir"), "/home/fsrv/dir")
 	assert.Equal(t, f.CleanPath("/dir/file"), "/home/fsrv/dir/file")
@@ -30,7 +33,7 @@ func TestFileSystem_CheckDepth(t *testing.T) {
 	f := New(&config.FileManager{
 		Path:     "/home/fsrv",
 		MaxDepth: 5,
-	})
+	}, nil)
 
 	assert.Equal(t, f.CheckDepth("/home/fsrv/one/two"), true)
 	assert.Equal(t, f.CheckDepth("/home/fsrv/one/two/three/four/five"), true)
@@ -39,3 +42,55 @@ func TestFileSystem_CheckDepth(t *testing.T) {
 	assert.Equal(t, f.CheckDepth("/home/fsrv/one/two/three/four/five/six"), false)
 	assert.Equal(t, f.CheckDepth("/home/fsrv/one/two/three/ignored/../four/five/ignored/../six"), false)
 }
+
+func TestFileManager_IsReserved(t *testing.T) {
+	f := New(&config.FileManager{
+		Path:     "/home/fsrv",
+		MaxDepth: 5,
+	}, nil)
+
+	assert.Equal(t, f.IsReserved(f.CleanPath("/.trash")), true)
+	assert.Equal(t, f.IsReserved(f.CleanPath("/.trash/item")), true)
+	assert.Equal(t, f.IsReserved(f.CleanPath("/dir/"+tempPrefix+"123")), true)
+	assert.Equal(t, f.IsReserved(f.CleanPath("/dir/file.txt")), false)
+	assert.Equal(t, f.IsReserved(f.CleanPath("/.trashed")), false)
+}
+
+func TestFileSystem_CleanRelative(t *testing.T) {
+	f := New(&config.FileManager{
+		Path:     "./files",
+		MaxDepth: 5,
+	}, nil)
+
+	assert.Equal(t, f.CleanPath("/dir/file"), "files/dir/file")
+	assert.Equal(t, f.CheckDepth(f.CleanPath("/one/two")), true)
+}
+
+func TestFileManager_Close(t *testing.T) {
+	dir := t.TempDir()
+	f := New(&config.FileManager{
+		Path:           dir,
+		MaxDepth:       5,
+		Trash:          true,
+		TrashRetention: time.Hour,
+	}, nil)
+
+	err := os.MkdirAll(filepath.Join(dir, "dir"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmp := filepath.Join(dir, "dir", tempPrefix+"123")
+	file := filepath.Join(dir, "dir", "file.txt")
+	for _, name := range []string{tmp, file} {
+		err = os.WriteFile(name, []byte("hello"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	assert.Equal(t, nil, f.Close())
+	_, err = os.Stat(tmp)
+	assert.Equal(t, true, os.IsNotExist(err))
+	_, err = os.Stat(file)
+	assert.Equal(t, nil, err)
+}
@@ -0,0 +1,49 @@
+package filemanager
+
+import "sync"
+
+// pathLocks provides a mutex for every path currently in use,
+// so writes to different files do not block each other.
+type pathLocks struct {
+	mutexes map[string]*pathMutex
+	mux     sync.Mutex
+}
+
+type pathMutex struct {
+	sync.Mutex
+	refs int
+}
+
+func newPathLocks() *pathLocks {
+	return &pathLocks{
+		mutexes: make(map[string]*pathMutex),
+	}
+}
+
+// Lock locks the mutex for a path.
+func (l *pathLocks) Lock(path string) {
+	l.mux.Lock()
+	m, ok := l.mutexes[path]
+	if !ok {
+		m = &pathMutex{}
+		l.mutexes[path] = m
+	}
+	m.refs++
+	l.mux.Unlock()
+
+	m.Lock()
+}
+
+// Unlock unlocks the mutex for a path, discarding
+// it if no other goroutine is waiting to lock it.
+func (l *pathLocks) Unlock(path string) {
+	l.mux.Lock()
+	m := l.mutexes[path]
+	m.refs--
+	if m.refs == 0 {
+		delete(l.mutexes, path)
+	}
+	l.mux.Unlock()
+
+	m.Unlock()
+}
@@ -0,0 +1,170 @@
+package filemanager
+
+import (
+	"errors"
+	"github.com/pkg/xattr"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+var (
+	ErrIsDirectory        = errors.New("the given path is a directory")
+	ErrPreconditionFailed = errors.New("the file does not match the given precondition")
+	ErrInvalidRange       = errors.New("the given range cannot be written to the file")
+	ErrShortWrite         = errors.New("the content is shorter than the given range")
+)
+
+// WriteMode represents the way a modification is applied to a file.
+type WriteMode int8
+
+const (
+	// WriteReplace replaces the contents of the file.
+	WriteReplace WriteMode = iota
+	// WriteAppend appends to the end of the file.
+	WriteAppend
+	// WriteAt overwrites the file starting at an offset,
+	// extending it if the write goes past the end.
+	WriteAt
+)
+
+// Modification describes a write to an existing file.
+type Modification struct {
+	// Mode is the way the write is applied to the file.
+	Mode WriteMode
+	// Offset is the position to write at, for WriteAt.
+	Offset int64
+	// Length is the exact number of bytes to write, for WriteAt.
+	Length int64
+	// Precondition, if set, must return true for the file
+	// as it is before the write, or ErrPreconditionFailed
+	// is returned and the file is left unchanged.
+	Precondition func(info os.FileInfo) bool
+}
+
+// Modify writes the contents of r to the existing regular file at
+// the given on-disk path, returning the file's updated info.
+//
+// Modifications to the same file are serialized, so a precondition
+// on the file's ETag guarantees the file has not changed since the
+// client last saw it. Replacements are written to a temporary file
+// which is moved into place, so a partial replacement is never visible.
+func (f *FileManager) Modify(name string, r io.Reader, mod *Modification) (os.FileInfo, error) {
+	f.locks.Lock(name)
+	defer f.locks.Unlock(name)
+
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, ErrIsDirectory
+	}
+	if !info.Mode().IsRegular() {
+		return nil, os.ErrNotExist
+	}
+	if mod.Precondition != nil && !mod.Precondition(info) {
+		return nil, ErrPreconditionFailed
+	}
+
+	switch mod.Mode {
+	case WriteReplace:
+		err = f.replace(name, info, r)
+	case WriteAppend:
+		err = writeFile(name, os.O_APPEND, 0, r, -1)
+	case WriteAt:
+		if mod.Offset < 0 || mod.Length < 0 || mod.Offset > info.Size() {
+			return nil, ErrInvalidRange
+		}
+		err = writeFile(name, 0, mod.Offset, r, mod.Length)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return os.Stat(name)
+}
+
+// replace atomically replaces the contents of a file, keeping its
+// permissions and the extended attributes used by the file server.
+func (f *FileManager) replace(name string, info os.FileInfo, r io.Reader) error {
+	tmp, _, err := f.writeTemp(filepath.Dir(name), r)
+	if err != nil {
+		return err
+	}
+
+	err = os.Chmod(tmp, info.Mode().Perm())
+	if err == nil {
+		err = copyXAttributes(name, tmp)
+	}
+	if err == nil {
+		err = os.Rename(tmp, name)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// writeFile writes the contents of r to an existing file at an offset.
+// If length is not negative, exactly that many bytes must be written.
+func writeFile(name string, flag int, offset int64, r io.Reader, length int64) error {
+	file, err := os.OpenFile(name, os.O_WRONLY|flag, 0)
+	if err != nil {
+		return err
+	}
+
+	if offset > 0 {
+		_, err = file.Seek(offset, io.SeekStart)
+	}
+	if err == nil {
+		if length < 0 {
+			_, err = io.Copy(file, r)
+		} else {
+			_, err = io.CopyN(file, r, length)
+			if err == io.EOF {
+				err = ErrShortWrite
+			}
+		}
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// copyXAttributes copies the extended attributes in the
+// file server's namespace from one file to another.
+func copyXAttributes(from, to string) error {
+	names, err := xattr.List(from)
+	if err != nil {
+		if errors.Is(err, syscall.ENOTSUP) {
+			return nil
+		}
+		return err
+	}
+
+	for _, name := range names {
+		if !strings.HasPrefix(name, xAttributeNS) {
+			continue
+		}
+
+		value, err := xattr.Get(from, name)
+		if err != nil {
+			return err
+		}
+		err = xattr.Set(to, name, value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package filemanager
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+)
+
+var ErrNotAttached = errors.New("no resource is attached to the path")
+
+// ResourceLocator stores which resource, if any, is attached directly
+// to each file or directory. All paths are on-disk paths, as returned
+// by CleanPath.
+type ResourceLocator interface {
+	// Get returns the id of the resource attached to a path,
+	// or ErrNotAttached if there is none.
+	Get(path string) (string, error)
+	// Set attaches a resource to a path, replacing any already attached.
+	Set(path, resourceID string) error
+	// Remove detaches the resource attached to a path.
+	Remove(path string) error
+	// RemoveAll is called after a file or directory has been deleted,
+	// to detach the resources attached to it and its descendants.
+	RemoveAll(path string) error
+	// Move is called after a file or directory has been moved, so
+	// that the resources attached to it and its descendants follow it.
+	Move(oldPath, newPath string) error
+}
+
+// ResourceID returns the id of the resource attached directly to a
+// path, or ErrNotAttached if there is none. The path must be the
+// on-disk path, as returned by CleanPath.
+func (f *FileManager) ResourceID(path string) (string, error) {
+	return f.resources.Get(path)
+}
+
+// LockPath locks an on-disk path against other operations which lock
+// it, such as attaching a resource or modifying the file, until the
+// returned function is called.
+func (f *FileManager) LockPath(path string) (unlock func()) {
+	f.locks.Lock(path)
+	return func() {
+		f.locks.Unlock(path)
+	}
+}
+
+// AttachResource attaches a resource to an on-disk path.
+func (f *FileManager) AttachResource(path, resourceID string) error {
+	return f.resources.Set(path, resourceID)
+}
+
+// DetachResource detaches the resource attached directly to an on-disk path.
+func (f *FileManager) DetachResource(path string) error {
+	return f.resources.Remove(path)
+}
+
+// CopyResources attaches the resource attached to every path under
+// root by src, including root itself, to the same path in dst. It
+// returns the number of paths which were copied.
+func CopyResources(root string, src, dst ResourceLocator) (int, error) {
+	copied := 0
+	err := filepath.WalkDir(root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if IsTemp(d.Name()) {
+			return nil
+		}
+
+		id, err := src.Get(name)
+		if err == ErrNotAttached {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		err = dst.Set(name, id)
+		if err != nil {
+			return err
+		}
+		copied++
+		return nil
+	})
+	return copied, err
+}
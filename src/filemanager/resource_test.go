@@ -0,0 +1,135 @@
+package filemanager
+
+import (
+	"fsrv/src/config"
+	"fsrv/src/database"
+	"github.com/go-playground/assert/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// pathDB is a database which only stores resource paths.
+type pathDB struct {
+	database.DBInterface
+	paths map[string]string
+}
+
+func (db *pathDB) SetResourcePath(path string, resourceID string) error {
+	db.paths[path] = resourceID
+	return nil
+}
+
+func (db *pathDB) GetPathResourceID(path string) (string, error) {
+	id, ok := db.paths[path]
+	if !ok {
+		return "", database.ErrResourcePathMissing
+	}
+	return id, nil
+}
+
+func (db *pathDB) MoveResourcePaths(oldPath string, newPath string) error {
+	for path, id := range db.paths {
+		if path == oldPath || strings.HasPrefix(path, oldPath+"/") {
+			delete(db.paths, path)
+			db.paths[newPath+strings.TrimPrefix(path, oldPath)] = id
+		}
+	}
+	return nil
+}
+
+func (db *pathDB) DeleteResourcePath(path string) error {
+	delete(db.paths, path)
+	return nil
+}
+
+func (db *pathDB) DeleteResourcePaths(path string) error {
+	for p := range db.paths {
+		if p == path || strings.HasPrefix(p, path+"/") {
+			delete(db.paths, p)
+		}
+	}
+	return nil
+}
+
+func newLocatorTestFileManager(t *testing.T, locator config.ResourceLocatorType) (*FileManager, string) {
+	dir := t.TempDir()
+	err := os.MkdirAll(filepath.Join(dir, "dir", "sub"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(dir, "dir", "sub", "file.txt"), []byte("hello"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := New(&config.FileManager{
+		Path:            dir,
+		MaxDepth:        5,
+		Trash:           true,
+		ResourceLocator: locator,
+	}, &pathDB{paths: make(map[string]string)})
+	return f, dir
+}
+
+func TestFileManager_ResourceLocator(t *testing.T) {
+	for _, locator := range []config.ResourceLocatorType{config.ResourceLocatorXAttr, config.ResourceLocatorDatabase} {
+		t.Run(string(locator), func(t *testing.T) {
+			f, dir := newLocatorTestFileManager(t, locator)
+			name := filepath.Join(dir, "dir")
+			file := filepath.Join(dir, "dir", "sub", "file.txt")
+
+			_, err := f.ResourceID(name)
+			assert.Equal(t, ErrNotAttached, err)
+			_, err = f.ResourceID(filepath.Join(dir, "missing"))
+			assert.Equal(t, ErrNotAttached, err)
+
+			err = f.AttachResource(name, "dir")
+			if err != nil {
+				t.Skip("extended attributes are not supported:", err)
+			}
+			assert.Equal(t, nil, f.AttachResource(file, "file"))
+
+			id, err := f.ResourceID(name)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, "dir", id)
+
+			// resources follow files into and out of the trash
+			item, err := f.Delete(name, true)
+			assert.Equal(t, nil, err)
+			_, err = f.ResourceID(name)
+			assert.Equal(t, ErrNotAttached, err)
+
+			_, err = f.Restore(item.ID)
+			assert.Equal(t, nil, err)
+			id, err = f.ResourceID(file)
+			assert.Equal(t, nil, err)
+			assert.Equal(t, "file", id)
+
+			assert.Equal(t, nil, f.DetachResource(name))
+			_, err = f.ResourceID(name)
+			assert.Equal(t, ErrNotAttached, err)
+			id, _ = f.ResourceID(file)
+			assert.Equal(t, "file", id)
+		})
+	}
+}
+
+func TestCopyResources(t *testing.T) {
+	f, dir := newLocatorTestFileManager(t, config.ResourceLocatorDatabase)
+	src := XAttrLocator{}
+	err := src.Set(filepath.Join(dir, "dir"), "dir")
+	if err != nil {
+		t.Skip("extended attributes are not supported:", err)
+	}
+	assert.Equal(t, nil, src.Set(filepath.Join(dir, "dir", "sub", "file.txt"), "file"))
+
+	n, err := CopyResources(dir, src, f.resources)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, n)
+
+	id, err := f.ResourceID(filepath.Join(dir, "dir", "sub", "file.txt"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "file", id)
+}
@@ -0,0 +1,218 @@
+package filemanager
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fsrv/utils/serde"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+var ErrTrashItemMissing = errors.New("the specified trash item does not exist")
+
+// trashDirName is the name of the directory, under the base
+// directory, which holds deleted files and directories.
+const trashDirName = ".trash"
+
+const trashPurgeInterval = time.Hour
+
+const (
+	trashItemData = "data"
+	trashItemInfo = "info.json"
+)
+
+// TrashItem represents a deleted file or directory held in the trash.
+type TrashItem struct {
+	// ID is the id of this item in the trash.
+	ID string `json:"id"`
+	// Path is the path the item was deleted from, relative to the base directory.
+	Path string `json:"path"`
+	// DeletedAt is the time when this item was deleted.
+	DeletedAt serde.Time `json:"deleted_at"`
+}
+
+// Delete deletes the file or directory at the given on-disk path.
+// Directories are only deleted, along with their contents, if
+// recursive is true. If the trash is enabled, the item is moved
+// into it and the returned trash item may be used to restore it.
+func (f *FileManager) Delete(name string, recursive bool) (*TrashItem, error) {
+	f.locks.Lock(name)
+	defer f.locks.Unlock(name)
+
+	info, err := os.Lstat(name)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() && !recursive {
+		return nil, ErrIsDirectory
+	}
+
+	if !f.trash {
+		err = os.RemoveAll(name)
+		if err != nil {
+			return nil, err
+		}
+		return nil, f.resources.RemoveAll(name)
+	}
+	return f.moveToTrash(name)
+}
+
+// moveToTrash moves a file or directory into a new trash item.
+func (f *FileManager) moveToTrash(name string) (*TrashItem, error) {
+	item := &TrashItem{
+		ID:        newTrashID(),
+		Path:      f.RelPath(name),
+		DeletedAt: serde.Time(time.Now()),
+	}
+
+	itemDir := filepath.Join(f.trashDir, item.ID)
+	err := os.MkdirAll(itemDir, 0700)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := json.Marshal(item)
+	if err == nil {
+		err = os.WriteFile(filepath.Join(itemDir, trashItemInfo), info, 0600)
+	}
+	data := filepath.Join(itemDir, trashItemData)
+	if err == nil {
+		err = os.Rename(name, data)
+	}
+	if err == nil {
+		err = f.resources.Move(name, data)
+		if err != nil {
+			_ = os.Rename(data, name)
+		}
+	}
+	if err != nil {
+		_ = os.RemoveAll(itemDir)
+		return nil, err
+	}
+	return item, nil
+}
+
+// Restore moves a trash item back to the path it was deleted from.
+// The parent directories of the path are recreated if necessary, and
+// ErrExists is returned if something else now exists at the path.
+func (f *FileManager) Restore(id string) (*TrashItem, error) {
+	item, err := f.TrashItem(id)
+	if err != nil {
+		return nil, err
+	}
+
+	name := f.CleanPath(filepath.FromSlash(item.Path))
+	f.locks.Lock(name)
+	defer f.locks.Unlock(name)
+
+	_, err = os.Lstat(name)
+	if err == nil {
+		return nil, ErrExists
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	err = f.mkdirs(filepath.Dir(name))
+	if err != nil {
+		return nil, err
+	}
+
+	itemDir := filepath.Join(f.trashDir, item.ID)
+	data := filepath.Join(itemDir, trashItemData)
+	err = os.Rename(data, name)
+	if err != nil {
+		return nil, err
+	}
+	err = f.resources.Move(data, name)
+	if err != nil {
+		_ = os.Rename(name, data)
+		return nil, err
+	}
+	return item, os.RemoveAll(itemDir)
+}
+
+// TrashItem returns the trash item with the given id.
+func (f *FileManager) TrashItem(id string) (*TrashItem, error) {
+	if !f.trash || !isTrashID(id) {
+		return nil, ErrTrashItemMissing
+	}
+
+	data, err := os.ReadFile(filepath.Join(f.trashDir, id, trashItemInfo))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrTrashItemMissing
+		}
+		return nil, err
+	}
+
+	var item TrashItem
+	err = json.Unmarshal(data, &item)
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// PurgeTrash permanently removes the trash items which
+// have been in the trash for longer than the retention period.
+func (f *FileManager) PurgeTrash() error {
+	entries, err := os.ReadDir(f.trashDir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	for _, entry := range entries {
+		item, err := f.TrashItem(entry.Name())
+		if err != nil {
+			if err == ErrTrashItemMissing {
+				// not a trash item, or partially removed
+				continue
+			}
+			return err
+		}
+
+		if time.Since(time.Time(item.DeletedAt)) > f.trashRetention {
+			itemDir := filepath.Join(f.trashDir, item.ID)
+			err = os.RemoveAll(itemDir)
+			if err != nil {
+				return err
+			}
+			err = f.resources.RemoveAll(itemDir)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// newTrashID returns a unique id for a trash item, which
+// sorts in the order the items were deleted.
+func newTrashID() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return strconv.FormatInt(time.Now().UnixNano(), 36) + "-" + hex.EncodeToString(b)
+}
+
+// isTrashID returns whether an id could have been returned by
+// newTrashID, so that it is safe to use as part of a path.
+func isTrashID(id string) bool {
+	if id == "" || len(id) > 32 {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,117 @@
+package filemanager
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+var (
+	ErrExists       = errors.New("a file already exists at the given path")
+	ErrNotDirectory = errors.New("a parent of the given path is not a directory")
+	ErrMaxDepth     = errors.New("the maximum directory depth would be exceeded")
+)
+
+// tempPrefix is the name prefix of the temporary files
+// that are written to before being moved into place.
+const tempPrefix = ".fsrv-upload-"
+
+const (
+	fileMode = 0644
+	dirMode  = 0755
+)
+
+// IsTemp returns whether a file name belongs to a temporary file.
+func IsTemp(name string) bool {
+	return strings.HasPrefix(name, tempPrefix)
+}
+
+// Create writes the contents of r to a new file at the given
+// on-disk path, returning the number of bytes written. Missing
+// parent directories are created if the maximum depth allows it.
+//
+// The contents are written to a temporary file which is then
+// moved into place, so a partially written file is never visible.
+// If a file already exists at the path, ErrExists is returned.
+func (f *FileManager) Create(name string, r io.Reader) (int64, error) {
+	dir := filepath.Dir(name)
+	err := f.mkdirs(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	tmp, n, err := f.writeTemp(dir, r)
+	if err != nil {
+		return n, err
+	}
+	defer os.Remove(tmp)
+
+	// unlike rename, link fails if the destination already exists.
+	err = os.Link(tmp, name)
+	if err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return n, ErrExists
+		}
+		return n, err
+	}
+	return n, nil
+}
+
+// mkdirs ensures a directory exists, creating it and any missing
+// parents if its depth is within the maximum depth allowed.
+func (f *FileManager) mkdirs(dir string) error {
+	info, err := os.Stat(dir)
+	if err == nil {
+		if !info.IsDir() {
+			return ErrNotDirectory
+		}
+		return nil
+	}
+	if errors.Is(err, syscall.ENOTDIR) {
+		return ErrNotDirectory
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	if !f.CheckDepth(dir) {
+		return ErrMaxDepth
+	}
+
+	err = os.MkdirAll(dir, dirMode)
+	if errors.Is(err, syscall.ENOTDIR) {
+		return ErrNotDirectory
+	}
+	return err
+}
+
+// writeTemp writes the contents of r to a new temporary file
+// in the given directory, returning the name of the file.
+func (f *FileManager) writeTemp(dir string, r io.Reader) (string, int64, error) {
+	tmp, err := os.CreateTemp(dir, tempPrefix+"*")
+	if err != nil {
+		return "", 0, err
+	}
+
+	n, err := io.Copy(tmp, r)
+	if err == nil {
+		err = tmp.Chmod(fileMode)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	closeErr := tmp.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", n, err
+	}
+
+	return tmp.Name(), n, nil
+}
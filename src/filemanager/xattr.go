@@ -0,0 +1,58 @@
+package filemanager
+
+import (
+	"errors"
+	"github.com/pkg/xattr"
+	"syscall"
+)
+
+const xAttributeNS = "user.fsrv."
+const xAttributeResource = xAttributeNS + "resourceid"
+
+// XAttrLocator is a ResourceLocator which stores resource ids in an
+// extended attribute of each file, so that they follow the file
+// when it is moved or deleted.
+type XAttrLocator struct{}
+
+func (XAttrLocator) Get(path string) (string, error) {
+	id, err := xattr.Get(path, xAttributeResource)
+	if err != nil {
+		if isMissingXAttribute(err) {
+			return "", ErrNotAttached
+		}
+		return "", err
+	}
+	return string(id), nil
+}
+
+func (XAttrLocator) Set(path, resourceID string) error {
+	return xattr.Set(path, xAttributeResource, []byte(resourceID))
+}
+
+func (XAttrLocator) Remove(path string) error {
+	err := xattr.Remove(path, xAttributeResource)
+	if err != nil && isMissingXAttribute(err) {
+		return nil
+	}
+	return err
+}
+
+func (XAttrLocator) RemoveAll(string) error {
+	// the attributes were deleted along with the files.
+	return nil
+}
+
+func (XAttrLocator) Move(string, string) error {
+	// the attributes were moved along with the files.
+	return nil
+}
+
+// isMissingXAttribute returns whether an error returned by the
+// xattr package means that the attribute or file does not exist,
+// or that the file system does not support extended attributes.
+func isMissingXAttribute(err error) bool {
+	return errors.Is(err, xattr.ENOATTR) ||
+		errors.Is(err, syscall.ENOENT) ||
+		errors.Is(err, syscall.ENOTDIR) ||
+		errors.Is(err, syscall.ENOTSUP)
+}
@@ -0,0 +1,195 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fileTimeLayout is the layout of {time} in log file names.
+const fileTimeLayout = "2006-01-02T15-04-05.000"
+
+// rotations is the number of files the maximum size of the logs is
+// split across, so that when the oldest file is removed, most of the
+// recent records are kept.
+const rotations = 4
+
+var sizeUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"k":   1 << 10,
+	"kb":  1000,
+	"kib": 1 << 10,
+	"m":   1 << 20,
+	"mb":  1000 * 1000,
+	"mib": 1 << 20,
+	"g":   1 << 30,
+	"gb":  1000 * 1000 * 1000,
+	"gib": 1 << 30,
+	"t":   1 << 40,
+	"tb":  1000 * 1000 * 1000 * 1000,
+	"tib": 1 << 40,
+}
+
+// ParseSize parses a size such as '0', '100 MB', '50G' or '1GiB'.
+// Units ending in 'B' other than 'iB' are decimal, and the rest are
+// binary. The unit is case-insensitive.
+func ParseSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+
+	n, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size '%s'", s)
+	}
+	unit, ok := sizeUnits[strings.ToLower(strings.TrimSpace(s[i:]))]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit in size '%s'", s)
+	}
+	return int64(n * float64(unit)), nil
+}
+
+// RotatingFile writes to log files named by a template, in which {time}
+// is replaced with the time the file was created, and {pid} with the process
+// id. Once a file reaches a share of the maximum size, a new file is created,
+// and the oldest files named by the template are removed until the total size
+// is within the maximum. A maximum size of 0 is unlimited.
+type RotatingFile struct {
+	template string
+	maxSize  int64
+
+	mux  sync.Mutex
+	file *os.File
+	size int64
+}
+
+// OpenRotatingFile creates the first log file for a template,
+// including its directory.
+func OpenRotatingFile(template string, maxSize int64) (*RotatingFile, error) {
+	f := &RotatingFile{template: template, maxSize: maxSize}
+	err := f.rotate()
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize/rotations {
+		err := f.rotate()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes the current log file.
+func (f *RotatingFile) Close() error {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	if f.file == nil {
+		return os.ErrClosed
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// rotate closes the current file, opens a new file, and removes
+// the oldest files if they exceed the maximum size.
+func (f *RotatingFile) rotate() error {
+	name := strings.NewReplacer(
+		"{time}", time.Now().Format(fileTimeLayout),
+		"{pid}", strconv.Itoa(os.Getpid()),
+	).Replace(f.template)
+
+	err := os.MkdirAll(filepath.Dir(name), 0755)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	if f.file != nil {
+		_ = f.file.Close()
+	}
+	f.file, f.size = file, info.Size()
+	return f.removeOldest(name)
+}
+
+// removeOldest removes the oldest files named by the template, other than
+// the current file, while the total size of the files would exceed the
+// maximum once the current file is full.
+func (f *RotatingFile) removeOldest(current string) error {
+	if f.maxSize <= 0 {
+		return nil
+	}
+	limit := f.maxSize - f.maxSize/rotations
+
+	pattern := strings.NewReplacer("{time}", "*", "{pid}", "*").Replace(f.template)
+	names, err := filepath.Glob(pattern)
+	if err != nil {
+		return err
+	}
+
+	type logFile struct {
+		name string
+		info os.FileInfo
+	}
+	var files []logFile
+	var total int64
+	for _, name := range names {
+		if name == current {
+			continue
+		}
+		info, err := os.Stat(name)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		files = append(files, logFile{name, info})
+		total += info.Size()
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].info.ModTime().Before(files[j].info.ModTime())
+	})
+	for _, file := range files {
+		if total <= limit {
+			break
+		}
+		err = os.Remove(file.name)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		total -= file.info.Size()
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package logging
+
+import (
+	"github.com/go-playground/assert/v2"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := map[string]int64{
+		"0":      0,
+		"512":    512,
+		"100 MB": 100 * 1000 * 1000,
+		"50G":    50 << 30,
+		"1GiB":   1 << 30,
+		"1.5kib": 1536,
+	}
+	for s, size := range tests {
+		parsed, err := ParseSize(s)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, size, parsed)
+	}
+
+	for _, bad := range []string{"", "MB", "-1", "5 parsecs"} {
+		_, err := ParseSize(bad)
+		assert.NotEqual(t, nil, err)
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	dir := t.TempDir()
+	template := filepath.Join(dir, "logs", "{time}-{pid}.txt")
+
+	// each file holds a quarter of the maximum size
+	f, err := OpenRotatingFile(template, 400)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	line := make([]byte, 100)
+	for i := 0; i < 12; i++ {
+		n, err := f.Write(line)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, len(line), n)
+
+		// ensure file names differ by time
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, "logs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var total int64
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, int64(100), info.Size())
+		total += info.Size()
+	}
+	assert.Equal(t, int64(400), total)
+
+	assert.Equal(t, nil, f.Close())
+	_, err = f.Write(line)
+	assert.Equal(t, os.ErrClosed, err)
+}
@@ -0,0 +1,190 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JSONFormat is the format which writes each record as a line of JSON.
+const JSONFormat = "json"
+
+// DefaultFormat is the format used when none is configured.
+const DefaultFormat = "%{time:2006-01-02 15:04:05.000} %{level:-8s} %{request}» %{message}"
+
+// defaultTimeLayout is the layout of %{time} if none is given.
+const defaultTimeLayout = "2006-01-02T15:04:05.999Z07:00"
+
+// Formatter formats a record as a line of output.
+type Formatter func(rec *Record) []byte
+
+// levelColors are the ansi colors of each level.
+var levelColors = []string{
+	DEBUG:    "\033[36m",
+	INFO:     "\033[37m",
+	NOTICE:   "\033[32m",
+	WARNING:  "\033[33m",
+	ERROR:    "\033[31m",
+	CRITICAL: "\033[35m",
+}
+
+const colorReset = "\033[0m"
+
+// ParseFormat parses a format, which is either JSONFormat, or a template
+// in the style of github.com/op/go-logging, made of text and verbs such as
+// %{level} or %{time:15:04:05}. The text after the colon of a verb is a
+// time layout for %{time}, and a fmt verb (with or without its letter) for
+// the rest, such as %{level:-8s} or %{id:03x}. The verbs are:
+//
+//	time      the time of the record
+//	id        the sequence number of the record
+//	level     the level of the record
+//	message   the message of the record
+//	request   the request id followed by a space, if there is one
+//	longfile  the full path and line number of the source
+//	shortfile the file name and line number of the source
+//	pid       the process id
+//	color     the color of the level, or reset with %{color:reset}
+//
+// Colors are only written if color is true.
+func ParseFormat(format string, color bool) (Formatter, error) {
+	if format == JSONFormat {
+		return formatJSON, nil
+	}
+
+	var parts []func(b []byte, rec *Record) []byte
+	for len(format) > 0 {
+		i := strings.Index(format, "%{")
+		if i < 0 {
+			text := format
+			parts = append(parts, func(b []byte, _ *Record) []byte { return append(b, text...) })
+			break
+		}
+		if i > 0 {
+			text := format[:i]
+			parts = append(parts, func(b []byte, _ *Record) []byte { return append(b, text...) })
+		}
+
+		end := strings.Index(format[i:], "}")
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated verb in log format '%s'", format)
+		}
+		verb, spec := format[i+2:i+end], ""
+		if j := strings.Index(verb, ":"); j >= 0 {
+			verb, spec = verb[:j], verb[j+1:]
+		}
+		part, err := parseVerb(verb, spec, color)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+		format = format[i+end+1:]
+	}
+
+	return func(rec *Record) []byte {
+		var b []byte
+		for _, part := range parts {
+			b = part(b, rec)
+		}
+		return append(b, '\n')
+	}, nil
+}
+
+func parseVerb(verb, spec string, color bool) (func(b []byte, rec *Record) []byte, error) {
+	switch verb {
+	case "time":
+		layout := spec
+		if layout == "" {
+			layout = defaultTimeLayout
+		}
+		return func(b []byte, rec *Record) []byte { return rec.Time.AppendFormat(b, layout) }, nil
+	case "id":
+		f := fmtSpec(spec, 'd')
+		return func(b []byte, rec *Record) []byte { return append(b, fmt.Sprintf(f, rec.ID)...) }, nil
+	case "level":
+		f := fmtSpec(spec, 's')
+		return func(b []byte, rec *Record) []byte { return append(b, fmt.Sprintf(f, rec.Level.String())...) }, nil
+	case "message":
+		f := fmtSpec(spec, 's')
+		return func(b []byte, rec *Record) []byte { return append(b, fmt.Sprintf(f, rec.Message)...) }, nil
+	case "request":
+		return func(b []byte, rec *Record) []byte {
+			if rec.RequestID == "" {
+				return b
+			}
+			return append(append(b, rec.RequestID...), ' ')
+		}, nil
+	case "longfile":
+		return func(b []byte, rec *Record) []byte {
+			return strconv.AppendInt(append(append(b, rec.File...), ':'), int64(rec.Line), 10)
+		}, nil
+	case "shortfile":
+		return func(b []byte, rec *Record) []byte {
+			return strconv.AppendInt(append(append(b, filepath.Base(rec.File)...), ':'), int64(rec.Line), 10)
+		}, nil
+	case "pid":
+		pid := strconv.Itoa(os.Getpid())
+		return func(b []byte, _ *Record) []byte { return append(b, pid...) }, nil
+	case "color":
+		if spec != "" && spec != "reset" {
+			return nil, fmt.Errorf("unknown color '%s' in log format", spec)
+		}
+		if !color {
+			return func(b []byte, _ *Record) []byte { return b }, nil
+		}
+		if spec == "reset" {
+			return func(b []byte, _ *Record) []byte { return append(b, colorReset...) }, nil
+		}
+		return func(b []byte, rec *Record) []byte {
+			if rec.Level < DEBUG || rec.Level > CRITICAL {
+				return b
+			}
+			return append(b, levelColors[rec.Level]...)
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown verb '%s' in log format", verb)
+	}
+}
+
+// fmtSpec returns a fmt verb from the spec of a format verb,
+// adding the default letter if the spec does not end with one.
+func fmtSpec(spec string, letter byte) string {
+	if spec == "" {
+		return "%" + string(letter)
+	}
+	last := spec[len(spec)-1]
+	if (last >= 'a' && last <= 'z') || (last >= 'A' && last <= 'Z') {
+		return "%" + spec
+	}
+	return "%" + spec + string(letter)
+}
+
+type jsonRecord struct {
+	Time      time.Time `json:"time"`
+	ID        uint64    `json:"id"`
+	Level     string    `json:"level"`
+	File      string    `json:"file"`
+	Line      int       `json:"line"`
+	RequestID string    `json:"request_id,omitempty"`
+	Message   string    `json:"message"`
+}
+
+func formatJSON(rec *Record) []byte {
+	b, err := json.Marshal(&jsonRecord{
+		Time:      rec.Time,
+		ID:        rec.ID,
+		Level:     rec.Level.String(),
+		File:      rec.File,
+		Line:      rec.Line,
+		RequestID: rec.RequestID,
+		Message:   rec.Message,
+	})
+	if err != nil {
+		return []byte(fmt.Sprintf("{\"message\":%q}\n", err.Error()))
+	}
+	return append(b, '\n')
+}
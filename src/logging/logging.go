@@ -0,0 +1,220 @@
+// Package logging writes leveled log records to stdout and to size
+// capped log files, in the formats configured by the [logging] config
+// section. Records may carry the id of the request they were logged for.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Level is the severity of a log record.
+type Level int
+
+const (
+	DEBUG Level = iota
+	INFO
+	NOTICE
+	WARNING
+	ERROR
+	CRITICAL
+)
+
+var levelNames = []string{"DEBUG", "INFO", "NOTICE", "WARNING", "ERROR", "CRITICAL"}
+
+func (l Level) String() string {
+	if l < DEBUG || l > CRITICAL {
+		return fmt.Sprintf("LEVEL(%d)", int(l))
+	}
+	return levelNames[l]
+}
+
+// ParseLevel parses the name of a level, ignoring case.
+func ParseLevel(name string) (Level, error) {
+	for i, levelName := range levelNames {
+		if strings.EqualFold(name, levelName) {
+			return Level(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level '%s'", name)
+}
+
+// Record is a single log entry.
+type Record struct {
+	// ID is the sequence number of the record.
+	ID uint64
+	// Time is when the record was logged.
+	Time time.Time
+	// Level is the severity of the record.
+	Level Level
+	// File and Line are the source location which logged the record.
+	File string
+	Line int
+	// RequestID is the id of the request the record was logged
+	// for, or empty if it was not logged for a request.
+	RequestID string
+	// Message is the formatted message.
+	Message string
+}
+
+// backend writes the records of at least its level to a writer.
+type backend struct {
+	level  Level
+	format Formatter
+	w      io.Writer
+}
+
+// core is shared by a logger and the loggers derived from it.
+type core struct {
+	mux      sync.Mutex
+	nextID   uint64
+	backends []*backend
+}
+
+// Logger logs records to its backends.
+type Logger struct {
+	core      *core
+	requestID string
+}
+
+// New creates a logger with no backends.
+func New() *Logger {
+	return &Logger{core: &core{}}
+}
+
+// AddBackend adds a backend which writes records of at least a
+// level to a writer, in a format. It must not be called while
+// the logger is in use.
+func (l *Logger) AddBackend(level Level, format Formatter, w io.Writer) {
+	l.core.backends = append(l.core.backends, &backend{level, format, w})
+}
+
+// WithRequestID returns a logger which shares the backends
+// of the logger, and adds a request id to its records.
+func (l *Logger) WithRequestID(id string) *Logger {
+	return &Logger{core: l.core, requestID: id}
+}
+
+// RequestID returns the request id added to records by
+// the logger, or an empty string if there is none.
+func (l *Logger) RequestID() string {
+	return l.requestID
+}
+
+func (l *Logger) log(level Level, format string, args []any) {
+	rec := &Record{
+		ID:        atomic.AddUint64(&l.core.nextID, 1),
+		Time:      time.Now(),
+		Level:     level,
+		RequestID: l.requestID,
+		Message:   fmt.Sprintf(format, args...),
+	}
+	// skip log and the exported method which called it
+	_, rec.File, rec.Line, _ = runtime.Caller(2)
+
+	l.core.mux.Lock()
+	defer l.core.mux.Unlock()
+	for _, b := range l.core.backends {
+		if level >= b.level {
+			_, _ = b.w.Write(b.format(rec))
+		}
+	}
+}
+
+func (l *Logger) Debugf(format string, args ...any) {
+	l.log(DEBUG, format, args)
+}
+
+func (l *Logger) Infof(format string, args ...any) {
+	l.log(INFO, format, args)
+}
+
+func (l *Logger) Noticef(format string, args ...any) {
+	l.log(NOTICE, format, args)
+}
+
+func (l *Logger) Warningf(format string, args ...any) {
+	l.log(WARNING, format, args)
+}
+
+func (l *Logger) Errorf(format string, args ...any) {
+	l.log(ERROR, format, args)
+}
+
+func (l *Logger) Criticalf(format string, args ...any) {
+	l.log(CRITICAL, format, args)
+}
+
+// Fatalf logs a critical record, then exits the program.
+func (l *Logger) Fatalf(format string, args ...any) {
+	l.log(CRITICAL, format, args)
+	os.Exit(1)
+}
+
+// Writer returns a writer which logs each write as a record of a
+// level, for use by libraries which log to a writer.
+func (l *Logger) Writer(level Level) io.Writer {
+	return &levelWriter{l, level}
+}
+
+type levelWriter struct {
+	logger *Logger
+	level  Level
+}
+
+func (w *levelWriter) Write(p []byte) (int, error) {
+	w.logger.log(w.level, "%s", []any{strings.TrimRight(string(p), "\n")})
+	return len(p), nil
+}
+
+// std is the default logger, which writes records of level
+// INFO and above to stderr until it is set up from the config.
+var std = defaultLogger()
+
+func defaultLogger() *Logger {
+	l := New()
+	format, _ := ParseFormat(DefaultFormat, false)
+	l.AddBackend(INFO, format, os.Stderr)
+	return l
+}
+
+// Default returns the default logger.
+func Default() *Logger {
+	return std
+}
+
+func Debugf(format string, args ...any) {
+	std.log(DEBUG, format, args)
+}
+
+func Infof(format string, args ...any) {
+	std.log(INFO, format, args)
+}
+
+func Noticef(format string, args ...any) {
+	std.log(NOTICE, format, args)
+}
+
+func Warningf(format string, args ...any) {
+	std.log(WARNING, format, args)
+}
+
+func Errorf(format string, args ...any) {
+	std.log(ERROR, format, args)
+}
+
+func Criticalf(format string, args ...any) {
+	std.log(CRITICAL, format, args)
+}
+
+// Fatalf logs a critical record to the default logger, then exits the program.
+func Fatalf(format string, args ...any) {
+	std.log(CRITICAL, format, args)
+	os.Exit(1)
+}
@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/go-playground/assert/v2"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLevel(t *testing.T) {
+	level, err := ParseLevel("warning")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, WARNING, level)
+	level, _ = ParseLevel("NOTICE")
+	assert.Equal(t, NOTICE, level)
+	_, err = ParseLevel("verbose")
+	assert.NotEqual(t, nil, err)
+}
+
+func TestParseFormat(t *testing.T) {
+	rec := &Record{
+		ID:        26,
+		Time:      time.Date(2022, 7, 1, 13, 4, 5, 6e6, time.UTC),
+		Level:     WARNING,
+		File:      "/src/fsrv/main.go",
+		Line:      12,
+		RequestID: "abc",
+		Message:   "hello",
+	}
+
+	tests := []struct {
+		format string
+		color  bool
+		output string
+	}{
+		{"%{time:15:04:05.000} %{id:03x} %{level:.04} » %{message}", false, "13:04:05.006 01a WARN » hello"},
+		{"%{level:-8s}|%{request}%{shortfile}", false, "WARNING |abc main.go:12"},
+		{"%{longfile} %{message:q}", false, "/src/fsrv/main.go:12 \"hello\""},
+		{"%{color}%{level}%{color:reset}", false, "WARNING"},
+		{"%{color}%{level}%{color:reset}", true, "\033[33mWARNING\033[0m"},
+		{"plain text", false, "plain text"},
+	}
+	for _, test := range tests {
+		format, err := ParseFormat(test.format, test.color)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, test.output+"\n", string(format(rec)))
+	}
+
+	for _, bad := range []string{"%{unknown}", "%{level", "%{color:blue}"} {
+		_, err := ParseFormat(bad, false)
+		assert.NotEqual(t, nil, err)
+	}
+}
+
+func TestLogger(t *testing.T) {
+	var text, jsonOut bytes.Buffer
+	textFormat, _ := ParseFormat("%{level} %{request}%{message}", false)
+	jsonFormat, _ := ParseFormat(JSONFormat, false)
+
+	logger := New()
+	logger.AddBackend(INFO, textFormat, &text)
+	logger.AddBackend(ERROR, jsonFormat, &jsonOut)
+
+	logger.Debugf("hidden")
+	logger.Infof("started %d", 1)
+	reqLogger := logger.WithRequestID("req1")
+	reqLogger.Errorf("failed: %v", "reason")
+	assert.Equal(t, "INFO started 1\nERROR req1 failed: reason\n", text.String())
+
+	var rec jsonRecord
+	err := json.Unmarshal(jsonOut.Bytes(), &rec)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "ERROR", rec.Level)
+	assert.Equal(t, "req1", rec.RequestID)
+	assert.Equal(t, "failed: reason", rec.Message)
+	// the debug record was not written, but was still numbered
+	assert.Equal(t, uint64(3), rec.ID)
+	assert.Equal(t, true, strings.HasSuffix(rec.File, "logging_test.go"))
+
+	text.Reset()
+	_, _ = logger.Writer(WARNING).Write([]byte("from a library\n"))
+	assert.Equal(t, "WARNING from a library\n", text.String())
+}
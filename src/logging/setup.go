@@ -0,0 +1,78 @@
+package logging
+
+import (
+	"fmt"
+	"fsrv/src/config"
+	"os"
+)
+
+// Setup replaces the default logger with one configured by the [logging]
+// config section, which writes to stdout and, if a file template is set,
+// to rotating log files. The returned function closes the log file. If the
+// config is nil, the default logger is kept.
+func Setup(cfg *config.Logging) (func() error, error) {
+	if cfg == nil {
+		return func() error { return nil }, nil
+	}
+	logger, closeFn, err := FromConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	std = logger
+	return closeFn, nil
+}
+
+// FromConfig creates a logger configured by the [logging] config section.
+// The returned function closes the log file.
+func FromConfig(cfg *config.Logging) (*Logger, func() error, error) {
+	logger := New()
+
+	level, format, err := parseBackend(cfg.StdoutLevel, cfg.StdoutFormat, true)
+	if err != nil {
+		return nil, nil, fmt.Errorf("stdout logging: %w", err)
+	}
+	logger.AddBackend(level, format, os.Stdout)
+
+	if cfg.File == "" {
+		return logger, func() error { return nil }, nil
+	}
+
+	level, format, err = parseBackend(cfg.FileLevel, cfg.FileFormat, false)
+	if err != nil {
+		return nil, nil, fmt.Errorf("file logging: %w", err)
+	}
+	var maxSize int64
+	if cfg.MaxFileSize != "" {
+		maxSize, err = ParseSize(cfg.MaxFileSize)
+		if err != nil {
+			return nil, nil, fmt.Errorf("file logging: %w", err)
+		}
+	}
+	file, err := OpenRotatingFile(cfg.File, maxSize)
+	if err != nil {
+		return nil, nil, fmt.Errorf("file logging: %w", err)
+	}
+	logger.AddBackend(level, format, file)
+	return logger, file.Close, nil
+}
+
+// parseBackend parses the level and format of a backend,
+// using INFO and DefaultFormat if they are empty.
+func parseBackend(levelName, formatStr string, color bool) (Level, Formatter, error) {
+	level := INFO
+	if levelName != "" {
+		var err error
+		level, err = ParseLevel(levelName)
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+	if formatStr == "" {
+		formatStr = DefaultFormat
+	}
+	format, err := ParseFormat(formatStr, color)
+	if err != nil {
+		return 0, nil, err
+	}
+	return level, format, nil
+}
@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+)
+
+// Default is the registry of the metrics of the server.
+var Default = NewRegistry()
+
+// DurationBuckets are the bucket bounds, in seconds,
+// of histograms which measure durations.
+var DurationBuckets = []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60}
+
+var (
+	// Requests counts handled requests by server, operation and status code.
+	Requests = Default.NewCounterVec("fsrv_http_requests_total",
+		"Requests handled, by server, operation and status code.", "server", "operation", "status")
+	// RequestDuration measures how long requests take to handle, by server and operation.
+	RequestDuration = Default.NewHistogramVec("fsrv_http_request_duration_seconds",
+		"Time taken to handle requests, by server and operation.", DurationBuckets, "server", "operation")
+	// RequestBytes counts the bytes read from request bodies, by server and operation.
+	RequestBytes = Default.NewCounterVec("fsrv_http_request_bytes_total",
+		"Bytes read from request bodies, by server and operation.", "server", "operation")
+	// ResponseBytes counts the bytes written to response bodies, by server and operation.
+	ResponseBytes = Default.NewCounterVec("fsrv_http_response_bytes_total",
+		"Bytes written to response bodies, by server and operation.", "server", "operation")
+
+	// RateLimitRejections counts requests rejected by UnifiedRateLimit, by the
+	// rate limit which was exceeded: anonymous, attempt, default or key.
+	RateLimitRejections = Default.NewCounterVec("fsrv_rate_limit_rejections_total",
+		"Requests rejected for exceeding a rate limit, by the rate limit: anonymous, attempt, default or key.", "reason")
+	// ConcurrentRequestRejections counts requests rejected by
+	// ConcurrentRequestLimit, by operation.
+	ConcurrentRequestRejections = Default.NewCounterVec("fsrv_concurrent_request_rejections_total",
+		"Requests rejected for exceeding the limit of concurrent requests, by operation.", "operation")
+
+	// CacheLookups counts lookups in the database caches, by
+	// cache and result: hit or miss.
+	CacheLookups = Default.NewCounterVec("fsrv_cache_lookups_total",
+		"Lookups in the database caches, by cache and result: hit or miss.", "cache", "result")
+	// DatabaseDuration measures how long database calls take, by method.
+	DatabaseDuration = Default.NewHistogramVec("fsrv_database_duration_seconds",
+		"Time taken by database calls, excluding the cache, by method.", DurationBuckets, "method")
+)
+
+// ObserveSince observes the seconds since a time in a histogram.
+func ObserveSince(h *Histogram, start time.Time) {
+	h.Observe(time.Since(start).Seconds())
+}
+
+// NewServer creates a server which serves the default
+// registry at /metrics, for use by Prometheus.
+func NewServer() *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Default.Handler())
+	return &http.Server{Handler: mux}
+}
@@ -0,0 +1,309 @@
+// Package metrics collects counters and histograms about the server, and
+// exposes them in the Prometheus text exposition format.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// collector is a metric family which can be written in the text format.
+type collector interface {
+	write(w *bufio.Writer)
+}
+
+// Registry holds metric families, and writes them in the order registered.
+type Registry struct {
+	mux        sync.Mutex
+	collectors []collector
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) register(c collector) {
+	r.mux.Lock()
+	r.collectors = append(r.collectors, c)
+	r.mux.Unlock()
+}
+
+// WriteTo writes every metric family in the text exposition format.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mux.Lock()
+	collectors := append([]collector(nil), r.collectors...)
+	r.mux.Unlock()
+
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	for _, c := range collectors {
+		c.write(bw)
+	}
+	err := bw.Flush()
+	return cw.n, err
+}
+
+// Handler serves the metric families of the registry.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_, _ = r.WriteTo(w)
+	})
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+// family is the name, help and labels shared by the series of a metric.
+type family struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+
+	mux    sync.RWMutex
+	series map[string]any
+}
+
+func newFamily(name, help, kind string, labels []string) family {
+	return family{name: name, help: help, kind: kind, labels: labels, series: map[string]any{}}
+}
+
+// get returns the series for label values, creating it if needed.
+func (f *family) get(values []string, create func() any) any {
+	if len(values) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s has %d labels, but %d values were given", f.name, len(f.labels), len(values)))
+	}
+	id := strings.Join(values, "\xff")
+
+	f.mux.RLock()
+	s, ok := f.series[id]
+	f.mux.RUnlock()
+	if ok {
+		return s
+	}
+
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	if s, ok = f.series[id]; !ok {
+		s = create()
+		f.series[id] = s
+	}
+	return s
+}
+
+// each calls fn with the label values and series of the family,
+// sorted by label values so that the output is stable.
+func (f *family) each(fn func(values []string, s any)) {
+	f.mux.RLock()
+	ids := make([]string, 0, len(f.series))
+	for id := range f.series {
+		ids = append(ids, id)
+	}
+	series := make(map[string]any, len(f.series))
+	for id, s := range f.series {
+		series[id] = s
+	}
+	f.mux.RUnlock()
+
+	sort.Strings(ids)
+	for _, id := range ids {
+		var values []string
+		if len(f.labels) > 0 {
+			values = strings.Split(id, "\xff")
+		}
+		fn(values, series[id])
+	}
+}
+
+func (f *family) writeHeader(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", f.name, strings.ReplaceAll(f.help, "\n", " "))
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.name, f.kind)
+}
+
+// writeSample writes a sample, with the label values of its series
+// followed by any extra label, such as the bucket of a histogram.
+func (f *family) writeSample(w *bufio.Writer, suffix string, values []string, extraName, extraValue string, value float64) {
+	w.WriteString(f.name)
+	w.WriteString(suffix)
+	if len(values) > 0 || extraName != "" {
+		w.WriteByte('{')
+		for i, v := range values {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			writeLabel(w, f.labels[i], v)
+		}
+		if extraName != "" {
+			if len(values) > 0 {
+				w.WriteByte(',')
+			}
+			writeLabel(w, extraName, extraValue)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(value))
+	w.WriteByte('\n')
+}
+
+func writeLabel(w *bufio.Writer, name, value string) {
+	w.WriteString(name)
+	w.WriteString(`="`)
+	w.WriteString(strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value))
+	w.WriteByte('"')
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// atomicFloat is a float64 which may be added to concurrently.
+type atomicFloat struct {
+	bits uint64
+}
+
+func (f *atomicFloat) Add(v float64) {
+	for {
+		old := atomic.LoadUint64(&f.bits)
+		sum := math.Float64bits(math.Float64frombits(old) + v)
+		if atomic.CompareAndSwapUint64(&f.bits, old, sum) {
+			return
+		}
+	}
+}
+
+func (f *atomicFloat) Load() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&f.bits))
+}
+
+// Counter is a value which only increases.
+type Counter struct {
+	value atomicFloat
+}
+
+// Inc adds one to the counter.
+func (c *Counter) Inc() {
+	c.value.Add(1)
+}
+
+// Add adds a non-negative value to the counter.
+func (c *Counter) Add(v float64) {
+	if v < 0 {
+		panic("metrics: counters cannot decrease")
+	}
+	c.value.Add(v)
+}
+
+// Value returns the value of the counter.
+func (c *Counter) Value() float64 {
+	return c.value.Load()
+}
+
+// CounterVec is a family of counters, partitioned by label values.
+type CounterVec struct {
+	family
+}
+
+// NewCounterVec creates a counter family and registers it.
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{newFamily(name, help, "counter", labels)}
+	r.register(c)
+	return c
+}
+
+// With returns the counter for label values, in the order of the labels.
+func (c *CounterVec) With(values ...string) *Counter {
+	return c.get(values, func() any { return &Counter{} }).(*Counter)
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	c.writeHeader(w)
+	c.each(func(values []string, s any) {
+		c.writeSample(w, "", values, "", "", s.(*Counter).Value())
+	})
+}
+
+// Histogram counts observations in cumulative buckets.
+type Histogram struct {
+	bounds []float64
+	counts []uint64
+	count  uint64
+	sum    atomicFloat
+}
+
+// Observe adds an observation to the histogram.
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.bounds, v)
+	if i < len(h.counts) {
+		atomic.AddUint64(&h.counts[i], 1)
+	}
+	atomic.AddUint64(&h.count, 1)
+	h.sum.Add(v)
+}
+
+// HistogramVec is a family of histograms, partitioned by label values.
+type HistogramVec struct {
+	family
+	bounds []float64
+}
+
+// NewHistogramVec creates a histogram family with the upper bounds of
+// its buckets, in increasing order, and registers it.
+func (r *Registry) NewHistogramVec(name, help string, bounds []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{newFamily(name, help, "histogram", labels), bounds}
+	r.register(h)
+	return h
+}
+
+// With returns the histogram for label values, in the order of the labels.
+func (h *HistogramVec) With(values ...string) *Histogram {
+	return h.get(values, func() any {
+		return &Histogram{bounds: h.bounds, counts: make([]uint64, len(h.bounds))}
+	}).(*Histogram)
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.writeHeader(w)
+	h.each(func(values []string, s any) {
+		hist := s.(*Histogram)
+		var cumulative uint64
+		for i, bound := range hist.bounds {
+			cumulative += atomic.LoadUint64(&hist.counts[i])
+			h.writeSample(w, "_bucket", values, "le", formatFloat(bound), float64(cumulative))
+		}
+		// observations made while writing may be in the buckets but not the count
+		count := atomic.LoadUint64(&hist.count)
+		if count < cumulative {
+			count = cumulative
+		}
+		h.writeSample(w, "_bucket", values, "le", "+Inf", float64(count))
+		h.writeSample(w, "_sum", values, "", "", hist.sum.Load())
+		h.writeSample(w, "_count", values, "", "", float64(count))
+	})
+}
@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"bytes"
+	"github.com/go-playground/assert/v2"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounterVec("test_requests_total", "Requests.", "operation", "status")
+	duration := r.NewHistogramVec("test_duration_seconds", "Durations.", []float64{0.1, 1}, "operation")
+	total := r.NewCounterVec("test_total", "Unlabelled.")
+
+	requests.With("write", "201").Inc()
+	requests.With("read", "200").Add(2)
+	requests.With("read", "200").Inc()
+	requests.With("read", `a"b`).Inc()
+	duration.With("read").Observe(0.05)
+	duration.With("read").Observe(0.5)
+	duration.With("read").Observe(3)
+	total.With().Inc()
+
+	var b bytes.Buffer
+	_, err := r.WriteTo(&b)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, `# HELP test_requests_total Requests.
+# TYPE test_requests_total counter
+test_requests_total{operation="read",status="200"} 3
+test_requests_total{operation="read",status="a\"b"} 1
+test_requests_total{operation="write",status="201"} 1
+# HELP test_duration_seconds Durations.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{operation="read",le="0.1"} 1
+test_duration_seconds_bucket{operation="read",le="1"} 2
+test_duration_seconds_bucket{operation="read",le="+Inf"} 3
+test_duration_seconds_sum{operation="read"} 3.55
+test_duration_seconds_count{operation="read"} 3
+# HELP test_total Unlabelled.
+# TYPE test_total counter
+test_total 1
+`, b.String())
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounterVec("test_total", "Test.", "label").With("value").Inc()
+
+	w := httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, true, strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain; version=0.0.4"))
+	assert.Equal(t, true, strings.Contains(w.Body.String(), `test_total{label="value"} 1`))
+}
+
+func TestCounter_Decrease(t *testing.T) {
+	defer func() {
+		assert.NotEqual(t, nil, recover())
+	}()
+	(&Counter{}).Add(-1)
+}
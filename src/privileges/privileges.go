@@ -0,0 +1,94 @@
+// Package privileges drops the privileges of the process to the
+// configured user, once the server has bound its sockets and
+// opened its files.
+package privileges
+
+import (
+	"errors"
+	"fmt"
+	"fsrv/src/config"
+	"os"
+	"os/user"
+	"strconv"
+)
+
+var ErrRunningAsRoot = errors.New("refusing to serve as root; set server.user to the user to change to, or server.allow_root to allow it")
+
+// Identity is a user id, with its primary and supplementary group ids.
+type Identity struct {
+	UID    int
+	GID    int
+	Groups []int
+}
+
+// Lookup finds the identity of a user by name or id. The primary group
+// is the user's own, unless a group is given by name or id. The
+// supplementary groups are those which the user is a member of.
+func Lookup(username, group string) (*Identity, error) {
+	u, err := user.Lookup(username)
+	if _, ok := err.(user.UnknownUserError); ok {
+		u, err = user.LookupId(username)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	gid := u.Gid
+	if group != "" {
+		g, err := user.LookupGroup(group)
+		if _, ok := err.(user.UnknownGroupError); ok {
+			g, err = user.LookupGroupId(group)
+		}
+		if err != nil {
+			return nil, err
+		}
+		gid = g.Gid
+	}
+
+	id := &Identity{}
+	id.UID, err = strconv.Atoi(u.Uid)
+	if err != nil {
+		return nil, fmt.Errorf("user %s has a non-numeric id: %w", username, err)
+	}
+	id.GID, err = strconv.Atoi(gid)
+	if err != nil {
+		return nil, fmt.Errorf("group %s has a non-numeric id: %w", gid, err)
+	}
+
+	groupIDs, err := u.GroupIds()
+	if err != nil {
+		return nil, err
+	}
+	for _, groupID := range groupIDs {
+		n, err := strconv.Atoi(groupID)
+		if err != nil {
+			return nil, fmt.Errorf("group %s has a non-numeric id: %w", groupID, err)
+		}
+		id.Groups = append(id.Groups, n)
+	}
+	return id, nil
+}
+
+// Drop changes the user and groups of the process to the configured
+// user and group, if they differ from the current ones. It returns
+// ErrRunningAsRoot if the process is still running as root afterwards,
+// unless that is explicitly allowed.
+func Drop(cfg *config.Server) error {
+	if cfg.User != "" {
+		id, err := Lookup(cfg.User, cfg.Group)
+		if err != nil {
+			return fmt.Errorf("looking up user %s: %w", cfg.User, err)
+		}
+		if os.Geteuid() != id.UID || os.Getegid() != id.GID {
+			err = setIdentity(id)
+			if err != nil {
+				return fmt.Errorf("changing to user %s: %w", cfg.User, err)
+			}
+		}
+	}
+
+	if os.Geteuid() == 0 && !cfg.AllowRoot {
+		return ErrRunningAsRoot
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+//go:build linux
+
+package privileges
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// setIdentity changes the user and groups of every thread of the process.
+// The groups are changed first, while the process may still change them.
+func setIdentity(id *Identity) error {
+	err := syscall.Setgroups(id.Groups)
+	if err != nil {
+		return fmt.Errorf("setgroups: %w", err)
+	}
+	err = syscall.Setgid(id.GID)
+	if err != nil {
+		return fmt.Errorf("setgid: %w", err)
+	}
+	err = syscall.Setuid(id.UID)
+	if err != nil {
+		return fmt.Errorf("setuid: %w", err)
+	}
+
+	// ensure root privileges cannot be regained
+	if id.UID != 0 && syscall.Setuid(0) == nil {
+		return fmt.Errorf("root privileges were regained after changing to uid %d", id.UID)
+	}
+	if os.Geteuid() != id.UID || os.Getegid() != id.GID {
+		return fmt.Errorf("the process is running as uid %d and gid %d", os.Geteuid(), os.Getegid())
+	}
+	return nil
+}
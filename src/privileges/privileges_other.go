@@ -0,0 +1,14 @@
+//go:build !linux
+
+package privileges
+
+import (
+	"errors"
+	"runtime"
+)
+
+// setIdentity is not supported on this platform, so
+// the server must be started as the configured user.
+func setIdentity(*Identity) error {
+	return errors.New("changing user is not supported on " + runtime.GOOS)
+}
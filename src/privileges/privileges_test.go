@@ -0,0 +1,48 @@
+package privileges
+
+import (
+	"fsrv/src/config"
+	"github.com/go-playground/assert/v2"
+	"os"
+	"os/user"
+	"strconv"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	current, err := user.Current()
+	if err != nil {
+		t.Skip("current user unavailable:", err)
+	}
+
+	byName, err := Lookup(current.Username, "")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, os.Getuid(), byName.UID)
+	assert.Equal(t, current.Gid, strconv.Itoa(byName.GID))
+
+	byID, err := Lookup(current.Uid, current.Gid)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, byName, byID)
+
+	_, err = Lookup("fsrv-no-such-user", "")
+	assert.NotEqual(t, nil, err)
+}
+
+func TestDrop(t *testing.T) {
+	current, err := user.Current()
+	if err != nil {
+		t.Skip("current user unavailable:", err)
+	}
+
+	// the identity is unchanged, so root is only refused if not allowed
+	cfg := &config.Server{User: current.Username, Group: current.Gid}
+	err = Drop(cfg)
+	if os.Geteuid() == 0 {
+		assert.Equal(t, ErrRunningAsRoot, err)
+	} else {
+		assert.Equal(t, nil, err)
+	}
+
+	cfg.AllowRoot = true
+	assert.Equal(t, nil, Drop(cfg))
+}
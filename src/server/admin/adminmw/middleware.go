@@ -1 +1,58 @@
 package adminmw
+
+import (
+	"fsrv/src/database"
+	"fsrv/src/server/middleware"
+	"fsrv/src/types/response"
+	"fsrv/utils/keygen"
+	"github.com/gin-gonic/gin"
+	"strings"
+)
+
+// TokenAuth verifies that a request is authenticated with
+// a valid admin token in an 'Authorization: Bearer' header.
+//
+//	Added Context Fields:
+//	 token -> *entities.Token
+func TokenAuth(db database.DBInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tokenStr, ok := extractBearer(ctx)
+		if !ok {
+			ctx.Header("WWW-Authenticate", "Bearer")
+			ctx.AbortWithStatusJSON(401, response.Unauthorized)
+			return
+		}
+
+		// tokens are stored by their hash, like keys.
+		token, err := db.GetTokenData(keygen.HashKey(tokenStr))
+		if err != nil {
+			if err == database.ErrTokenMissing {
+				ctx.AbortWithStatusJSON(401, response.Unauthorized)
+				return
+			}
+
+			middleware.GetLogger(ctx).Errorf("error getting token from database: %v", err)
+			ctx.AbortWithStatusJSON(500, response.InternalServerError)
+			return
+		}
+
+		if token.IsExpired() {
+			ctx.AbortWithStatusJSON(401, response.UnauthorizedExpiredToken)
+			return
+		}
+
+		ctx.Set("token", token)
+		ctx.Next()
+	}
+}
+
+// extractBearer returns the token in a request's Authorization
+// header, if it uses the Bearer scheme.
+func extractBearer(ctx *gin.Context) (string, bool) {
+	scheme, token, ok := strings.Cut(ctx.GetHeader("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
@@ -0,0 +1,64 @@
+package adminmw
+
+import (
+	"fsrv/src/database/entities"
+	"fsrv/src/database/impl/sqlite"
+	"fsrv/utils/keygen"
+	"fsrv/utils/serde"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTokenAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, err := sqlite.Create(filepath.Join(t.TempDir(), "fsrv.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens := map[string]time.Time{
+		"valid":   time.Unix(0, 0),
+		"future":  time.Now().Add(time.Hour),
+		"expired": time.Now().Add(-time.Hour),
+	}
+	for plaintext, expiresAt := range tokens {
+		err = db.CreateToken(&entities.Token{
+			ID:        keygen.HashKey(plaintext),
+			ExpiresAt: serde.Time(expiresAt),
+			CreatedAt: serde.Time(time.Now()),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := gin.New()
+	r.Use(TokenAuth(db))
+	r.GET("/", func(ctx *gin.Context) {
+		ctx.Status(200)
+	})
+
+	tests := []struct {
+		header string
+		code   int
+	}{
+		{"Bearer valid", 200},
+		{"bearer future", 200},
+		{"Bearer expired", 401},
+		{"Bearer missing", 401},
+		{"Basic valid", 401},
+		{"valid", 401},
+		{"", 401},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", test.header)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, test.code, w.Code)
+	}
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"fsrv/src/database"
+	"fsrv/src/server/middleware"
+	"fsrv/src/types/response"
+	"github.com/gin-gonic/gin"
+)
+
+// abortWithDBError aborts the request with a response
+// appropriate to an error returned by the database.
+func abortWithDBError(ctx *gin.Context, err error) {
+	switch err {
+	case database.ErrKeyMissing, database.ErrRoleMissing, database.ErrResourceMissing, database.ErrRateLimitMissing,
+		database.ErrTokenMissing:
+		ctx.AbortWithStatusJSON(404, response.NewErrorMessage(err.Error()))
+	case database.ErrKeyDuplicate, database.ErrRoleDuplicate, database.ErrResourceDuplicate, database.ErrTokenDuplicate:
+		ctx.AbortWithStatusJSON(409, response.NewErrorMessage(err.Error()))
+	case database.ErrKeyNameBad, database.ErrRoleNameBad, database.ErrResourceNameBad:
+		ctx.AbortWithStatusJSON(400, response.NewErrorMessage(err.Error()))
+	default:
+		middleware.GetLogger(ctx).Errorf("error accessing database: %v", err)
+		ctx.AbortWithStatusJSON(500, response.InternalServerError)
+	}
+}
+
+// bindJSON binds the request body to v, aborting the request
+// with code 400 and returning false if it is not valid JSON.
+func bindJSON(ctx *gin.Context, v any) bool {
+	err := ctx.ShouldBindJSON(v)
+	if err != nil {
+		ctx.AbortWithStatusJSON(400, response.NewErrorMessage("error parsing body: "+err.Error()))
+		return false
+	}
+	return true
+}
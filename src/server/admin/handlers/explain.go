@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"fsrv/src/database/entities"
+	"fsrv/src/server/middleware"
+	"fsrv/src/types"
+	"fsrv/src/types/response"
+	"github.com/gin-gonic/gin"
+	"path/filepath"
+)
+
+// ExplainAccess represents a request to explain why a key is allowed or
+// denied permission to perform an operation on a path, which does not
+// need to exist. The response contains the evaluation of the resource
+// at each level of the directory walk, and the final access status.
+//
+//	Middleware Dependencies:
+//	 GetQuery (key): the key id, or empty for anonymous access
+//	 GetQuery (path): the path, relative to the file manager path
+//	 GetQuery (operation)
+func (h *Handler) ExplainAccess() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var key *entities.Key
+		if keyID := ctx.GetString("key"); keyID != "" {
+			var err error
+			key, err = h.database.GetKeyData(keyID)
+			if err != nil {
+				abortWithDBError(ctx, err)
+				return
+			}
+		}
+		op, err := types.ParseOperationType(ctx.GetString("operation"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(400, response.NewErrorMessage(err.Error()))
+			return
+		}
+
+		// the path is rooted, so that it cannot escape the file manager path
+		name := h.fileManager.CleanPath(filepath.FromSlash("/" + ctx.GetString("path")))
+		exp, err := h.resolver.Explain(key, name, op)
+		if err != nil {
+			middleware.GetLogger(ctx).Errorf("error explaining access: %v", err)
+			ctx.AbortWithStatusJSON(500, response.InternalServerError)
+			return
+		}
+		ctx.JSON(200, response.NewSuccessData(exp))
+	}
+}
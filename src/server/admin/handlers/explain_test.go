@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"fsrv/src/access"
+	"fsrv/src/database/entities"
+	"fsrv/src/filemanager"
+	"fsrv/src/server/middleware"
+	"github.com/go-playground/assert/v2"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHandler_ExplainAccess(t *testing.T) {
+	r, db, dir := newTestFileHandler(t)
+	err := os.MkdirAll(filepath.Join(dir, "dir"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = (filemanager.XAttrLocator{}).Set(filepath.Join(dir, "dir"), "dir")
+	if err != nil {
+		t.Skip("extended attributes are not supported:", err)
+	}
+	err = db.CreateResource(&entities.Resource{ID: "dir"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.CreateRole(&entities.Role{ID: "reader"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := doRequest(r, "POST", "/admin/resources/dir/permissions", `{"roles":["reader"],"operation":"read","allow":true}`)
+	assert.Equal(t, 200, w.Code)
+	w = doRequest(r, "POST", "/admin/keys", `{"roles":["reader"]}`)
+	assert.Equal(t, 201, w.Code)
+	keyID := decodeData[entities.Key](t, w).ID
+
+	w = doRequest(r, "GET", "/admin/explain?key="+keyID+"&path=/dir/file.txt&operation=read", "")
+	assert.Equal(t, 200, w.Code)
+	exp := decodeData[access.Explanation](t, w)
+	assert.Equal(t, keyID, exp.KeyID)
+	assert.Equal(t, "/dir/file.txt", exp.Path)
+	assert.Equal(t, 2, len(exp.Steps))
+	assert.Equal(t, "dir", exp.Steps[1].ResourceID)
+	assert.Equal(t, entities.MatchRole, exp.Steps[1].Match.Rule)
+	assert.Equal(t, "reader", exp.Steps[1].Match.Node.ID)
+	assert.Equal(t, false, exp.Default)
+
+	// anonymous access falls through to the default
+	w = doRequest(r, "GET", "/admin/explain?path=/file.txt&operation=write", "")
+	assert.Equal(t, 200, w.Code)
+	exp = decodeData[access.Explanation](t, w)
+	assert.Equal(t, "", exp.KeyID)
+	assert.Equal(t, true, exp.Default)
+
+	// paths are relative to the file manager path, and cannot escape it
+	w = doRequest(r, "GET", "/admin/explain?path=..&operation=read", "")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "/", decodeData[access.Explanation](t, w).Path)
+	w = doRequest(r, "GET", "/admin/explain?path=dir/../../file.txt&operation=read", "")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "/file.txt", decodeData[access.Explanation](t, w).Path)
+
+	w = doRequest(r, "GET", "/admin/explain?key=missing&path=/", "")
+	assert.Equal(t, 404, w.Code)
+	w = doRequest(r, "GET", "/admin/explain?operation=execute", "")
+	assert.Equal(t, 400, w.Code)
+}
+
+func TestHandler_ExplainAccessMetrics(t *testing.T) {
+	// the operation query is stored as a string, which the
+	// metrics of the admin server must not mistake for an
+	// operation type.
+	r, _, _ := newTestFileHandler(t, middleware.Metrics("admin"))
+	w := doRequest(r, "GET", "/admin/explain?path=/file.txt&operation=read", "")
+	assert.Equal(t, 200, w.Code)
+}
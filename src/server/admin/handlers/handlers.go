@@ -1,26 +1,71 @@
 package handlers
 
 import (
+	"fsrv/src/access"
+	"fsrv/src/config"
 	"fsrv/src/database"
 	"fsrv/src/filemanager"
+	"fsrv/src/server/middleware"
 	"github.com/gin-gonic/gin"
+	"math"
 )
 
 type Handler struct {
+	config      *config.Server
 	database    database.DBInterface
 	fileManager *filemanager.FileManager
+	resolver    *access.Resolver
 }
 
-func New(db database.DBInterface, fm *filemanager.FileManager) *Handler {
+func New(cfg *config.Server, db database.DBInterface, fm *filemanager.FileManager, resolver *access.Resolver) *Handler {
 	return &Handler{
+		config:      cfg,
 		database:    db,
 		fileManager: fm,
+		resolver:    resolver,
 	}
 }
 
 func (h *Handler) Register(r *gin.Engine) {
-	//r.GET("/", h.Get())
-	//r.POST("/", h.Create())
-	//r.PATCH("/", h.Update())
-	//r.DELETE("/", h.Delete())
+	pageQueries := []gin.HandlerFunc{
+		middleware.GetQuery("limit", "limit", middleware.IntQuery(100, 1, 1000)),
+		middleware.GetQuery("offset", "offset", middleware.IntQuery(0, 0, math.MaxInt32)),
+	}
+
+	keys := r.Group("/admin/keys")
+	keys.GET("", append(pageQueries, h.ListKeys())...)
+	keys.POST("", h.CreateKey())
+	keys.GET("/:id", h.GetKey())
+	keys.PATCH("/:id", h.UpdateKey())
+	keys.DELETE("/:id", h.DeleteKey())
+	keys.PUT("/:id/roles/:role", h.GiveRole())
+	keys.DELETE("/:id/roles/:role", h.TakeRole())
+
+	roles := r.Group("/admin/roles")
+	roles.GET("", append(pageQueries, h.ListRoles())...)
+	roles.POST("", h.CreateRole())
+	roles.GET("/:id", h.GetRole())
+	roles.DELETE("/:id", h.DeleteRole())
+
+	resources := r.Group("/admin/resources")
+	resources.GET("/:id/permissions", h.GetPermissions())
+	resources.POST("/:id/permissions", h.GrantPermission())
+	resources.DELETE("/:id/permissions", h.RevokePermission())
+
+	paths := r.Group("/admin/paths")
+	paths.GET("/*path", h.GetPathChain())
+	paths.PUT("/*path", h.AttachResource())
+	paths.DELETE("/*path", middleware.GetQuery("keep", "keep", middleware.BoolQuery(false)), h.DetachResource())
+
+	r.GET("/admin/explain",
+		middleware.GetQuery("key", "key", middleware.StringQuery()),
+		middleware.GetQuery("path", "path", middleware.StringQuery()),
+		middleware.GetQuery("operation", "operation", middleware.EnumQuery("read", "write", "modify", "delete")),
+		h.ExplainAccess(),
+	)
+
+	tokens := r.Group("/admin/tokens")
+	tokens.GET("", append(pageQueries, h.ListTokens())...)
+	tokens.POST("", h.CreateToken())
+	tokens.DELETE("/:id", h.DeleteToken())
 }
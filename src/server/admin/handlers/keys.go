@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"fsrv/src/config"
+	"fsrv/src/database"
+	"fsrv/src/database/entities"
+	"fsrv/src/types/response"
+	"fsrv/utils/keygen"
+	"fsrv/utils/serde"
+	"github.com/gin-gonic/gin"
+	"time"
+)
+
+// KeyRequest represents the fields of a key which
+// may be set when it is created or updated.
+type KeyRequest struct {
+	// Comment is used to note the owner or usage of the key.
+	Comment *string `json:"comment"`
+	// Roles are the roles the key has. They may only be set
+	// when the key is created.
+	Roles []string `json:"roles"`
+	// RateLimitID is the rate limit level of the key. If empty,
+	// the default authenticated rate limit is used.
+	RateLimitID *string `json:"rate_limit_id"`
+	// ExpiresAt is the time when the key expires,
+	// in unix milliseconds. 0 means it never expires.
+	ExpiresAt *serde.Time `json:"expires_at"`
+}
+
+// CreatedKey represents a key minted by a create request.
+type CreatedKey struct {
+	*entities.Key
+	// Plaintext is the key used by clients to authenticate. It is
+	// only returned when the key is created, as the server only
+	// stores its hash, which is used as the id of the key.
+	Plaintext string `json:"key"`
+}
+
+// ListKeys represents a request to list keys.
+//
+//	Middleware Dependencies:
+//	 GetQuery (limit)
+//	 GetQuery (offset)
+func (h *Handler) ListKeys() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		keys, err := h.database.GetKeys(ctx.GetInt("limit"), ctx.GetInt("offset"))
+		if err != nil {
+			abortWithDBError(ctx, err)
+			return
+		}
+		if keys == nil {
+			keys = []*entities.Key{}
+		}
+		ctx.JSON(200, response.NewSuccessData(keys))
+	}
+}
+
+// CreateKey represents a request to mint a new key.
+func (h *Handler) CreateKey() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req KeyRequest
+		if !bindJSON(ctx, &req) {
+			return
+		}
+
+		key, plaintext := NewKey(h.config)
+		key.Roles = req.Roles
+		if !h.applyKeyRequest(ctx, key, &req) {
+			return
+		}
+
+		err := h.database.CreateKey(key)
+		if err != nil {
+			if err == database.ErrRoleMissing {
+				ctx.AbortWithStatusJSON(400, response.NewErrorMessage(err.Error()))
+				return
+			}
+			abortWithDBError(ctx, err)
+			return
+		}
+
+		ctx.JSON(201, response.NewSuccessData(&CreatedKey{
+			Key:       key,
+			Plaintext: plaintext,
+		}))
+	}
+}
+
+// NewKey mints a new key which never expires, returning it along with the
+// plaintext key used by clients, from which the id of the key is derived.
+func NewKey(cfg *config.Server) (*entities.Key, string) {
+	plaintext := keygen.MintKey(
+		keygen.GetRand(cfg.KeyRandomBytes),
+		[]byte(cfg.KeyValidationSecret),
+		cfg.KeyCheckBytes,
+	)
+	key := &entities.Key{
+		ID:        keygen.HashKey(plaintext),
+		ExpiresAt: serde.Time(time.Unix(0, 0)),
+		CreatedAt: serde.Time(time.Now()),
+	}
+	return key, plaintext
+}
+
+// GetKey represents a request to get a key by its id.
+func (h *Handler) GetKey() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key, err := h.database.GetKeyData(ctx.Param("id"))
+		if err != nil {
+			abortWithDBError(ctx, err)
+			return
+		}
+		ctx.JSON(200, response.NewSuccessData(key))
+	}
+}
+
+// UpdateKey represents a request to update the comment,
+// rate limit or expiry of a key. Fields which are not
+// present in the request are left unchanged.
+func (h *Handler) UpdateKey() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req KeyRequest
+		if !bindJSON(ctx, &req) {
+			return
+		}
+		if req.Roles != nil {
+			ctx.AbortWithStatusJSON(400, response.NewErrorMessage("roles cannot be changed by updating a key"))
+			return
+		}
+
+		cached, err := h.database.GetKeyData(ctx.Param("id"))
+		if err != nil {
+			abortWithDBError(ctx, err)
+			return
+		}
+		// the key may be shared by a cache, so it is updated as a copy,
+		// which the cache replaces it with once the update succeeds.
+		key := *cached
+		if !h.applyKeyRequest(ctx, &key, &req) {
+			return
+		}
+
+		err = h.database.UpdateKey(&key)
+		if err != nil {
+			abortWithDBError(ctx, err)
+			return
+		}
+		ctx.JSON(200, response.NewSuccessData(&key))
+	}
+}
+
+// DeleteKey represents a request to revoke a key.
+func (h *Handler) DeleteKey() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		err := h.database.DeleteKey(ctx.Param("id"))
+		if err != nil {
+			abortWithDBError(ctx, err)
+			return
+		}
+		ctx.JSON(200, response.EmptySuccess)
+	}
+}
+
+// applyKeyRequest sets the fields present in a request on a key,
+// aborting the request and returning false if any are invalid, in
+// which case the key is left unchanged.
+func (h *Handler) applyKeyRequest(ctx *gin.Context, key *entities.Key, req *KeyRequest) bool {
+	if req.RateLimitID != nil && *req.RateLimitID != "" {
+		_, err := h.database.GetRateLimitData(*req.RateLimitID)
+		if err != nil {
+			if err == database.ErrRateLimitMissing {
+				ctx.AbortWithStatusJSON(400, response.NewErrorMessage(err.Error()))
+				return false
+			}
+			abortWithDBError(ctx, err)
+			return false
+		}
+	}
+
+	if req.Comment != nil {
+		key.Comment = *req.Comment
+	}
+	if req.ExpiresAt != nil {
+		key.ExpiresAt = *req.ExpiresAt
+	}
+	if req.RateLimitID != nil {
+		key.RateLimitID = *req.RateLimitID
+	}
+	return true
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fsrv/src/access"
+	"fsrv/src/config"
+	"fsrv/src/database/entities"
+	"fsrv/src/database/impl/cache"
+	"fsrv/src/database/impl/sqlite"
+	"fsrv/src/filemanager"
+	"fsrv/utils/keygen"
+	"fsrv/utils/serde"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestHandler(t *testing.T) (*gin.Engine, *sqlite.SQLiteDB) {
+	r, db, _ := newTestFileHandler(t)
+	return r, db
+}
+
+// newTestFileHandler returns a handler whose file manager path is
+// the returned temporary directory, after any middleware given.
+func newTestFileHandler(t *testing.T, mw ...gin.HandlerFunc) (*gin.Engine, *sqlite.SQLiteDB, string) {
+	gin.SetMode(gin.TestMode)
+
+	db, err := sqlite.Create(filepath.Join(t.TempDir(), "fsrv.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Server{
+		KeyValidationSecret: "secret",
+		KeyRandomBytes:      32,
+		KeyCheckBytes:       8,
+	}
+
+	dir := t.TempDir()
+	fmCfg := &config.FileManager{Path: dir, MaxDepth: 5}
+	fm := filemanager.New(fmCfg, db)
+
+	r := gin.New()
+	r.Use(mw...)
+	New(cfg, db, fm, access.New(db, fm, fmCfg)).Register(r)
+	return r, db, dir
+}
+
+func doRequest(r http.Handler, method, url, body string) *httptest.ResponseRecorder {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, url, reader)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func decodeData[T any](t *testing.T, w *httptest.ResponseRecorder) T {
+	var res struct {
+		Data T `json:"data"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res.Data
+}
+
+func TestHandler_Keys(t *testing.T) {
+	r, db := newTestHandler(t)
+	err := db.CreateRole(&entities.Role{ID: "reader", Precedence: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := doRequest(r, "POST", "/admin/keys", `{"comment":"test","roles":["reader"]}`)
+	assert.Equal(t, 201, w.Code)
+	created := decodeData[struct {
+		ID        string   `json:"id"`
+		Key       string   `json:"key"`
+		Comment   string   `json:"comment"`
+		Roles     []string `json:"roles"`
+		ExpiresAt int64    `json:"expires_at"`
+	}](t, w)
+	assert.Equal(t, keygen.HashKey(created.Key), created.ID)
+	assert.Equal(t, "test", created.Comment)
+	assert.Equal(t, int64(0), created.ExpiresAt)
+
+	// the key itself is never returned again
+	w = doRequest(r, "GET", "/admin/keys/"+created.ID, "")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, false, strings.Contains(w.Body.String(), created.Key))
+	key := decodeData[entities.Key](t, w)
+	assert.Equal(t, "reader", key.Roles[0])
+	assert.Equal(t, false, key.IsExpired())
+
+	w = doRequest(r, "PATCH", "/admin/keys/"+created.ID, `{"comment":"updated","expires_at":1000}`)
+	assert.Equal(t, 200, w.Code)
+	key = decodeData[entities.Key](t, w)
+	assert.Equal(t, "updated", key.Comment)
+	assert.Equal(t, true, key.IsExpired())
+
+	w = doRequest(r, "PATCH", "/admin/keys/"+created.ID, `{"rate_limit_id":"missing"}`)
+	assert.Equal(t, 400, w.Code)
+
+	w = doRequest(r, "GET", "/admin/keys?limit=10", "")
+	assert.Equal(t, 200, w.Code)
+	keys := decodeData[[]*entities.Key](t, w)
+	assert.Equal(t, 1, len(keys))
+	assert.Equal(t, "updated", keys[0].Comment)
+
+	w = doRequest(r, "DELETE", "/admin/keys/"+created.ID, "")
+	assert.Equal(t, 200, w.Code)
+	w = doRequest(r, "GET", "/admin/keys/"+created.ID, "")
+	assert.Equal(t, 404, w.Code)
+	w = doRequest(r, "DELETE", "/admin/keys/"+created.ID, "")
+	assert.Equal(t, 404, w.Code)
+}
+
+func TestHandler_UpdateKeyInvalid(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sqliteDB, err := sqlite.Create(filepath.Join(t.TempDir(), "fsrv.sqlite"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// keys are shared with the file server by the cache
+	db := cache.NewCache(&config.Cache{Keys: 10}, sqliteDB)
+	fmCfg := &config.FileManager{Path: t.TempDir(), MaxDepth: 5}
+	fm := filemanager.New(fmCfg, db)
+	r := gin.New()
+	New(&config.Server{}, db, fm, access.New(db, fm, fmCfg)).Register(r)
+
+	key := &entities.Key{ID: "key", ExpiresAt: serde.Time(time.Unix(0, 0)), CreatedAt: serde.Time(time.Now())}
+	err = db.CreateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.GetKeyData(key.ID)
+	assert.Equal(t, nil, err)
+
+	w := doRequest(r, "PATCH", "/admin/keys/key", `{"comment":"updated","expires_at":1000,"rate_limit_id":"missing"}`)
+	assert.Equal(t, 400, w.Code)
+
+	cached, err := db.GetKeyData(key.ID)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, cached.IsExpired())
+	assert.Equal(t, "", cached.Comment)
+}
+
+func TestHandler_CreateKeyMissingRole(t *testing.T) {
+	r, _ := newTestHandler(t)
+
+	w := doRequest(r, "POST", "/admin/keys", `{"roles":["missing"]}`)
+	assert.Equal(t, 400, w.Code)
+}
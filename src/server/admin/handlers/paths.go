@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"errors"
+	"fsrv/src/access"
+	"fsrv/src/database/entities"
+	"fsrv/src/filemanager"
+	"fsrv/src/server/middleware"
+	"fsrv/src/types/response"
+	"github.com/gin-gonic/gin"
+	"io/fs"
+)
+
+// AttachRequest represents a request to create a
+// resource and attach it to a file or directory.
+type AttachRequest struct {
+	// ID is the id of the resource. If empty, one is generated.
+	ID string `json:"id"`
+	// Flags are the access flags of the resource.
+	Flags entities.Flags `json:"flags"`
+	// Nodes are the permission nodes of the resource, in the form
+	// "<operation>:<role>": allow. Nodes may also be granted later.
+	Nodes map[entities.ResourceOperationAccess]bool `json:"nodes"`
+}
+
+// AttachedResource represents the resource attached to a path.
+type AttachedResource struct {
+	// Path is the path the resource is attached to, as seen by clients.
+	Path string `json:"path"`
+	// Resource is the resource attached to the path.
+	Resource *entities.Resource `json:"resource"`
+}
+
+// GetPathChain represents a request to get the resources which
+// decide the access to a path, which does not need to exist: the
+// resource attached to the path itself, if any, followed by those
+// attached to each of its parents, up to the root directory.
+//
+//	Path Parameters:
+//	 path: the path, relative to the file manager path
+func (h *Handler) GetPathChain() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		name, ok := h.cleanPath(ctx)
+		if !ok {
+			return
+		}
+
+		chain, err := h.resolver.Chain(name)
+		if err != nil {
+			middleware.GetLogger(ctx).Errorf("error resolving resource chain: %v", err)
+			ctx.AbortWithStatusJSON(500, response.InternalServerError)
+			return
+		}
+		ctx.JSON(200, response.NewSuccessData(chain))
+	}
+}
+
+// AttachResource represents a request to create a resource and attach
+// it to an existing file or directory. If attaching the resource fails,
+// it is deleted again, so that no resource is left unattached.
+//
+//	Path Parameters:
+//	 path: the path, relative to the file manager path
+func (h *Handler) AttachResource() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		name, ok := h.cleanPath(ctx)
+		if !ok {
+			return
+		}
+		var req AttachRequest
+		if !bindJSON(ctx, &req) {
+			return
+		}
+
+		res := &entities.Resource{
+			ID:             req.ID,
+			Flags:          req.Flags,
+			OperationNodes: req.Nodes,
+		}
+		if res.ID == "" {
+			res.ID = access.NewResourceID()
+		}
+		if res.OperationNodes == nil {
+			res.OperationNodes = map[entities.ResourceOperationAccess]bool{}
+		}
+		roles := make([]string, 0, len(res.OperationNodes))
+		for node := range res.OperationNodes {
+			roles = append(roles, node.ID)
+		}
+		if !h.checkRoles(ctx, roles) {
+			return
+		}
+
+		err := h.resolver.Attach(name, res)
+		if err != nil {
+			abortWithAttachError(ctx, err)
+			return
+		}
+
+		ctx.JSON(201, response.NewSuccessData(&AttachedResource{
+			Path:     h.fileManager.RelPath(name),
+			Resource: res,
+		}))
+	}
+}
+
+// DetachResource represents a request to detach the resource attached
+// directly to a path, so that the path inherits its access from its
+// parent directory. The resource is deleted, unless it is kept.
+//
+//	Path Parameters:
+//	 path: the path, relative to the file manager path
+//
+//	Middleware Dependencies:
+//	 GetQuery (keep)
+func (h *Handler) DetachResource() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		name, ok := h.cleanPath(ctx)
+		if !ok {
+			return
+		}
+		_, err := h.resolver.Detach(name, ctx.GetBool("keep"))
+		if err != nil {
+			abortWithAttachError(ctx, err)
+			return
+		}
+		ctx.JSON(200, response.EmptySuccess)
+	}
+}
+
+// cleanPath returns the on-disk path of the path parameter of a request,
+// aborting the request and returning false if it is reserved.
+func (h *Handler) cleanPath(ctx *gin.Context) (string, bool) {
+	name := h.fileManager.CleanPath(ctx.Param("path"))
+	if h.fileManager.IsReserved(name) {
+		ctx.AbortWithStatusJSON(404, response.NewErrorMessage("the specified path does not exist"))
+		return "", false
+	}
+	return name, true
+}
+
+// abortWithAttachError aborts the request with a response appropriate
+// to an error returned when attaching or detaching a resource.
+func abortWithAttachError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		ctx.AbortWithStatusJSON(404, response.NewErrorMessage("the specified path does not exist"))
+	case err == filemanager.ErrNotAttached:
+		ctx.AbortWithStatusJSON(404, response.NewErrorMessage(err.Error()))
+	case err == access.ErrAlreadyAttached:
+		ctx.AbortWithStatusJSON(409, response.NewErrorMessage(err.Error()))
+	default:
+		abortWithDBError(ctx, err)
+	}
+}
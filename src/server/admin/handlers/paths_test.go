@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"fsrv/src/access"
+	"fsrv/src/database/entities"
+	"fsrv/src/filemanager"
+	"github.com/go-playground/assert/v2"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHandler_Paths(t *testing.T) {
+	r, db, dir := newTestFileHandler(t)
+	err := os.MkdirAll(filepath.Join(dir, "dir", "sub"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = (filemanager.XAttrLocator{}).Set(dir, "root")
+	if err != nil {
+		t.Skip("extended attributes are not supported:", err)
+	}
+	err = (filemanager.XAttrLocator{}).Remove(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := doRequest(r, "PUT", "/admin/paths/dir", `{"id":"dir","flags":1,"nodes":{"read:*":true}}`)
+	assert.Equal(t, 201, w.Code)
+	attached := decodeData[AttachedResource](t, w)
+	assert.Equal(t, "/dir", attached.Path)
+	assert.Equal(t, entities.FlagPublicRead, attached.Resource.Flags)
+
+	w = doRequest(r, "PUT", "/admin/paths/dir", `{}`)
+	assert.Equal(t, 409, w.Code)
+	w = doRequest(r, "PUT", "/admin/paths/missing", `{}`)
+	assert.Equal(t, 404, w.Code)
+	w = doRequest(r, "PUT", "/admin/paths/dir/sub", `{"nodes":{"read:missing":true}}`)
+	assert.Equal(t, 404, w.Code)
+
+	// a resource id is generated if none is given
+	w = doRequest(r, "PUT", "/admin/paths/", `{}`)
+	assert.Equal(t, 201, w.Code)
+	root := decodeData[AttachedResource](t, w)
+	assert.NotEqual(t, "", root.Resource.ID)
+
+	w = doRequest(r, "GET", "/admin/paths/dir/sub/file.txt", "")
+	assert.Equal(t, 200, w.Code)
+	chain := decodeData[[]*access.Link](t, w)
+	assert.Equal(t, 2, len(chain))
+	assert.Equal(t, "/dir", chain[0].Path)
+	assert.Equal(t, true, chain[0].Resource.OperationNodes[entities.ResourceOperationAccess{ID: "*"}])
+	assert.Equal(t, "/", chain[1].Path)
+	assert.Equal(t, root.Resource.ID, chain[1].ResourceID)
+
+	w = doRequest(r, "DELETE", "/admin/paths/dir", "")
+	assert.Equal(t, 200, w.Code)
+	_, err = db.GetResourceData("dir")
+	assert.NotEqual(t, nil, err)
+	w = doRequest(r, "DELETE", "/admin/paths/dir", "")
+	assert.Equal(t, 404, w.Code)
+
+	w = doRequest(r, "DELETE", "/admin/paths/?keep=true", "")
+	assert.Equal(t, 200, w.Code)
+	_, err = db.GetResourceData(root.Resource.ID)
+	assert.Equal(t, nil, err)
+
+	w = doRequest(r, "GET", "/admin/paths/dir", "")
+	assert.Equal(t, 0, len(decodeData[[]*access.Link](t, w)))
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"fsrv/src/database"
+	"fsrv/src/database/entities"
+	"fsrv/src/types"
+	"fsrv/src/types/response"
+	"github.com/gin-gonic/gin"
+)
+
+// PermissionRequest represents a request to allow or deny
+// roles permission to perform an operation on a resource.
+type PermissionRequest struct {
+	// Roles are the roles, key ids or "*" (any key) which are
+	// allowed or denied permission.
+	Roles []string `json:"roles" binding:"required,min=1"`
+	// Operation is the operation: read, write, modify or delete.
+	Operation string `json:"operation" binding:"required"`
+	// Allow is whether the operation is allowed or denied.
+	Allow bool `json:"allow"`
+}
+
+// GetPermissions represents a request to get a resource,
+// including the permission nodes of its roles.
+func (h *Handler) GetPermissions() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		res, err := h.database.GetResourceData(ctx.Param("id"))
+		if err != nil {
+			abortWithDBError(ctx, err)
+			return
+		}
+		ctx.JSON(200, response.NewSuccessData(res))
+	}
+}
+
+// GrantPermission represents a request to allow or deny roles
+// permission to perform an operation on a resource, replacing
+// any permission they already had for the operation. The
+// response contains the updated resource.
+func (h *Handler) GrantPermission() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req PermissionRequest
+		if !bindJSON(ctx, &req) {
+			return
+		}
+		op, err := types.ParseOperationType(req.Operation)
+		if err != nil {
+			ctx.AbortWithStatusJSON(400, response.NewErrorMessage(err.Error()))
+			return
+		}
+		if !h.checkGrantee(ctx, req.Roles) {
+			return
+		}
+
+		h.changePermission(ctx, req.Roles, func(perm *entities.Permission, roles []string) error {
+			perm.TypeRWMD = op
+			perm.Status = req.Allow
+			return h.database.GrantPermission(perm, roles...)
+		})
+	}
+}
+
+// RevokePermission represents a request to remove the permission
+// nodes of roles for an operation on a resource, so that access
+// for the roles is inherited instead. The response contains the
+// updated resource.
+//
+//	Query Parameters:
+//	 operation: the operation type
+//	 role: a role, key id or "*"; may be repeated
+func (h *Handler) RevokePermission() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		op, err := types.ParseOperationType(ctx.Query("operation"))
+		if err != nil {
+			ctx.AbortWithStatusJSON(400, response.NewErrorMessage(err.Error()))
+			return
+		}
+		roles := ctx.QueryArray("role")
+		if len(roles) == 0 {
+			ctx.AbortWithStatusJSON(400, response.NewErrorMessage("at least one role must be provided"))
+			return
+		}
+
+		h.changePermission(ctx, roles, func(perm *entities.Permission, roles []string) error {
+			perm.TypeRWMD = op
+			return h.database.RevokePermission(perm, roles...)
+		})
+	}
+}
+
+// checkGrantee ensures that the resource of a request and each of the
+// given roles exist, aborting the request and returning false if not.
+// The roles may also be key ids, or "*".
+func (h *Handler) checkGrantee(ctx *gin.Context, roles []string) bool {
+	_, err := h.database.GetResourceData(ctx.Param("id"))
+	if err != nil {
+		abortWithDBError(ctx, err)
+		return false
+	}
+	return h.checkRoles(ctx, roles)
+}
+
+// checkRoles ensures that each of the given roles exist, aborting the
+// request and returning false if not. The roles may also be key ids,
+// or "*".
+func (h *Handler) checkRoles(ctx *gin.Context, roles []string) bool {
+	for _, role := range roles {
+		if role == "*" {
+			continue
+		}
+		_, err := h.database.GetRoleData(role)
+		if err == database.ErrRoleMissing {
+			_, err = h.database.GetKeyData(role)
+			if err == database.ErrKeyMissing {
+				err = database.ErrRoleMissing
+			}
+		}
+		if err != nil {
+			abortWithDBError(ctx, err)
+			return false
+		}
+	}
+	return true
+}
+
+func (h *Handler) changePermission(ctx *gin.Context, roles []string, change func(*entities.Permission, []string) error) {
+	resourceID := ctx.Param("id")
+	err := change(&entities.Permission{ResourceID: resourceID}, roles)
+	if err != nil {
+		abortWithDBError(ctx, err)
+		return
+	}
+
+	res, err := h.database.GetResourceData(resourceID)
+	if err != nil {
+		abortWithDBError(ctx, err)
+		return
+	}
+	ctx.JSON(200, response.NewSuccessData(res))
+}
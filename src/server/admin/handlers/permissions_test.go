@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"fsrv/src/database/entities"
+	"fsrv/src/types"
+	"github.com/go-playground/assert/v2"
+	"testing"
+)
+
+func TestHandler_Permissions(t *testing.T) {
+	r, db := newTestHandler(t)
+	err := db.CreateRole(&entities.Role{ID: "reader"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.CreateResource(&entities.Resource{ID: "res"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := doRequest(r, "POST", "/admin/resources/res/permissions", `{"roles":["reader","*"],"operation":"read","allow":true}`)
+	assert.Equal(t, 200, w.Code)
+	res := decodeData[entities.Resource](t, w)
+	assert.Equal(t, map[entities.ResourceOperationAccess]bool{
+		{ID: "reader", Type: types.OperationRead}: true,
+		{ID: "*", Type: types.OperationRead}:      true,
+	}, res.OperationNodes)
+
+	// granting again replaces the existing node
+	w = doRequest(r, "POST", "/admin/resources/res/permissions", `{"roles":["reader"],"operation":"read","allow":false}`)
+	assert.Equal(t, 200, w.Code)
+	res = decodeData[entities.Resource](t, w)
+	assert.Equal(t, false, res.OperationNodes[entities.ResourceOperationAccess{ID: "reader", Type: types.OperationRead}])
+	assert.Equal(t, 2, len(res.OperationNodes))
+
+	w = doRequest(r, "DELETE", "/admin/resources/res/permissions?operation=read&role=*", "")
+	assert.Equal(t, 200, w.Code)
+	res = decodeData[entities.Resource](t, w)
+	assert.Equal(t, map[entities.ResourceOperationAccess]bool{
+		{ID: "reader", Type: types.OperationRead}: false,
+	}, res.OperationNodes)
+
+	w = doRequest(r, "POST", "/admin/resources/res/permissions", `{"roles":["missing"],"operation":"write","allow":true}`)
+	assert.Equal(t, 404, w.Code)
+	w = doRequest(r, "POST", "/admin/resources/missing/permissions", `{"roles":["reader"],"operation":"write","allow":true}`)
+	assert.Equal(t, 404, w.Code)
+	w = doRequest(r, "POST", "/admin/resources/res/permissions", `{"roles":["reader"],"operation":"execute"}`)
+	assert.Equal(t, 400, w.Code)
+	w = doRequest(r, "GET", "/admin/resources/res/permissions", "")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, 1, len(decodeData[entities.Resource](t, w).OperationNodes))
+}
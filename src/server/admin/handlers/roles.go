@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"fsrv/src/database/entities"
+	"fsrv/src/types/response"
+	"github.com/gin-gonic/gin"
+)
+
+// ListRoles represents a request to list the names of roles,
+// ordered by precedence.
+//
+//	Middleware Dependencies:
+//	 GetQuery (limit)
+//	 GetQuery (offset)
+func (h *Handler) ListRoles() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		roles, err := h.database.GetRoles(ctx.GetInt("limit"), ctx.GetInt("offset"))
+		if err != nil {
+			abortWithDBError(ctx, err)
+			return
+		}
+		if roles == nil {
+			roles = []string{}
+		}
+		ctx.JSON(200, response.NewSuccessData(roles))
+	}
+}
+
+// CreateRole represents a request to create a role.
+func (h *Handler) CreateRole() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var role entities.Role
+		if !bindJSON(ctx, &role) {
+			return
+		}
+
+		err := h.database.CreateRole(&role)
+		if err != nil {
+			abortWithDBError(ctx, err)
+			return
+		}
+		ctx.JSON(201, response.NewSuccessData(&role))
+	}
+}
+
+// GetRole represents a request to get a role by its name.
+func (h *Handler) GetRole() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		role, err := h.database.GetRoleData(ctx.Param("id"))
+		if err != nil {
+			abortWithDBError(ctx, err)
+			return
+		}
+		ctx.JSON(200, response.NewSuccessData(role))
+	}
+}
+
+// DeleteRole represents a request to delete a role, which
+// is taken from every key and resource which has it.
+func (h *Handler) DeleteRole() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		err := h.database.DeleteRole(ctx.Param("id"))
+		if err != nil {
+			abortWithDBError(ctx, err)
+			return
+		}
+		ctx.JSON(200, response.EmptySuccess)
+	}
+}
+
+// GiveRole represents a request to give a role to a key.
+// The response contains the updated key.
+func (h *Handler) GiveRole() gin.HandlerFunc {
+	return h.changeRole(func(keyID, role string) error {
+		return h.database.GiveRole(keyID, role)
+	})
+}
+
+// TakeRole represents a request to take a role from a key.
+// The response contains the updated key.
+func (h *Handler) TakeRole() gin.HandlerFunc {
+	return h.changeRole(func(keyID, role string) error {
+		return h.database.TakeRole(keyID, role)
+	})
+}
+
+func (h *Handler) changeRole(change func(keyID, role string) error) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		keyID := ctx.Param("id")
+		err := change(keyID, ctx.Param("role"))
+		if err != nil {
+			abortWithDBError(ctx, err)
+			return
+		}
+
+		key, err := h.database.GetKeyData(keyID)
+		if err != nil {
+			abortWithDBError(ctx, err)
+			return
+		}
+		ctx.JSON(200, response.NewSuccessData(key))
+	}
+}
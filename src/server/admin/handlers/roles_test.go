@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"fsrv/src/database/entities"
+	"github.com/go-playground/assert/v2"
+	"testing"
+)
+
+func TestHandler_Roles(t *testing.T) {
+	r, _ := newTestHandler(t)
+
+	w := doRequest(r, "POST", "/admin/roles", `{"id":"writer","precedence":20}`)
+	assert.Equal(t, 201, w.Code)
+	w = doRequest(r, "POST", "/admin/roles", `{"id":"reader","precedence":10}`)
+	assert.Equal(t, 201, w.Code)
+	w = doRequest(r, "POST", "/admin/roles", `{"id":"reader","precedence":30}`)
+	assert.Equal(t, 409, w.Code)
+	w = doRequest(r, "POST", "/admin/roles", `{"id":"*"}`)
+	assert.Equal(t, 400, w.Code)
+
+	w = doRequest(r, "GET", "/admin/roles", "")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, []string{"reader", "writer"}, decodeData[[]string](t, w))
+
+	w = doRequest(r, "GET", "/admin/roles/writer", "")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, 20, decodeData[entities.Role](t, w).Precedence)
+
+	w = doRequest(r, "POST", "/admin/keys", `{}`)
+	keyID := decodeData[entities.Key](t, w).ID
+
+	w = doRequest(r, "PUT", "/admin/keys/"+keyID+"/roles/writer", "")
+	assert.Equal(t, 200, w.Code)
+	w = doRequest(r, "PUT", "/admin/keys/"+keyID+"/roles/reader", "")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, []string{"reader", "writer", keyID}, decodeData[entities.Key](t, w).Roles)
+	w = doRequest(r, "PUT", "/admin/keys/"+keyID+"/roles/missing", "")
+	assert.Equal(t, 404, w.Code)
+
+	w = doRequest(r, "DELETE", "/admin/keys/"+keyID+"/roles/reader", "")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, []string{"writer", keyID}, decodeData[entities.Key](t, w).Roles)
+	w = doRequest(r, "DELETE", "/admin/keys/"+keyID+"/roles/"+keyID, "")
+	assert.Equal(t, 400, w.Code)
+
+	w = doRequest(r, "DELETE", "/admin/roles/writer", "")
+	assert.Equal(t, 200, w.Code)
+	w = doRequest(r, "GET", "/admin/keys/"+keyID, "")
+	assert.Equal(t, []string{keyID}, decodeData[entities.Key](t, w).Roles)
+	w = doRequest(r, "DELETE", "/admin/roles/"+keyID, "")
+	assert.Equal(t, 404, w.Code)
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"fsrv/src/database/entities"
+	"fsrv/src/types/response"
+	"fsrv/utils/keygen"
+	"fsrv/utils/serde"
+	"github.com/gin-gonic/gin"
+	"time"
+)
+
+// tokenRandomBytes is the number of random bytes in an admin token.
+const tokenRandomBytes = 32
+
+// TokenRequest represents the fields of a token
+// which may be set when it is created.
+type TokenRequest struct {
+	// Comment is used to note the owner or usage of the token.
+	Comment string `json:"comment"`
+	// ExpiresAt is the time when the token expires,
+	// in unix milliseconds. 0 means it never expires.
+	ExpiresAt serde.Time `json:"expires_at"`
+}
+
+// CreatedToken represents a token created by a create request.
+type CreatedToken struct {
+	*entities.Token
+	// Plaintext is the token used to authenticate. It is only
+	// returned when the token is created, as the server only
+	// stores its hash, which is used as the id of the token.
+	Plaintext string `json:"token"`
+}
+
+// NewToken generates a new admin token which expires at the
+// given time, or never if it is the unix epoch. It returns
+// the token to store, and the token used to authenticate.
+func NewToken(comment string, expiresAt time.Time) (*entities.Token, string) {
+	plaintext := base64.RawURLEncoding.EncodeToString(keygen.GetRand(tokenRandomBytes))
+	return &entities.Token{
+		ID:        keygen.HashKey(plaintext),
+		Comment:   comment,
+		ExpiresAt: serde.Time(expiresAt),
+		CreatedAt: serde.Time(time.Now()),
+	}, plaintext
+}
+
+// ListTokens represents a request to list admin tokens.
+//
+//	Middleware Dependencies:
+//	 GetQuery (limit)
+//	 GetQuery (offset)
+func (h *Handler) ListTokens() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		tokens, err := h.database.GetTokens(ctx.GetInt("limit"), ctx.GetInt("offset"))
+		if err != nil {
+			abortWithDBError(ctx, err)
+			return
+		}
+		if tokens == nil {
+			tokens = []*entities.Token{}
+		}
+		ctx.JSON(200, response.NewSuccessData(tokens))
+	}
+}
+
+// CreateToken represents a request to create a new admin token.
+func (h *Handler) CreateToken() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		req := TokenRequest{ExpiresAt: serde.Time(time.Unix(0, 0))}
+		if !bindJSON(ctx, &req) {
+			return
+		}
+
+		token, plaintext := NewToken(req.Comment, time.Time(req.ExpiresAt))
+		err := h.database.CreateToken(token)
+		if err != nil {
+			abortWithDBError(ctx, err)
+			return
+		}
+
+		ctx.JSON(201, response.NewSuccessData(&CreatedToken{
+			Token:     token,
+			Plaintext: plaintext,
+		}))
+	}
+}
+
+// DeleteToken represents a request to revoke an admin token.
+// If every token is revoked, a new bootstrap token is generated
+// when the admin server is next started.
+func (h *Handler) DeleteToken() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		err := h.database.DeleteToken(ctx.Param("id"))
+		if err != nil {
+			abortWithDBError(ctx, err)
+			return
+		}
+		ctx.JSON(200, response.EmptySuccess)
+	}
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"fsrv/src/database"
+	"fsrv/utils/keygen"
+	"github.com/go-playground/assert/v2"
+	"testing"
+)
+
+func TestHandler_Tokens(t *testing.T) {
+	r, db := newTestHandler(t)
+
+	w := doRequest(r, "POST", "/admin/tokens", `{"comment":"ci"}`)
+	assert.Equal(t, 201, w.Code)
+	created := decodeData[struct {
+		ID    string `json:"id"`
+		Token string `json:"token"`
+	}](t, w)
+	assert.Equal(t, keygen.HashKey(created.Token), created.ID)
+
+	token, err := db.GetTokenData(created.ID)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "ci", token.Comment)
+	assert.Equal(t, false, token.IsExpired())
+
+	w = doRequest(r, "GET", "/admin/tokens", "")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, 1, len(decodeData[[]any](t, w)))
+
+	w = doRequest(r, "DELETE", "/admin/tokens/"+created.ID, "")
+	assert.Equal(t, 200, w.Code)
+	_, err = db.GetTokenData(created.ID)
+	assert.Equal(t, database.ErrTokenMissing, err)
+}
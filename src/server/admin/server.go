@@ -1,19 +1,30 @@
-package files
+package admin
 
 import (
+	"context"
+	"fmt"
+	"fsrv/src/access"
 	"fsrv/src/config"
 	"fsrv/src/database"
 	"fsrv/src/filemanager"
+	"fsrv/src/logging"
+	"fsrv/src/server/admin/adminmw"
 	"fsrv/src/server/admin/handlers"
 	"fsrv/src/server/middleware"
 	"github.com/gin-gonic/gin"
+	"log"
+	"net"
 	"net/http"
+	"os"
+	"time"
 )
 
 type Server struct {
 	config      *config.Config
 	database    database.DBInterface
 	fileManager *filemanager.FileManager
+	http        *http.Server
+	active      *middleware.ActiveRequests
 }
 
 func New(cfg *config.Config, db database.DBInterface, fm *filemanager.FileManager) *Server {
@@ -21,15 +32,79 @@ func New(cfg *config.Config, db database.DBInterface, fm *filemanager.FileManage
 		config:      cfg,
 		database:    db,
 		fileManager: fm,
+		http:        &http.Server{},
+		active:      middleware.NewActiveRequests(),
 	}
 }
 
+// Start listens on a tcp address and serves requests.
 func (s *Server) Start(addr string) error {
-	r := gin.Default()
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Serve serves requests on a listener until the server is shut
+// down, after which it returns http.ErrServerClosed.
+func (s *Server) Serve(l net.Listener) error {
+	err := s.bootstrapToken()
+	if err != nil {
+		l.Close()
+		return err
+	}
+
+	s.http.ErrorLog = log.New(logging.Default().Writer(logging.WARNING), "", 0)
+	r := gin.New()
+	r.Use(s.active.Track())
+	r.Use(middleware.RequestID())
+	r.Use(middleware.Metrics("admin"))
 	r.Use(middleware.GetIP())
-	//r.Use(filesmw.UnifiedRateLimit(s.database, s.config.Server))
-	//r.Use(filesmw.Auth(s.database, s.config.FileManager))
-	//
-	handlers.New(s.database, s.fileManager).Register(r)
-	return http.ListenAndServe(addr, r)
+	r.Use(middleware.LogRequests())
+	r.Use(gin.RecoveryWithWriter(logging.Default().Writer(logging.ERROR)))
+	r.Use(adminmw.TokenAuth(s.database))
+
+	resolver := access.New(s.database, s.fileManager, s.config.FileManager)
+	handlers.New(s.config.Server, s.database, s.fileManager, resolver).Register(r)
+	s.http.Handler = r
+	return s.http.Serve(l)
+}
+
+// Shutdown stops the server from accepting requests, and waits for active
+// requests to finish until the context is done, after which their connections
+// are closed and their handlers are waited for.
+func (s *Server) Shutdown(ctx context.Context) error {
+	err := s.http.Shutdown(ctx)
+	if err != nil {
+		_ = s.http.Close()
+	}
+	s.active.Wait()
+	return err
+}
+
+// bootstrapToken creates an admin token which never expires if no
+// tokens exist, such as on the first start, and prints it to stderr.
+// It is the only time the token is shown, and it is never logged, so
+// it is not kept in log files.
+func (s *Server) bootstrapToken() error {
+	tokens, err := s.database.GetTokens(1, 0)
+	if err != nil {
+		return err
+	}
+	if len(tokens) > 0 {
+		return nil
+	}
+
+	token, plaintext := handlers.NewToken("bootstrap", time.Unix(0, 0))
+	err = s.database.CreateToken(token)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(os.Stderr, "bootstrap admin token (it will not be shown again): %s\n", plaintext)
+	if err != nil {
+		return err
+	}
+	logging.Noticef("no admin tokens exist; created bootstrap admin token '%s' and printed it to stderr", token.ID)
+	return nil
 }
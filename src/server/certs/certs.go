@@ -0,0 +1,177 @@
+// Package certs provides the TLS configuration of the file server,
+// reloading its certificate when the files change, and identifies
+// keys by the client certificates of mutual TLS.
+package certs
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"fsrv/src/config"
+	"fsrv/src/logging"
+	"fsrv/utils"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultReloadInterval is how often the certificate files
+// are checked for changes, if no interval is configured.
+const DefaultReloadInterval = time.Minute
+
+const (
+	// FingerprintPrefix prefixes the hex encoded sha256 fingerprint
+	// of a client certificate in the client keys configuration.
+	FingerprintPrefix = "sha256:"
+	// SubjectPrefix prefixes the subject of a client certificate,
+	// such as 'CN=host1,O=fleet', in the client keys configuration.
+	SubjectPrefix = "subject:"
+)
+
+// Reloader holds a certificate and its private key, which
+// are loaded again whenever their files are modified.
+type Reloader struct {
+	certFile string
+	keyFile  string
+
+	mux     sync.RWMutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+// NewReloader loads a certificate and its private key.
+func NewReloader(certFile, keyFile string) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile}
+	_, err := r.Reload()
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload loads the certificate and private key again if either
+// file was modified since they were last loaded, and returns
+// whether they were. If loading fails, the previous certificate
+// is kept.
+func (r *Reloader) Reload() (bool, error) {
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return false, err
+	}
+
+	r.mux.RLock()
+	unchanged := r.cert != nil && modTime.Equal(r.modTime)
+	r.mux.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return false, err
+	}
+
+	r.mux.Lock()
+	r.cert = &cert
+	r.modTime = modTime
+	r.mux.Unlock()
+	return true, nil
+}
+
+// Watch reloads the certificate at an interval, until done is closed.
+func (r *Reloader) Watch(interval time.Duration, done <-chan struct{}) {
+	stop := utils.Executor(interval, func() {
+		reloaded, err := r.Reload()
+		if err != nil {
+			logging.Errorf("error reloading tls certificate: %v", err)
+		} else if reloaded {
+			logging.Infof("reloaded tls certificate %s", r.certFile)
+		}
+	})
+	go func() {
+		<-done
+		stop <- struct{}{}
+	}()
+}
+
+// GetCertificate returns the current certificate, for use in tls.Config.
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	return r.cert, nil
+}
+
+func (r *Reloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, name := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(name)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// ServerConfig creates the TLS configuration for a server, whose
+// certificate is reloaded at the configured interval until done is
+// closed. If a client certificate authority is configured, clients
+// may present certificates issued by it, and must if required.
+func ServerConfig(cfg *config.TLS, done <-chan struct{}) (*tls.Config, error) {
+	reloader, err := NewReloader(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsCfg := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}
+
+	if cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(cfg.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", cfg.ClientCAFile)
+		}
+		tlsCfg.ClientCAs = pool
+		tlsCfg.ClientAuth = tls.VerifyClientCertIfGiven
+		if cfg.RequireClientCert {
+			tlsCfg.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	} else if cfg.RequireClientCert {
+		return nil, errors.New("require_client_cert requires a client_ca_file")
+	}
+
+	interval := cfg.ReloadInterval
+	if interval <= 0 {
+		interval = DefaultReloadInterval
+	}
+	reloader.Watch(interval, done)
+	return tlsCfg, nil
+}
+
+// Fingerprint returns the hex encoded sha256 fingerprint of a certificate.
+func Fingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// ClientKeyID returns the id of the key which a client certificate
+// identifies, from a map of identities to key ids. The certificate is
+// identified first by its fingerprint, then by its subject.
+func ClientKeyID(cert *x509.Certificate, keys map[string]string) (string, bool) {
+	if id, ok := keys[FingerprintPrefix+Fingerprint(cert)]; ok {
+		return id, true
+	}
+	id, ok := keys[SubjectPrefix+cert.Subject.String()]
+	return id, ok
+}
@@ -0,0 +1,137 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fsrv/src/config"
+	"github.com/go-playground/assert/v2"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeCert writes a new self-signed certificate and its private key.
+func writeCert(t *testing.T, certFile, keyFile, name string) *x509.Certificate {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name, Organization: []string{"fleet"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &priv.PublicKey, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	first := writeCert(t, certFile, keyFile, "first")
+
+	r, err := NewReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := r.GetCertificate(nil)
+	assert.Equal(t, first.Raw, cert.Certificate[0])
+
+	// unchanged files are not loaded again
+	reloaded, err := r.Reload()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, reloaded)
+
+	second := writeCert(t, certFile, keyFile, "second")
+	later := time.Now().Add(time.Minute)
+	if err = os.Chtimes(certFile, later, later); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err = r.Reload()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, reloaded)
+	cert, _ = r.GetCertificate(nil)
+	assert.Equal(t, second.Raw, cert.Certificate[0])
+
+	// a bad certificate keeps the previous one
+	err = os.WriteFile(certFile, []byte("bad"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	later = later.Add(time.Minute)
+	if err = os.Chtimes(certFile, later, later); err != nil {
+		t.Fatal(err)
+	}
+	_, err = r.Reload()
+	assert.NotEqual(t, nil, err)
+	cert, _ = r.GetCertificate(nil)
+	assert.Equal(t, second.Raw, cert.Certificate[0])
+}
+
+func TestServerConfig(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	writeCert(t, certFile, keyFile, "server")
+	done := make(chan struct{})
+	defer close(done)
+
+	_, err := ServerConfig(&config.TLS{CertFile: certFile, KeyFile: keyFile, RequireClientCert: true}, done)
+	assert.NotEqual(t, nil, err)
+
+	tlsCfg, err := ServerConfig(&config.TLS{CertFile: certFile, KeyFile: keyFile, ClientCAFile: certFile}, done)
+	assert.Equal(t, nil, err)
+	assert.NotEqual(t, nil, tlsCfg.ClientCAs)
+}
+
+func TestClientKeyID(t *testing.T) {
+	dir := t.TempDir()
+	cert := writeCert(t, filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem"), "host1")
+
+	keys := map[string]string{
+		SubjectPrefix + "CN=host1,O=fleet": "subject-key",
+	}
+	id, ok := ClientKeyID(cert, keys)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, "subject-key", id)
+
+	// the fingerprint takes precedence over the subject
+	keys[FingerprintPrefix+Fingerprint(cert)] = "fingerprint-key"
+	id, _ = ClientKeyID(cert, keys)
+	assert.Equal(t, "fingerprint-key", id)
+
+	_, ok = ClientKeyID(cert, map[string]string{SubjectPrefix + "CN=host2,O=fleet": "other"})
+	assert.Equal(t, false, ok)
+}
@@ -1,102 +1,67 @@
 package filesmw
 
 import (
-	"context"
-	"fsrv/src/config"
-	"fsrv/src/database"
+	"fsrv/src/access"
 	"fsrv/src/database/entities"
-	"fsrv/src/types"
+	"fsrv/src/filemanager"
+	"fsrv/src/server/middleware"
 	"fsrv/src/types/response"
 	"github.com/gin-gonic/gin"
-	"log"
-	"net/http"
-	"path/filepath"
-	"strings"
-	"time"
 )
 
-// Auth verifies that the issuer a request has authority to take a given action on the resource in question
-func Auth(db database.DBInterface, cfg *config.FileManager) gin.HandlerFunc {
-	root := cfg.Path
-	return func(ctx *gin.Context) {
-		c, cancel := context.WithTimeout(ctx, 10*time.Second)
-		defer cancel()
-
-		authHandler(ctx, db, root, extractResPath(ctx))
-		c.Done()
-	}
-}
-
-func authHandler(ctx *gin.Context, db database.DBInterface, root, path string) {
-	dir := http.Dir(root)
-
-	//get resource data
-	resID, ok := extractResourceID(&dir, path)
-	if !ok {
-		ctx.AbortWithStatusJSON(403, response.Unauthorized)
-		return
-	}
-
-	res, err := db.GetResourceData(resID)
-	if err != nil {
-		ctx.AbortWithStatusJSON(403, response.Unauthorized)
-		return
-	}
+const (
+	// ExplainRequestHeader is the header which requests an explanation
+	// of the access to a path, if explanations are enabled.
+	ExplainRequestHeader = "X-Fsrv-Explain"
+	// ExplainResponseHeader is the header which contains the explanation.
+	ExplainResponseHeader = "X-Fsrv-Access-Explanation"
+)
 
-	//get key string
-	authKey, keyGiven := extractKey(ctx)
+// Auth verifies that the issuer of a request has authority to take the
+// requested action on the file or directory in question. Access is
+// inherited from parent directories, as determined by the resolver.
+// If explain is true, and the request has the ExplainRequestHeader
+// header, a summary of how access was determined is returned in the
+// ExplainResponseHeader header, including when access is denied.
+//
+//	Middleware Dependencies:
+//	 ClassifyOperation
+//	 UnifiedRateLimit (optional: key)
+func Auth(resolver *access.Resolver, fm *filemanager.FileManager, explain bool) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var key *entities.Key
+		if value, ok := ctx.Get("key"); ok {
+			key = value.(*entities.Key)
+		}
 
-	if !keyGiven {
-		//evaluate access based on resource flags
-		switch res.Flags {
-		case entities.FlagPublicRead:
-			if ctx.Request.Method != http.MethodGet {
-				ctx.Next()
-				return
+		name := fm.CleanPath(extractResPath(ctx))
+		var status entities.AccessStatus
+		var err error
+		if explain && ctx.GetHeader(ExplainRequestHeader) != "" {
+			var exp *access.Explanation
+			exp, err = resolver.Explain(key, name, getOperation(ctx))
+			if err == nil {
+				ctx.Header(ExplainResponseHeader, exp.String())
+				status = exp.Status
 			}
-		default:
-			log.Fatalf("Bad resource flag value \"%d\"", res.Flags)
+		} else {
+			status, err = resolver.Check(key, name, getOperation(ctx))
+		}
+		if err != nil {
+			middleware.GetLogger(ctx).Errorf("error checking access: %v", err)
+			ctx.AbortWithStatusJSON(500, response.InternalServerError)
+			return
 		}
 
-		ctx.Set("Resource", res)
-		ctx.Next()
-		return
-	}
-
-	key, err := db.GetKeyData(authKey)
-	if err != nil {
-		ctx.AbortWithStatusJSON(500, response.InternalServerError)
-		return
-	}
-
-	//check key expiry
-	if key.IsExpired() {
-		ctx.AbortWithStatusJSON(401, response.ForbiddenExpiredKey)
-		return
-	}
+		if status != entities.AccessAllowed {
+			if key == nil {
+				ctx.AbortWithStatusJSON(401, response.Unauthorized)
+			} else {
+				ctx.AbortWithStatusJSON(403, response.Forbidden)
+			}
+			return
+		}
 
-	//evaluate access based on roles
-	status := res.CheckAccess(key, getAccessType(ctx))
-	switch status {
-	case entities.AccessAllowed:
-		ctx.Set("resource", res)
-		ctx.Set("key", key)
-		ctx.Set("path", path)
 		ctx.Next()
-	case entities.AccessDenied:
-		ctx.AbortWithStatusJSON(401, response.ForbiddenExpiredKey)
-		return
-	case entities.AccessNeutral:
-		authHandler(ctx, db, root, filepath.Dir(strings.TrimSuffix(root, "/")))
-	}
-}
-
-func getAccessType(ctx *gin.Context) types.OperationType {
-	switch ctx.Request.Method {
-	case http.MethodGet:
-		return types.OperationRead
-	default:
-		log.Fatalf("Bad access type \"%s\"", ctx.Request.Method)
 	}
-	return -1
 }
@@ -0,0 +1,26 @@
+package filesmw
+
+import (
+	"fsrv/src/server/certs"
+	"github.com/gin-gonic/gin"
+)
+
+// ClientCertificate identifies the key of a request by the verified client
+// certificate presented over mutual TLS, if any, using a map of certificate
+// fingerprints or subjects to key ids. Requests with no certificate, or a
+// certificate which does not identify a key, are left unchanged.
+//
+//	Added Context Fields:
+//	 certKeyID -> string (optional)
+func ClientCertificate(keys map[string]string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		state := ctx.Request.TLS
+		if state != nil && len(state.VerifiedChains) > 0 && len(state.VerifiedChains[0]) > 0 {
+			if keyID, ok := certs.ClientKeyID(state.VerifiedChains[0][0], keys); ok {
+				ctx.Set("certKeyID", keyID)
+			}
+		}
+
+		ctx.Next()
+	}
+}
@@ -2,16 +2,22 @@ package filesmw
 
 import (
 	"fsrv/src/database/entities"
+	"fsrv/src/metrics"
 	"fsrv/src/types"
 	"fsrv/src/types/response"
 	"fsrv/utils/syncmap"
 	"github.com/gin-gonic/gin"
 )
 
-// ConcurrentRequestLimit
+// ConcurrentRequestLimit limits the number of requests each client,
+// identified by its key or otherwise its ip, may have in progress at
+// once. Reads are limited by readLimit, and writes, modifications and
+// deletions by writeLimit, and a limit of 0 does not limit them. Other
+// requests are rejected with 429.
 //
 //	Middleware Dependencies:
 //	 GetIP
+//	 ClassifyOperation
 func ConcurrentRequestLimit(readLimit, writeLimit int) gin.HandlerFunc {
 	readMap := syncmap.New[string, int]()
 	writeMap := syncmap.New[string, int]()
@@ -20,7 +26,7 @@ func ConcurrentRequestLimit(readLimit, writeLimit int) gin.HandlerFunc {
 		// limit and counts map for this request
 		var limit int
 		var counts *syncmap.CountMap[string, int]
-		switch crlGetMode(ctx) {
+		switch getOperation(ctx) {
 		case types.OperationRead:
 			limit = readLimit
 			counts = readMap
@@ -28,6 +34,10 @@ func ConcurrentRequestLimit(readLimit, writeLimit int) gin.HandlerFunc {
 			limit = writeLimit
 			counts = writeMap
 		}
+		if limit <= 0 {
+			ctx.Next()
+			return
+		}
 
 		// client id (key id or ip)
 		var id string
@@ -41,22 +51,15 @@ func ConcurrentRequestLimit(readLimit, writeLimit int) gin.HandlerFunc {
 		// check if the count is less than the limit, and
 		// increment it if so. otherwise, 429 and exit.
 		if !counts.CompareLessAndIncrement(id, limit) {
+			metrics.ConcurrentRequestRejections.With(getOperation(ctx).String()).Inc()
 			ctx.AbortWithStatusJSON(429, response.TooManyConcurrentRequests)
 			return
 		}
 
+		// decrement the counter when done, even if a handler panics
+		defer counts.Decrement(id)
+
 		// run the other request handlers
 		ctx.Next()
-
-		// decrement the counter when done
-		counts.Decrement(id)
 	}
 }
-
-// TODO: get the mode based on the information about the request.
-//
-//	maybe involve another prerequisite adminmw to assign the
-//	request type to the context for use within here and ratelimiting.
-func crlGetMode(ctx *gin.Context) types.OperationType {
-	return types.OperationRead
-}
@@ -0,0 +1,48 @@
+package filesmw
+
+import (
+	"fsrv/src/types"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestConcurrentRequestLimit(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	r := gin.New()
+	r.Use(func(ctx *gin.Context) {
+		ctx.Set("ip", "127.0.0.1")
+		op, _ := types.ParseOperationType(ctx.Query("op"))
+		ctx.Set("operation", op)
+	})
+	r.Use(ConcurrentRequestLimit(1, 0))
+	r.GET("/", func(ctx *gin.Context) {
+		if ctx.Query("block") != "" {
+			started <- struct{}{}
+			<-release
+		}
+		ctx.Status(204)
+	})
+
+	serve := func(query string) int {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/?"+query, nil))
+		return w.Code
+	}
+
+	done := make(chan int)
+	go func() {
+		done <- serve("op=read&block=1")
+	}()
+	<-started
+
+	// the read limit is reached, but writes are not limited
+	assert.Equal(t, 429, serve("op=read"))
+	assert.Equal(t, 204, serve("op=write"))
+
+	close(release)
+	assert.Equal(t, 204, <-done)
+	assert.Equal(t, 204, serve("op=read"))
+}
@@ -2,15 +2,8 @@ package filesmw
 
 import (
 	"github.com/gin-gonic/gin"
-	"github.com/pkg/xattr"
-	"net/http"
-	"path/filepath"
-	"strings"
 )
 
-const xAttributeNS = "user.fsrv."
-const xAttributeResource = xAttributeNS + "resourceid"
-
 func extractKey(ctx *gin.Context) (string, bool) {
 	auth := ctx.GetHeader("authorization")
 	if len(auth) > 0 {
@@ -22,22 +15,3 @@ func extractKey(ctx *gin.Context) (string, bool) {
 func extractResPath(ctx *gin.Context) string {
 	return ctx.Request.URL.Path
 }
-
-func extractResourceID(dir *http.Dir, path string) (string, bool) {
-	for {
-		//check if valid
-		_, err := dir.Open(string(*dir) + path)
-
-		if err != nil {
-			return "", false
-		}
-
-		resourceID, err := xattr.Get(path, xAttributeResource)
-		//if resource id attribute is found
-		if err == nil {
-			return string(resourceID), true
-		}
-
-		path = filepath.Dir(strings.TrimSuffix(path, "/")) //get parent directory
-	}
-}
@@ -15,7 +15,7 @@ func ObtainKey(db database.DBInterface) gin.HandlerFunc {
 			return
 		}
 
-		ctx.Set("key", &key)
+		ctx.Set("key", key)
 		ctx.Next()
 	}
 }
@@ -0,0 +1,51 @@
+package filesmw
+
+import (
+	"fsrv/src/filemanager"
+	"fsrv/src/types"
+	"fsrv/src/types/response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"os"
+)
+
+// ClassifyOperation determines the type of operation a request
+// performs on a file or directory and assigns it to the context.
+//
+// GET and HEAD read, POST writes, PATCH modifies and DELETE deletes.
+// PUT writes a new file, or modifies the file if it already exists.
+// Requests with any other method fail with code 405.
+//
+//	Added Context Fields:
+//	 operation -> types.OperationType
+func ClassifyOperation(fm *filemanager.FileManager) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var op types.OperationType
+		switch ctx.Request.Method {
+		case http.MethodGet, http.MethodHead:
+			op = types.OperationRead
+		case http.MethodPost:
+			op = types.OperationWrite
+		case http.MethodPatch:
+			op = types.OperationModify
+		case http.MethodPut:
+			op = types.OperationWrite
+			if _, err := os.Lstat(fm.CleanPath(extractResPath(ctx))); err == nil {
+				op = types.OperationModify
+			}
+		case http.MethodDelete:
+			op = types.OperationDelete
+		default:
+			ctx.AbortWithStatusJSON(405, response.MethodNotAllowed)
+			return
+		}
+
+		ctx.Set("operation", op)
+		ctx.Next()
+	}
+}
+
+// getOperation returns the operation type assigned by ClassifyOperation.
+func getOperation(ctx *gin.Context) types.OperationType {
+	return ctx.MustGet("operation").(types.OperationType)
+}
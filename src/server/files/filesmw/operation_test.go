@@ -0,0 +1,56 @@
+package filesmw
+
+import (
+	"fsrv/src/config"
+	"fsrv/src/filemanager"
+	"fsrv/src/types"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestClassifyOperation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "exists.txt"), nil, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fm := filemanager.New(&config.FileManager{Path: dir, MaxDepth: 5}, nil)
+
+	tests := []struct {
+		method string
+		path   string
+		code   int
+		op     types.OperationType
+	}{
+		{"GET", "/exists.txt", 200, types.OperationRead},
+		{"HEAD", "/exists.txt", 200, types.OperationRead},
+		{"POST", "/new.txt", 200, types.OperationWrite},
+		{"PATCH", "/exists.txt", 200, types.OperationModify},
+		{"PUT", "/new.txt", 200, types.OperationWrite},
+		{"PUT", "/exists.txt", 200, types.OperationModify},
+		{"DELETE", "/exists.txt", 200, types.OperationDelete},
+		{"OPTIONS", "/exists.txt", 405, 0},
+	}
+
+	for _, test := range tests {
+		var op types.OperationType
+		r := gin.New()
+		r.Use(ClassifyOperation(fm))
+		r.Handle(test.method, "/*path", func(ctx *gin.Context) {
+			op = getOperation(ctx)
+		})
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(test.method, test.path, nil))
+		assert.Equal(t, w.Code, test.code)
+		if test.code == 200 {
+			assert.Equal(t, op, test.op)
+		}
+	}
+}
@@ -2,18 +2,20 @@ package filesmw
 
 import (
 	"bytes"
-	"database/sql"
 	"encoding/base64"
 	"fsrv/src/config"
 	"fsrv/src/database"
 	"fsrv/src/database/entities"
+	"fsrv/src/logging"
+	"fsrv/src/metrics"
+	"fsrv/src/server/middleware"
+	"fsrv/src/types"
 	"fsrv/src/types/response"
 	"fsrv/utils"
+	"fsrv/utils/keygen"
 	"fsrv/utils/syncrl"
 	"github.com/gin-gonic/gin"
 	"github.com/zytekaron/gorl"
-	"log"
-	"math"
 	"sync"
 	"time"
 )
@@ -34,25 +36,32 @@ import (
 
 const urlRateLimitPurgeInterval = 10 * time.Minute
 
-// UnifiedRateLimit
+// UnifiedRateLimit limits the rate of requests by ip and by key. The
+// rate limiters are purged periodically, until done is closed.
 //
 //	Middleware Dependencies:
 //	 GetIP
+//	 ClassifyOperation
+//	 ClientCertificate (optional)
 //
 //	Added Context Fields:
 //	 key -> entities.Key (optional)
-func UnifiedRateLimit(db database.DBInterface, serverCfg *config.Server) gin.HandlerFunc {
+func UnifiedRateLimit(db database.DBInterface, serverCfg *config.Server, done <-chan struct{}) gin.HandlerFunc {
 	anonRLManager := unifiedNewRL(serverCfg.IPAnonymousRL)
 	attemptRLManager := unifiedNewRL(serverCfg.AuthAttemptRL)
 	defaultRLManager := unifiedNewRL(serverCfg.AuthDefaultRL)
 
 	keyRLSuite := syncrl.New()
-	utils.Executor(urlRateLimitPurgeInterval, func() {
+	purge := utils.Executor(urlRateLimitPurgeInterval, func() {
 		anonRLManager.Purge()
 		attemptRLManager.Purge()
 		defaultRLManager.Purge()
 		keyRLSuite.PurgeAll()
 	})
+	go func() {
+		<-done
+		purge <- struct{}{}
+	}()
 
 	// checks whether the key was minted by the server.
 	isValidKeyID := unifiedKeySourceValidator(
@@ -65,19 +74,110 @@ func UnifiedRateLimit(db database.DBInterface, serverCfg *config.Server) gin.Han
 	// to add their own identical managers to the suite.
 	var suiteModMux sync.Mutex
 
+	// limits the rate of requests by an authenticated key,
+	// using the bucket name to identify the key.
+	limitKey := func(ctx *gin.Context, key *entities.Key, bucket string) {
+		ip := ctx.GetString("ip")
+
+		// if the key doesn't specify a rate limit id,
+		// use the default authenticated rate limit.
+		if key.RateLimitID == "" {
+			sb := defaultRLManager.Get(ip)
+			if !sb.Draw(1) {
+				metrics.RateLimitRejections.With("default").Inc()
+				ctx.AbortWithStatusJSON(429, response.TooManyRequests)
+				return
+			}
+
+			ctx.Next()
+			return
+		}
+
+		// attempt to get a manager for the key's rate limit id.
+		suiteModMux.Lock()
+		keyBM, ok := keyRLSuite.Get(key.RateLimitID)
+		if !ok {
+			// one case per rate limit id: a manager does not exist. create one.
+			rateLimit, err := db.GetRateLimitData(key.RateLimitID)
+			if err != nil {
+				// always an issue with the server. if the rate limit doesn't exist, the issue
+				// is caused by bad administration. otherwise, a database issue was encountered.
+				if err == database.ErrRateLimitMissing {
+					middleware.GetLogger(ctx).Errorf("missing rate limit in database with id: %s", key.RateLimitID)
+				} else {
+					middleware.GetLogger(ctx).Errorf("error getting rate limit from database: %v", err)
+				}
+
+				suiteModMux.Unlock()
+				ctx.AbortWithStatusJSON(500, response.InternalServerError)
+				return
+			}
+
+			// create and add a bucket manager instance for this rate limit level.
+			keyBM = unifiedNewRL(rateLimit)
+			keyRLSuite.Put(key.RateLimitID, keyBM)
+		}
+		suiteModMux.Unlock()
+
+		// the key has passed validation checks. now, just verify
+		// that the key has not exceeded its own rate limit.
+		if !keyBM.Draw(bucket, 1) {
+			metrics.RateLimitRejections.With("key").Inc()
+			ctx.AbortWithStatusJSON(429, response.TooManyRequests)
+			return
+		}
+
+		// successful authentication: continue to next handler
+		ctx.Next()
+	}
+
 	return func(ctx *gin.Context) {
 		ip := ctx.GetString("ip")
 
+		// a verified client certificate identifies the key directly,
+		// so there is no key to validate or authentication attempt.
+		if certKeyID := ctx.GetString("certKeyID"); certKeyID != "" {
+			key, err := db.GetKeyData(certKeyID)
+			if err != nil {
+				// the certificate is mapped to a key which does not exist.
+				if err == database.ErrKeyMissing {
+					middleware.GetLogger(ctx).Errorf("missing key in database for client certificate with id: %s", certKeyID)
+					ctx.AbortWithStatusJSON(403, response.Forbidden)
+					return
+				}
+
+				middleware.GetLogger(ctx).Errorf("error getting key from database: %v", err)
+				ctx.AbortWithStatusJSON(500, response.InternalServerError)
+				return
+			}
+			ctx.Set("key", key)
+
+			if key.IsExpired() {
+				ctx.AbortWithStatusJSON(403, response.ForbiddenExpiredKey)
+				return
+			}
+
+			limitKey(ctx, key, key.ID)
+			return
+		}
+
 		// extract a key id from the request.
 		keyID, ok := extractKey(ctx)
 		if !ok {
 			// no key provided: fallback to ip-based rate limiting.
 			sb := anonRLManager.Get(ip)
 			if !sb.Draw(1) {
+				metrics.RateLimitRejections.With("anonymous").Inc()
 				ctx.AbortWithStatusJSON(429, response.TooManyRequests)
 				return
 			}
 
+			// anonymous clients may only ever read public resources.
+			if getOperation(ctx) != types.OperationRead {
+				ctx.AbortWithStatusJSON(401, response.Unauthorized)
+				return
+			}
+
 			ctx.Next()
 			return
 		}
@@ -89,6 +189,7 @@ func UnifiedRateLimit(db database.DBInterface, serverCfg *config.Server) gin.Han
 		// key provided: ensure the client has not exceeded
 		// the allowed number of key authentication attempts.
 		if !attemptBucket.CanDraw(1) {
+			metrics.RateLimitRejections.With("attempt").Inc()
 			ctx.AbortWithStatusJSON(429, response.TooManyRequests)
 			return
 		}
@@ -101,8 +202,9 @@ func UnifiedRateLimit(db database.DBInterface, serverCfg *config.Server) gin.Han
 			return
 		}
 
-		// attempt to get the key from the database.
-		key, err := db.GetKeyData(keyID)
+		// attempt to get the key from the database,
+		// where it is stored by its hash.
+		key, err := db.GetKeyData(keygen.HashKey(keyID))
 		if err != nil {
 			// if the key doesn't exist, draw from the attempt bucket.
 			if err == database.ErrKeyMissing {
@@ -112,7 +214,8 @@ func UnifiedRateLimit(db database.DBInterface, serverCfg *config.Server) gin.Han
 			}
 
 			// database query issue
-			log.Println("error getting key from database:", err)
+			middleware.GetLogger(ctx).Errorf("error getting key from database: %v", err)
+			ctx.AbortWithStatusJSON(500, response.InternalServerError)
 			return
 		}
 		ctx.Set("key", key)
@@ -125,53 +228,7 @@ func UnifiedRateLimit(db database.DBInterface, serverCfg *config.Server) gin.Han
 			return
 		}
 
-		// if the key doesn't specify a rate limit id,
-		// use the default authenticated rate limit.
-		if key.RateLimitID == "" {
-			sb := defaultRLManager.Get(ip)
-			if !sb.Draw(1) {
-				ctx.AbortWithStatusJSON(429, response.TooManyRequests)
-				return
-			}
-
-			ctx.Next()
-			return
-		}
-
-		// attempt to get a manager for the key's rate limit id.
-		suiteModMux.Lock()
-		keyBM, ok := keyRLSuite.Get(key.RateLimitID)
-		if !ok {
-			// one case per rate limit id: a manager does not exist. create one.
-			rateLimit, err := db.GetRateLimitData(key.RateLimitID)
-			if err != nil {
-				// always an issue with the server. if the rate limit doesn't exist, the issue
-				// is caused by bad administration. otherwise, a database issue was encountered.
-				if err == sql.ErrNoRows {
-					log.Println("missing rate limit in database with id:", key.RateLimitID)
-				} else {
-					log.Println("error getting rate limit from database:", err)
-				}
-
-				ctx.AbortWithStatusJSON(500, response.InternalServerError)
-				return
-			}
-
-			// create and add a bucket manager instance for this rate limit level.
-			keyBM = unifiedNewRL(rateLimit)
-			keyRLSuite.Put(key.RateLimitID, keyBM)
-		}
-		suiteModMux.Unlock()
-
-		// the key has passed validation checks. now, just verify
-		// that the key has not exceeded its own rate limit.
-		if !keyBM.Draw(keyID, 1) {
-			ctx.AbortWithStatusJSON(429, response.TooManyRequests)
-			return
-		}
-
-		// successful authentication: continue to next handler
-		ctx.Next()
+		limitKey(ctx, key, keyID)
 	}
 }
 
@@ -180,13 +237,12 @@ func unifiedNewRL(rl *entities.RateLimit) *gorl.BucketManager {
 }
 
 func unifiedKeySourceValidator(randomBytes, checksumBytes int, salt []byte) func(string) bool {
-	const b64repMlt float64 = 1 / (6.0 / 8) // base64 representation multiplier
-
 	if checksumBytes > 64 {
-		log.Fatal("unifiedKeySourceValidator: checksumBytes cannot be greater than 64 because sha512 produces 64 byte output")
+		logging.Fatalf("unifiedKeySourceValidator: checksumBytes cannot be greater than 64 because sha512 produces 64 byte output")
 	}
 
-	size := int(math.Ceil(b64repMlt*float64(randomBytes)) + math.Ceil(b64repMlt*float64(checksumBytes)))
+	// the random data and checksum are encoded together.
+	size := base64.RawURLEncoding.EncodedLen(randomBytes + checksumBytes)
 
 	return func(keyStr string) bool {
 		if len(keyStr) != size {
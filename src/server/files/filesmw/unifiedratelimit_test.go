@@ -0,0 +1,20 @@
+package filesmw
+
+import (
+	"fsrv/utils/keygen"
+	"github.com/go-playground/assert/v2"
+	"testing"
+)
+
+func TestUnifiedKeySourceValidator(t *testing.T) {
+	for _, size := range [][2]int{{32, 8}, {31, 5}, {16, 1}} {
+		randomBytes, checksumBytes := size[0], size[1]
+		isValidKeyID := unifiedKeySourceValidator(randomBytes, checksumBytes, []byte("secret"))
+
+		key := keygen.MintKey(keygen.GetRand(randomBytes), []byte("secret"), checksumBytes)
+		assert.Equal(t, true, isValidKeyID(key))
+
+		other := keygen.MintKey(keygen.GetRand(randomBytes), []byte("other"), checksumBytes)
+		assert.Equal(t, false, isValidKeyID(other))
+	}
+}
@@ -0,0 +1,16 @@
+package handlers
+
+import (
+	"fsrv/src/database/entities"
+	"github.com/gin-gonic/gin"
+)
+
+// getKey returns the key which authenticated the request, or nil.
+func getKey(ctx *gin.Context) *entities.Key {
+	value, ok := ctx.Get("key")
+	if !ok {
+		return nil
+	}
+	key, _ := value.(*entities.Key)
+	return key
+}
@@ -1,9 +1,126 @@
 package handlers
 
-import "github.com/gin-gonic/gin"
+import (
+	"fsrv/src/types/response"
+	"github.com/gin-gonic/gin"
+	"io"
+	"mime"
+	"path"
+	"path/filepath"
+)
 
+// CreatedFile represents a file written by a create request.
+type CreatedFile struct {
+	// Path is the path of the file, relative to the file server root.
+	Path string `json:"path"`
+	// Size is the number of bytes written to the file.
+	Size int64 `json:"size"`
+}
+
+// Create represents a request to create one or more new files.
+//
+// A multipart/form-data request creates a file for every file part
+// in the directory at the request path, named after the part's file
+// name. Any other request creates a file at the request path with
+// the request body as its contents.
+//
+// Existing files are never overwritten; Update is used to modify them.
+//
+// With restore=<id>, the request instead restores the trash item
+// with the given id, which must have been deleted from the request path.
+//
+//	Middleware Dependencies:
+//	 GetQuery (restore)
 func (h *Handler) Create() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
+		urlPath, _, ok := h.resolvePath(ctx)
+		if !ok {
+			return
+		}
+
+		if id := ctx.GetString("restore"); id != "" {
+			h.restore(ctx, urlPath, id)
+			return
+		}
+
+		mediaType, _, _ := mime.ParseMediaType(ctx.GetHeader("Content-Type"))
+		if mediaType == "multipart/form-data" {
+			h.createMultipart(ctx, urlPath)
+			return
+		}
+
+		if urlPath == "/" {
+			ctx.AbortWithStatusJSON(400, response.InvalidFileName)
+			return
+		}
+
+		file, ok := h.createFile(ctx, urlPath, ctx.Request.Body)
+		if !ok {
+			return
+		}
+		ctx.JSON(201, response.NewSuccessData([]*CreatedFile{file}))
+	}
+}
+
+// createMultipart creates a file in the directory at urlPath for
+// every file part in a multipart/form-data request. Files created
+// before a failing part are kept.
+func (h *Handler) createMultipart(ctx *gin.Context, urlPath string) {
+	reader, err := ctx.Request.MultipartReader()
+	if err != nil {
+		ctx.AbortWithStatusJSON(400, response.NewErrorMessage("error reading multipart body: "+err.Error()))
+		return
+	}
+
+	var files []*CreatedFile
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			ctx.AbortWithStatusJSON(400, response.NewErrorMessage("error reading multipart body: "+err.Error()))
+			return
+		}
+
+		// FileName strips any directories from the name.
+		name := part.FileName()
+		if name == "" {
+			// not a file
+			continue
+		}
+		if name == "." || name == ".." || name == "/" {
+			ctx.AbortWithStatusJSON(400, response.InvalidFileName)
+			return
+		}
+
+		file, ok := h.createFile(ctx, path.Join(urlPath, name), part)
+		if !ok {
+			return
+		}
+		files = append(files, file)
+	}
+
+	if len(files) == 0 {
+		ctx.AbortWithStatusJSON(400, response.NewErrorMessage("no files were provided"))
+		return
+	}
+	ctx.JSON(201, response.NewSuccessData(files))
+}
+
+// createFile creates a file at urlPath with the contents of r,
+// aborting the request and returning false if it fails.
+func (h *Handler) createFile(ctx *gin.Context, urlPath string, r io.Reader) (*CreatedFile, bool) {
+	name := h.fileManager.CleanPath(filepath.FromSlash(urlPath))
+	if h.fileManager.IsReserved(name) {
+		ctx.AbortWithStatusJSON(400, response.InvalidFileName)
+		return nil, false
+	}
 
+	n, err := h.fileManager.Create(name, r)
+	if err != nil {
+		abortWithFileError(ctx, err)
+		return nil, false
 	}
+	return &CreatedFile{Path: urlPath, Size: n}, true
 }
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"bytes"
+	"github.com/go-playground/assert/v2"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHandler_Create(t *testing.T) {
+	r, dir := newTestHandler(t)
+
+	w := doRequest(r, "POST", "/one/two/file.txt", strings.NewReader("content"), nil)
+	assert.Equal(t, w.Code, 201)
+	data, err := os.ReadFile(filepath.Join(dir, "one/two/file.txt"))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, string(data), "content")
+
+	// existing files are not overwritten
+	w = doRequest(r, "POST", "/one/two/file.txt", strings.NewReader("replaced"), nil)
+	assert.Equal(t, w.Code, 409)
+	data, _ = os.ReadFile(filepath.Join(dir, "one/two/file.txt"))
+	assert.Equal(t, string(data), "content")
+
+	// a parent is a file
+	w = doRequest(r, "POST", "/one/two/file.txt/nested", strings.NewReader("content"), nil)
+	assert.Equal(t, w.Code, 409)
+
+	// directories may only be created up to the maximum depth
+	w = doRequest(r, "POST", "/one/two/three/file.txt", strings.NewReader("content"), nil)
+	assert.Equal(t, w.Code, 201)
+	w = doRequest(r, "POST", "/one/two/three/four/file.txt", strings.NewReader("content"), nil)
+	assert.Equal(t, w.Code, 403)
+	_, err = os.Stat(filepath.Join(dir, "one/two/three/four"))
+	assert.Equal(t, os.IsNotExist(err), true)
+
+	// no temporary files are left behind
+	entries, _ := os.ReadDir(filepath.Join(dir, "one/two"))
+	assert.Equal(t, len(entries), 2)
+}
+
+func TestHandler_CreateMultipart(t *testing.T) {
+	r, dir := newTestHandler(t)
+
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+	_ = mw.WriteField("comment", "ignored")
+	fw, _ := mw.CreateFormFile("file", "a.txt")
+	_, _ = fw.Write([]byte("aaa"))
+	fw, _ = mw.CreateFormFile("file", "../../b.txt")
+	_, _ = fw.Write([]byte("bb"))
+	_ = mw.Close()
+
+	w := doRequest(r, "POST", "/uploads", body, map[string]string{"Content-Type": mw.FormDataContentType()})
+	assert.Equal(t, w.Code, 201)
+	assert.Equal(t, strings.Contains(w.Body.String(), `{"path":"/uploads/b.txt","size":2}`), true)
+
+	data, _ := os.ReadFile(filepath.Join(dir, "uploads/a.txt"))
+	assert.Equal(t, string(data), "aaa")
+	data, _ = os.ReadFile(filepath.Join(dir, "uploads/b.txt"))
+	assert.Equal(t, string(data), "bb")
+}
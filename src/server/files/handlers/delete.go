@@ -1,9 +1,127 @@
 package handlers
 
-import "github.com/gin-gonic/gin"
+import (
+	"errors"
+	"fsrv/src/database/entities"
+	"fsrv/src/logging"
+	"fsrv/src/server/middleware"
+	"fsrv/src/types"
+	"fsrv/src/types/response"
+	"github.com/gin-gonic/gin"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
 
+// DeletedFile represents a file or directory removed by a delete request.
+type DeletedFile struct {
+	// Path is the path of the file, relative to the file server root.
+	Path string `json:"path"`
+	// TrashID is the id of the trash item which may be used to
+	// restore the file, if the trash is enabled.
+	TrashID string `json:"trash_id,omitempty"`
+}
+
+var errDeleteDenied = errors.New("delete denied")
+
+// Delete represents a request to delete a file or directory.
+//
+// Directories are only deleted with recursive=true, and only if the
+// client may delete every file and directory within them which has
+// its own access specifiers.
+//
+//	Middleware Dependencies:
+//	 GetQuery (recursive)
 func (h *Handler) Delete() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
+		urlPath, name, ok := h.resolvePath(ctx)
+		if !ok {
+			return
+		}
+		if urlPath == "/" {
+			ctx.AbortWithStatusJSON(403, response.Forbidden)
+			return
+		}
+
+		info, err := os.Lstat(name)
+		if err != nil {
+			abortWithFileError(ctx, err)
+			return
+		}
+
+		if info.IsDir() {
+			if !ctx.GetBool("recursive") {
+				ctx.AbortWithStatusJSON(400, response.RecursiveRequired)
+				return
+			}
+
+			err = h.checkDeleteDescendants(middleware.GetLogger(ctx), getKey(ctx), name)
+			if err != nil {
+				if err == errDeleteDenied {
+					ctx.AbortWithStatusJSON(403, response.Forbidden)
+					return
+				}
+				abortWithFileError(ctx, err)
+				return
+			}
+		}
+
+		item, err := h.fileManager.Delete(name, info.IsDir())
+		if err != nil {
+			abortWithFileError(ctx, err)
+			return
+		}
+
+		deleted := &DeletedFile{Path: urlPath}
+		if item != nil {
+			deleted.TrashID = item.ID
+		}
+		ctx.JSON(200, response.NewSuccessData(deleted))
+	}
+}
+
+// checkDeleteDescendants walks a directory, returning errDeleteDenied
+// if the key is denied permission to delete any of its contents.
+func (h *Handler) checkDeleteDescendants(logger *logging.Logger, key *entities.Key, dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == dir {
+			// access to the directory itself was already checked.
+			return nil
+		}
+
+		status, err := h.resolver.CheckPath(key, path, types.OperationDelete)
+		if err != nil {
+			logger.Errorf("error checking access for recursive delete: %v", err)
+			return errDeleteDenied
+		}
+		if status == entities.AccessDenied {
+			return errDeleteDenied
+		}
+		return nil
+	})
+}
+
+// restore restores a trash item which was deleted from urlPath.
+func (h *Handler) restore(ctx *gin.Context, urlPath, id string) {
+	item, err := h.fileManager.TrashItem(id)
+	if err != nil {
+		abortWithFileError(ctx, err)
+		return
+	}
+	// the client's access was checked against the request path, so
+	// the item may only be restored to the path it was deleted from.
+	if item.Path != urlPath {
+		ctx.AbortWithStatusJSON(404, response.TrashItemMissing)
+		return
+	}
 
+	item, err = h.fileManager.Restore(id)
+	if err != nil {
+		abortWithFileError(ctx, err)
+		return
 	}
+	ctx.JSON(200, response.NewSuccessData(item))
 }
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fsrv/src/database/entities"
+	"fsrv/src/types"
+	"fsrv/src/types/response"
+	"github.com/go-playground/assert/v2"
+	"github.com/pkg/xattr"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHandler_Delete(t *testing.T) {
+	r, dir := newTestHandler(t)
+	writeTestFile(t, dir, "dir/file.txt", "content")
+	writeTestFile(t, dir, "dir/sub/other.txt", "content")
+
+	w := doRequest(r, "DELETE", "/dir", nil, nil)
+	assert.Equal(t, w.Code, 400)
+
+	w = doRequest(r, "DELETE", "/", nil, nil)
+	assert.Equal(t, w.Code, 403)
+
+	w = doRequest(r, "DELETE", "/dir/file.txt", nil, nil)
+	assert.Equal(t, w.Code, 200)
+	_, err := os.Stat(filepath.Join(dir, "dir/file.txt"))
+	assert.Equal(t, os.IsNotExist(err), true)
+
+	w = doRequest(r, "DELETE", "/dir?recursive=true", nil, nil)
+	assert.Equal(t, w.Code, 200)
+	_, err = os.Stat(filepath.Join(dir, "dir"))
+	assert.Equal(t, os.IsNotExist(err), true)
+
+	w = doRequest(r, "DELETE", "/dir", nil, nil)
+	assert.Equal(t, w.Code, 404)
+
+	// the trash is not accessible
+	w = doRequest(r, "GET", "/.trash", nil, nil)
+	assert.Equal(t, w.Code, 404)
+}
+
+func TestHandler_DeleteRestore(t *testing.T) {
+	r, dir := newTestHandler(t)
+	writeTestFile(t, dir, "dir/file.txt", "content")
+
+	w := doRequest(r, "DELETE", "/dir?recursive=true", nil, nil)
+	assert.Equal(t, w.Code, 200)
+
+	var res response.Response[DeletedFile]
+	_ = json.Unmarshal(w.Body.Bytes(), &res)
+	assert.NotEqual(t, res.Data.TrashID, "")
+
+	// items may only be restored to their original path
+	w = doRequest(r, "POST", "/other?restore="+res.Data.TrashID, nil, nil)
+	assert.Equal(t, w.Code, 404)
+
+	w = doRequest(r, "POST", "/dir?restore="+res.Data.TrashID, nil, nil)
+	assert.Equal(t, w.Code, 200)
+	data, _ := os.ReadFile(filepath.Join(dir, "dir/file.txt"))
+	assert.Equal(t, string(data), "content")
+
+	w = doRequest(r, "POST", "/dir?restore="+res.Data.TrashID, nil, nil)
+	assert.Equal(t, w.Code, 404)
+}
+
+func TestHandler_DeleteProtectedDescendant(t *testing.T) {
+	db := &resourceDB{resources: map[string]*entities.Resource{
+		"protected": {
+			ID: "protected",
+			OperationNodes: map[entities.ResourceOperationAccess]bool{
+				{ID: "*", Type: types.OperationDelete}: false,
+			},
+		},
+	}}
+	r, dir := newTestHandlerWithDB(t, db)
+	writeTestFile(t, dir, "dir/sub/file.txt", "content")
+
+	err := xattr.Set(filepath.Join(dir, "dir/sub/file.txt"), "user.fsrv.resourceid", []byte("protected"))
+	if err != nil {
+		t.Skip("extended attributes are not supported:", err)
+	}
+
+	w := doRequest(r, "DELETE", "/dir?recursive=true", nil, nil)
+	assert.Equal(t, w.Code, 403)
+	_, err = os.Stat(filepath.Join(dir, "dir/sub/file.txt"))
+	assert.Equal(t, err, nil)
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"errors"
+	"fsrv/src/filemanager"
+	"fsrv/src/server/middleware"
+	"fsrv/src/types/response"
+	"github.com/gin-gonic/gin"
+	"io/fs"
+)
+
+// abortWithFileError aborts the request with a response
+// appropriate to an error returned by a file operation.
+func abortWithFileError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		ctx.AbortWithStatusJSON(404, response.NotFound)
+	case errors.Is(err, fs.ErrPermission):
+		ctx.AbortWithStatusJSON(403, response.Forbidden)
+	case errors.Is(err, filemanager.ErrExists):
+		ctx.AbortWithStatusJSON(409, response.FileExists)
+	case errors.Is(err, filemanager.ErrNotDirectory):
+		ctx.AbortWithStatusJSON(409, response.NotDirectory)
+	case errors.Is(err, filemanager.ErrMaxDepth):
+		ctx.AbortWithStatusJSON(403, response.MaxDepthExceeded)
+	case errors.Is(err, filemanager.ErrIsDirectory):
+		ctx.AbortWithStatusJSON(400, response.IsDirectory)
+	case errors.Is(err, filemanager.ErrPreconditionFailed):
+		ctx.AbortWithStatusJSON(412, response.PreconditionFailed)
+	case errors.Is(err, filemanager.ErrInvalidRange):
+		ctx.AbortWithStatusJSON(416, response.InvalidRange)
+	case errors.Is(err, filemanager.ErrTrashItemMissing):
+		ctx.AbortWithStatusJSON(404, response.TrashItemMissing)
+	case errors.Is(err, filemanager.ErrShortWrite):
+		ctx.AbortWithStatusJSON(400, response.NewErrorMessage(err.Error()))
+	default:
+		middleware.GetLogger(ctx).Errorf("error accessing file: %v", err)
+		ctx.AbortWithStatusJSON(500, response.InternalServerError)
+	}
+}
@@ -1,26 +1,64 @@
 package handlers
 
 import (
+	"fsrv/src/access"
 	"fsrv/src/database"
 	"fsrv/src/filemanager"
+	"fsrv/src/server/middleware"
+	"fsrv/src/types/response"
 	"github.com/gin-gonic/gin"
+	"math"
+	"path"
+	"path/filepath"
 )
 
 type Handler struct {
 	database    database.DBInterface
 	fileManager *filemanager.FileManager
+	resolver    *access.Resolver
 }
 
-func New(db database.DBInterface, fm *filemanager.FileManager) *Handler {
+func New(db database.DBInterface, fm *filemanager.FileManager, resolver *access.Resolver) *Handler {
 	return &Handler{
 		database:    db,
 		fileManager: fm,
+		resolver:    resolver,
 	}
 }
 
 func (h *Handler) Register(r *gin.Engine) {
-	r.GET("/", h.Get())
-	r.POST("/", h.Create())
-	r.PATCH("/", h.Update())
-	r.DELETE("/", h.Delete())
+	listQueries := []gin.HandlerFunc{
+		middleware.GetQuery("limit", "limit", middleware.IntQuery(100, 1, 1000)),
+		middleware.GetQuery("offset", "offset", middleware.IntQuery(0, 0, math.MaxInt32)),
+		middleware.GetQuery("sort", "sort", middleware.EnumQuery("name", "size", "mtime")),
+		middleware.GetQuery("order", "order", middleware.EnumQuery("asc", "desc")),
+	}
+	r.GET("/*path", append(listQueries, h.Get())...)
+	r.HEAD("/*path", append(listQueries, h.Get())...)
+	r.POST("/*path",
+		middleware.GetQuery("restore", "restore", middleware.StringQuery()),
+		h.Create(),
+	)
+	r.PATCH("/*path",
+		middleware.GetQuery("mode", "mode", middleware.EnumQuery("replace", "append")),
+		h.Update(),
+	)
+	r.PUT("/*path", h.Put())
+	r.DELETE("/*path",
+		middleware.GetQuery("recursive", "recursive", middleware.BoolQuery(false)),
+		h.Delete(),
+	)
+}
+
+// resolvePath returns the path of a request relative to the file server
+// root, and the corresponding on-disk path. If the path is reserved by
+// the file manager, the request is aborted with 404 and ok is false.
+func (h *Handler) resolvePath(ctx *gin.Context) (urlPath, name string, ok bool) {
+	urlPath = path.Clean("/" + ctx.Param("path"))
+	name = h.fileManager.CleanPath(filepath.FromSlash(urlPath))
+	if h.fileManager.IsReserved(name) {
+		ctx.AbortWithStatusJSON(404, response.NotFound)
+		return "", "", false
+	}
+	return urlPath, name, true
 }
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"fsrv/src/access"
+	"fsrv/src/config"
+	"fsrv/src/database"
+	"fsrv/src/database/entities"
+	"fsrv/src/filemanager"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// resourceDB is a database which only stores resources.
+type resourceDB struct {
+	database.DBInterface
+	resources map[string]*entities.Resource
+}
+
+func (db *resourceDB) GetResourceData(resourceID string) (*entities.Resource, error) {
+	res, ok := db.resources[resourceID]
+	if !ok {
+		return nil, database.ErrResourceMissing
+	}
+	return res, nil
+}
+
+func newTestHandler(t *testing.T) (*gin.Engine, string) {
+	return newTestHandlerWithDB(t, nil)
+}
+
+func newTestHandlerWithDB(t *testing.T, db database.DBInterface) (*gin.Engine, string) {
+	gin.SetMode(gin.TestMode)
+
+	dir := t.TempDir()
+	cfg := &config.FileManager{
+		Path:     dir,
+		MaxDepth: 3,
+		Trash:    true,
+	}
+	fm := filemanager.New(cfg, nil)
+
+	r := gin.New()
+	New(db, fm, access.New(db, fm, cfg)).Register(r)
+	return r, dir
+}
+
+func writeTestFile(t *testing.T, dir, name, content string) {
+	path := filepath.Join(dir, name)
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func doRequest(r http.Handler, method, url string, body io.Reader, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, url, body)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestHandler_Get(t *testing.T) {
+	r, dir := newTestHandler(t)
+	writeTestFile(t, dir, "dir/file.txt", "hello, world")
+
+	w := doRequest(r, "GET", "/dir/file.txt", nil, nil)
+	assert.Equal(t, w.Code, 200)
+	assert.Equal(t, w.Body.String(), "hello, world")
+	assert.Equal(t, w.Header().Get("Content-Length"), "12")
+	assert.Equal(t, strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain"), true)
+
+	w = doRequest(r, "GET", "/dir/missing.txt", nil, nil)
+	assert.Equal(t, w.Code, 404)
+
+	w = doRequest(r, "GET", "/../../dir/file.txt", nil, nil)
+	assert.Equal(t, w.Code, 200)
+}
+
+func TestHandler_GetRange(t *testing.T) {
+	r, dir := newTestHandler(t)
+	writeTestFile(t, dir, "file.txt", "0123456789")
+
+	w := doRequest(r, "GET", "/file.txt", nil, map[string]string{"Range": "bytes=2-5"})
+	assert.Equal(t, w.Code, 206)
+	assert.Equal(t, w.Body.String(), "2345")
+	assert.Equal(t, w.Header().Get("Content-Range"), "bytes 2-5/10")
+
+	w = doRequest(r, "GET", "/file.txt", nil, map[string]string{"Range": "bytes=0-1,8-9"})
+	assert.Equal(t, w.Code, 206)
+	assert.Equal(t, strings.HasPrefix(w.Header().Get("Content-Type"), "multipart/byteranges"), true)
+
+	w = doRequest(r, "GET", "/file.txt", nil, map[string]string{"Range": "bytes=20-30"})
+	assert.Equal(t, w.Code, 416)
+}
+
+func TestHandler_GetConditional(t *testing.T) {
+	r, dir := newTestHandler(t)
+	writeTestFile(t, dir, "file.txt", "content")
+
+	w := doRequest(r, "GET", "/file.txt", nil, nil)
+	etag := w.Header().Get("ETag")
+	lastModified := w.Header().Get("Last-Modified")
+	assert.NotEqual(t, etag, "")
+
+	w = doRequest(r, "GET", "/file.txt", nil, map[string]string{"If-None-Match": etag})
+	assert.Equal(t, w.Code, 304)
+
+	w = doRequest(r, "GET", "/file.txt", nil, map[string]string{"If-None-Match": `"stale"`})
+	assert.Equal(t, w.Code, 200)
+
+	w = doRequest(r, "GET", "/file.txt", nil, map[string]string{"If-Modified-Since": lastModified})
+	assert.Equal(t, w.Code, 304)
+
+	w = doRequest(r, "GET", "/file.txt", nil, map[string]string{"Range": "bytes=0-2", "If-Range": `"stale"`})
+	assert.Equal(t, w.Code, 200)
+	assert.Equal(t, w.Body.String(), "content")
+}
@@ -1,12 +1,52 @@
 package handlers
 
 import (
+	"fsrv/src/filemanager"
+	"fsrv/src/types/response"
 	"github.com/gin-gonic/gin"
+	"net/http"
+	"os"
 )
 
-// Get represents a request to read the contents of a file
+// Get represents a request to read the contents of a file,
+// or to list the contents of a directory.
+//
+// The file is streamed to the client with its content type and
+// length. Range requests (including multiple ranges) and the
+// conditional headers If-Match, If-None-Match, If-Modified-Since,
+// If-Unmodified-Since and If-Range are supported using the file's
+// modification time and ETag.
 func (h *Handler) Get() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
+		_, name, ok := h.resolvePath(ctx)
+		if !ok {
+			return
+		}
 
+		file, err := os.Open(name)
+		if err != nil {
+			abortWithFileError(ctx, err)
+			return
+		}
+		defer file.Close()
+
+		info, err := file.Stat()
+		if err != nil {
+			abortWithFileError(ctx, err)
+			return
+		}
+		if info.IsDir() {
+			h.list(ctx, file)
+			return
+		}
+		if !info.Mode().IsRegular() {
+			ctx.AbortWithStatusJSON(404, response.NotFound)
+			return
+		}
+
+		// ServeContent takes care of range and conditional requests,
+		// and uses the ETag header (if set) for the latter.
+		ctx.Header("ETag", filemanager.ETag(info))
+		http.ServeContent(ctx.Writer, ctx.Request, info.Name(), info.ModTime(), file)
 	}
 }
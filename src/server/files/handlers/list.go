@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"fsrv/src/database/entities"
+	"fsrv/src/server/middleware"
+	"fsrv/src/types"
+	"fsrv/src/types/response"
+	"fsrv/utils/serde"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+	"html/template"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ListEntry represents a single file or directory in a directory listing.
+type ListEntry struct {
+	// Name is the name of the file or directory.
+	Name string `json:"name"`
+	// Size is the size of the file in bytes.
+	Size int64 `json:"size"`
+	// ModTime is the time the file was last modified.
+	ModTime serde.Time `json:"mtime"`
+	// IsDir is whether this entry is a directory.
+	IsDir bool `json:"is_dir"`
+	// Mime is the mime type of the file, based on its extension.
+	Mime string `json:"mime,omitempty"`
+}
+
+// Listing represents a single page of a directory listing.
+type Listing struct {
+	// Path is the path of the directory, relative to the file server root.
+	Path string `json:"path"`
+	// Total is the number of entries in the directory visible to the client.
+	Total int `json:"total"`
+	// Offset is the index of the first entry in this page.
+	Offset int `json:"offset"`
+	// Entries are the entries in this page.
+	Entries []*ListEntry `json:"entries"`
+}
+
+// list responds with a listing of an open directory,
+// omitting any entries the client may not read.
+//
+//	Middleware Dependencies:
+//	 GetQuery (limit, offset, sort, order)
+func (h *Handler) list(ctx *gin.Context, dir *os.File) {
+	dirEntries, err := dir.ReadDir(-1)
+	if err != nil {
+		abortWithFileError(ctx, err)
+		return
+	}
+
+	key := getKey(ctx)
+	entries := make([]*ListEntry, 0, len(dirEntries))
+	for _, dirEntry := range dirEntries {
+		name := filepath.Join(dir.Name(), dirEntry.Name())
+		if h.fileManager.IsReserved(name) {
+			continue
+		}
+
+		status, err := h.resolver.CheckPath(key, name, types.OperationRead)
+		if err != nil {
+			middleware.GetLogger(ctx).Errorf("error checking access for directory listing: %v", err)
+			continue
+		}
+		// the directory itself is readable, so entries without
+		// their own access specifiers inherit that permission.
+		if status == entities.AccessDenied {
+			continue
+		}
+
+		info, err := dirEntry.Info()
+		if err != nil {
+			// removed after the directory was read
+			continue
+		}
+
+		entry := &ListEntry{
+			Name:    info.Name(),
+			ModTime: serde.Time(info.ModTime()),
+			IsDir:   info.IsDir(),
+		}
+		if !entry.IsDir {
+			entry.Size = info.Size()
+			entry.Mime = mime.TypeByExtension(filepath.Ext(entry.Name))
+		}
+		entries = append(entries, entry)
+	}
+
+	sortEntries(entries, ctx.GetString("sort"), ctx.GetString("order") == "desc")
+
+	listing := &Listing{
+		Path:   path.Clean("/" + ctx.Param("path")),
+		Total:  len(entries),
+		Offset: ctx.GetInt("offset"),
+	}
+	start := listing.Offset
+	if start > len(entries) {
+		start = len(entries)
+	}
+	end := start + ctx.GetInt("limit")
+	if end > len(entries) {
+		end = len(entries)
+	}
+	listing.Entries = entries[start:end]
+
+	if ctx.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEHTML {
+		ctx.Render(200, render.HTML{
+			Template: listingTemplate,
+			Data:     newListingPage(ctx, listing),
+		})
+		return
+	}
+
+	ctx.JSON(200, response.NewSuccessData(listing))
+}
+
+// sortEntries sorts directory entries by the given field. Ties
+// are broken by name, and directories always sort before files.
+func sortEntries(entries []*ListEntry, field string, desc bool) {
+	less := func(a, b *ListEntry) bool {
+		switch field {
+		case "size":
+			if a.Size != b.Size {
+				return a.Size < b.Size
+			}
+		case "mtime":
+			at, bt := time.Time(a.ModTime), time.Time(b.ModTime)
+			if !at.Equal(bt) {
+				return at.Before(bt)
+			}
+		}
+		return a.Name < b.Name
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.IsDir != b.IsDir {
+			return a.IsDir
+		}
+		if desc {
+			return less(b, a)
+		}
+		return less(a, b)
+	})
+}
+
+type listingPage struct {
+	*Listing
+	Parent string
+	Prev   string
+	Next   string
+	Links  []string
+}
+
+// newListingPage prepares a listing for rendering as html. Links keep
+// the key used for this request, and pagination links also keep the
+// requested sorting and page size.
+func newListingPage(ctx *gin.Context, listing *Listing) *listingPage {
+	entryQuery := url.Values{}
+	pageQuery := url.Values{}
+	for _, name := range []string{"key", "sort", "order", "limit"} {
+		if value, ok := ctx.GetQuery(name); ok {
+			if name == "key" {
+				entryQuery.Set(name, value)
+			}
+			pageQuery.Set(name, value)
+		}
+	}
+
+	entryLink := func(name string) string {
+		u := url.URL{Path: path.Join(listing.Path, name), RawQuery: entryQuery.Encode()}
+		return u.String()
+	}
+	pageLink := func(offset int) string {
+		pageQuery.Set("offset", strconv.Itoa(offset))
+		u := url.URL{Path: listing.Path, RawQuery: pageQuery.Encode()}
+		return u.String()
+	}
+
+	page := &listingPage{Listing: listing}
+	if listing.Path != "/" {
+		page.Parent = entryLink("..")
+	}
+
+	limit := ctx.GetInt("limit")
+	if listing.Offset > 0 {
+		prev := listing.Offset - limit
+		if prev < 0 {
+			prev = 0
+		}
+		page.Prev = pageLink(prev)
+	}
+	if listing.Offset+len(listing.Entries) < listing.Total {
+		page.Next = pageLink(listing.Offset + limit)
+	}
+
+	for _, entry := range listing.Entries {
+		page.Links = append(page.Links, entryLink(entry.Name))
+	}
+	return page
+}
+
+var listingTemplate = template.Must(template.New("listing").Funcs(template.FuncMap{
+	"time": func(t serde.Time) string { return time.Time(t).Format("2006-01-02 15:04:05") },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Index of {{.Path}}</title>
+</head>
+<body>
+<h1>Index of {{.Path}}</h1>
+<table>
+<tr><th>Name</th><th>Size</th><th>Modified</th></tr>
+{{- if .Parent}}
+<tr><td><a href="{{.Parent}}">../</a></td><td></td><td></td></tr>
+{{- end}}
+{{- range $i, $e := .Entries}}
+<tr><td><a href="{{index $.Links $i}}">{{$e.Name}}{{if $e.IsDir}}/{{end}}</a></td><td>{{if not $e.IsDir}}{{$e.Size}}{{end}}</td><td>{{time $e.ModTime}}</td></tr>
+{{- end}}
+</table>
+<p>
+{{- if .Prev}}<a href="{{.Prev}}">previous</a> {{end}}
+{{- if .Next}}<a href="{{.Next}}">next</a>{{end}}
+</p>
+</body>
+</html>
+`))
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fsrv/src/database/entities"
+	"fsrv/src/types"
+	"fsrv/src/types/response"
+	"github.com/go-playground/assert/v2"
+	"github.com/pkg/xattr"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func decodeListing(t *testing.T, body []byte) *Listing {
+	var res response.Response[Listing]
+	err := json.Unmarshal(body, &res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res.Data
+}
+
+func listingNames(listing *Listing) []string {
+	var names []string
+	for _, entry := range listing.Entries {
+		names = append(names, entry.Name)
+	}
+	return names
+}
+
+func TestHandler_List(t *testing.T) {
+	r, dir := newTestHandler(t)
+	writeTestFile(t, dir, "b.txt", "bb")
+	writeTestFile(t, dir, "a.json", "aaa")
+	writeTestFile(t, dir, "c.txt", "c")
+	writeTestFile(t, dir, "sub/d.txt", "d")
+
+	w := doRequest(r, "GET", "/", nil, nil)
+	assert.Equal(t, w.Code, 200)
+	listing := decodeListing(t, w.Body.Bytes())
+	assert.Equal(t, listing.Total, 4)
+	assert.Equal(t, listingNames(listing), []string{"sub", "a.json", "b.txt", "c.txt"})
+	assert.Equal(t, listing.Entries[0].IsDir, true)
+	assert.Equal(t, listing.Entries[1].Mime, "application/json")
+
+	w = doRequest(r, "GET", "/?sort=size&order=desc", nil, nil)
+	assert.Equal(t, listingNames(decodeListing(t, w.Body.Bytes())), []string{"sub", "a.json", "b.txt", "c.txt"})
+
+	w = doRequest(r, "GET", "/?sort=size", nil, nil)
+	assert.Equal(t, listingNames(decodeListing(t, w.Body.Bytes())), []string{"sub", "c.txt", "b.txt", "a.json"})
+
+	w = doRequest(r, "GET", "/?limit=2&offset=1", nil, nil)
+	listing = decodeListing(t, w.Body.Bytes())
+	assert.Equal(t, listing.Total, 4)
+	assert.Equal(t, listing.Offset, 1)
+	assert.Equal(t, listingNames(listing), []string{"a.json", "b.txt"})
+
+	w = doRequest(r, "GET", "/?limit=0", nil, nil)
+	assert.Equal(t, w.Code, 400)
+
+	w = doRequest(r, "GET", "/sub", nil, map[string]string{"Accept": "text/html"})
+	assert.Equal(t, w.Code, 200)
+	assert.Equal(t, strings.Contains(w.Body.String(), `<a href="/sub/d.txt">d.txt</a>`), true)
+	assert.Equal(t, strings.Contains(w.Body.String(), `<a href="/">../</a>`), true)
+}
+
+func TestHandler_ListFiltersProtected(t *testing.T) {
+	db := &resourceDB{resources: map[string]*entities.Resource{
+		"secret": {
+			ID: "secret",
+			OperationNodes: map[entities.ResourceOperationAccess]bool{
+				{ID: "*", Type: types.OperationRead}: false,
+			},
+		},
+	}}
+	r, dir := newTestHandlerWithDB(t, db)
+	writeTestFile(t, dir, "public.txt", "public")
+	writeTestFile(t, dir, "secret.txt", "secret")
+
+	err := xattr.Set(filepath.Join(dir, "secret.txt"), "user.fsrv.resourceid", []byte("secret"))
+	if err != nil {
+		t.Skip("extended attributes are not supported:", err)
+	}
+
+	w := doRequest(r, "GET", "/", nil, nil)
+	assert.Equal(t, w.Code, 200)
+	assert.Equal(t, listingNames(decodeListing(t, w.Body.Bytes())), []string{"public.txt"})
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"fsrv/src/filemanager"
+	"fsrv/src/types"
+	"fsrv/src/types/response"
+	"github.com/gin-gonic/gin"
+	"os"
+)
+
+// Put represents a request to write the contents of a file, creating
+// it if it does not exist and replacing its contents otherwise.
+//
+// If-Match is checked against the file's ETag before it is replaced,
+// and the response contains the ETag of the file.
+//
+//	Middleware Dependencies:
+//	 ClassifyOperation (optional)
+func (h *Handler) Put() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		urlPath, name, ok := h.resolvePath(ctx)
+		if !ok {
+			return
+		}
+
+		// the client's access was checked for the operation
+		// determined by ClassifyOperation, so it must be used.
+		var op types.OperationType
+		if value, ok := ctx.Get("operation"); ok {
+			op = value.(types.OperationType)
+		} else if _, err := os.Lstat(name); err == nil {
+			op = types.OperationModify
+		} else {
+			op = types.OperationWrite
+		}
+
+		if op == types.OperationWrite {
+			if urlPath == "/" {
+				ctx.AbortWithStatusJSON(400, response.InvalidFileName)
+				return
+			}
+
+			file, ok := h.createFile(ctx, urlPath, ctx.Request.Body)
+			if !ok {
+				return
+			}
+			ctx.JSON(201, response.NewSuccessData(file))
+			return
+		}
+
+		info, err := h.fileManager.Modify(name, ctx.Request.Body, &filemanager.Modification{
+			Mode:         filemanager.WriteReplace,
+			Precondition: ifMatch(ctx.GetHeader("If-Match")),
+		})
+		if err != nil {
+			abortWithFileError(ctx, err)
+			return
+		}
+
+		ctx.Header("ETag", filemanager.ETag(info))
+		ctx.JSON(200, response.NewSuccessData(&UpdatedFile{
+			Path: urlPath,
+			Size: info.Size(),
+		}))
+	}
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"github.com/go-playground/assert/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHandler_Put(t *testing.T) {
+	r, dir := newTestHandler(t)
+
+	w := doRequest(r, "PUT", "/dir/file.txt", strings.NewReader("created"), nil)
+	assert.Equal(t, w.Code, 201)
+
+	w = doRequest(r, "PUT", "/dir/file.txt", strings.NewReader("replaced"), map[string]string{"If-Match": `"stale"`})
+	assert.Equal(t, w.Code, 412)
+
+	w = doRequest(r, "PUT", "/dir/file.txt", strings.NewReader("replaced"), nil)
+	assert.Equal(t, w.Code, 200)
+	data, _ := os.ReadFile(filepath.Join(dir, "dir/file.txt"))
+	assert.Equal(t, string(data), "replaced")
+
+	w = doRequest(r, "HEAD", "/dir/file.txt", nil, nil)
+	assert.Equal(t, w.Code, 200)
+	assert.Equal(t, w.Header().Get("Content-Length"), "8")
+}
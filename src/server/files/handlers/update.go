@@ -1,9 +1,139 @@
 package handlers
 
-import "github.com/gin-gonic/gin"
+import (
+	"errors"
+	"fsrv/src/filemanager"
+	"fsrv/src/types/response"
+	"github.com/gin-gonic/gin"
+	"os"
+	"strconv"
+	"strings"
+)
 
+// UpdatedFile represents a file written by an update request.
+type UpdatedFile struct {
+	// Path is the path of the file, relative to the file server root.
+	Path string `json:"path"`
+	// Size is the size of the file after the update.
+	Size int64 `json:"size"`
+}
+
+// Update represents a request to modify the contents of an existing file.
+//
+// By default, the request body replaces the contents of the file.
+// With mode=append, the body is appended to the file. With a
+// Content-Range header, the body is written at the given range.
+//
+// If-Match is checked against the file's ETag before it is written,
+// and the response contains the ETag of the updated file.
+//
+//	Middleware Dependencies:
+//	 GetQuery (mode)
 func (h *Handler) Update() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
+		urlPath, name, ok := h.resolvePath(ctx)
+		if !ok {
+			return
+		}
+
+		mod := &filemanager.Modification{
+			Precondition: ifMatch(ctx.GetHeader("If-Match")),
+		}
+		switch ctx.GetString("mode") {
+		case "replace":
+			mod.Mode = filemanager.WriteReplace
+		case "append":
+			mod.Mode = filemanager.WriteAppend
+		}
+
+		if contentRange := ctx.GetHeader("Content-Range"); contentRange != "" {
+			if mod.Mode == filemanager.WriteAppend {
+				ctx.AbortWithStatusJSON(400, response.NewErrorMessage("Content-Range cannot be used with mode=append"))
+				return
+			}
+
+			start, end, err := parseContentRange(contentRange)
+			if err != nil {
+				ctx.AbortWithStatusJSON(400, response.NewErrorMessage("error parsing Content-Range: "+err.Error()))
+				return
+			}
+
+			mod.Mode = filemanager.WriteAt
+			mod.Offset = start
+			mod.Length = end - start + 1
+			if ctx.Request.ContentLength >= 0 && ctx.Request.ContentLength != mod.Length {
+				ctx.AbortWithStatusJSON(400, response.NewErrorMessage("Content-Length does not match Content-Range"))
+				return
+			}
+		}
+
+		info, err := h.fileManager.Modify(name, ctx.Request.Body, mod)
+		if err != nil {
+			abortWithFileError(ctx, err)
+			return
+		}
+
+		ctx.Header("ETag", filemanager.ETag(info))
+		ctx.JSON(200, response.NewSuccessData(&UpdatedFile{
+			Path: urlPath,
+			Size: info.Size(),
+		}))
+	}
+}
+
+// ifMatch returns a precondition which checks a file's
+// ETag against the value of an If-Match header, or nil
+// if the header is not present.
+func ifMatch(header string) func(os.FileInfo) bool {
+	if header == "" {
+		return nil
+	}
+
+	return func(info os.FileInfo) bool {
+		etag := filemanager.ETag(info)
+		for _, value := range strings.Split(header, ",") {
+			value = strings.TrimSpace(value)
+			// weak tags never match, as If-Match uses strong comparison.
+			if value == "*" || value == etag {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// parseContentRange parses the first and last byte positions
+// of a Content-Range header in the form "bytes first-last/length",
+// where length may be "*".
+func parseContentRange(header string) (first, last int64, err error) {
+	const prefix = "bytes "
+	if !strings.HasPrefix(header, prefix) {
+		return 0, 0, errors.New("unit must be bytes")
+	}
+
+	rangeStr, lengthStr, ok := strings.Cut(strings.TrimPrefix(header, prefix), "/")
+	if !ok {
+		return 0, 0, errors.New("missing complete length")
+	}
+	firstStr, lastStr, ok := strings.Cut(rangeStr, "-")
+	if !ok {
+		return 0, 0, errors.New("invalid range")
+	}
+
+	first, err = strconv.ParseInt(firstStr, 10, 64)
+	if err != nil || first < 0 {
+		return 0, 0, errors.New("invalid first byte position")
+	}
+	last, err = strconv.ParseInt(lastStr, 10, 64)
+	if err != nil || last < first {
+		return 0, 0, errors.New("invalid last byte position")
+	}
 
+	if lengthStr != "*" {
+		length, err := strconv.ParseInt(lengthStr, 10, 64)
+		if err != nil || length <= last {
+			return 0, 0, errors.New("invalid complete length")
+		}
 	}
+	return first, last, nil
 }
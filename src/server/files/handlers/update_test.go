@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"github.com/go-playground/assert/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHandler_Update(t *testing.T) {
+	r, dir := newTestHandler(t)
+	writeTestFile(t, dir, "file.txt", "0123456789")
+	read := func() string {
+		data, _ := os.ReadFile(filepath.Join(dir, "file.txt"))
+		return string(data)
+	}
+
+	w := doRequest(r, "PATCH", "/file.txt", strings.NewReader("replaced"), nil)
+	assert.Equal(t, w.Code, 200)
+	assert.Equal(t, read(), "replaced")
+
+	w = doRequest(r, "PATCH", "/file.txt?mode=append", strings.NewReader(" and appended"), nil)
+	assert.Equal(t, w.Code, 200)
+	assert.Equal(t, read(), "replaced and appended")
+
+	w = doRequest(r, "PATCH", "/file.txt", strings.NewReader("REPL"), map[string]string{"Content-Range": "bytes 0-3/*"})
+	assert.Equal(t, w.Code, 200)
+	assert.Equal(t, read(), "REPLaced and appended")
+
+	// writes may extend the file, but may not leave a gap
+	w = doRequest(r, "PATCH", "/file.txt", strings.NewReader("!!"), map[string]string{"Content-Range": "bytes 21-22/*"})
+	assert.Equal(t, w.Code, 200)
+	assert.Equal(t, read(), "REPLaced and appended!!")
+	w = doRequest(r, "PATCH", "/file.txt", strings.NewReader("!!"), map[string]string{"Content-Range": "bytes 30-31/*"})
+	assert.Equal(t, w.Code, 416)
+
+	w = doRequest(r, "PATCH", "/file.txt", strings.NewReader("abc"), map[string]string{"Content-Range": "bytes 0-3/*"})
+	assert.Equal(t, w.Code, 400)
+
+	w = doRequest(r, "PATCH", "/missing.txt", strings.NewReader("content"), nil)
+	assert.Equal(t, w.Code, 404)
+}
+
+func TestHandler_UpdateIfMatch(t *testing.T) {
+	r, dir := newTestHandler(t)
+	writeTestFile(t, dir, "file.txt", "original")
+
+	etag := doRequest(r, "GET", "/file.txt", nil, nil).Header().Get("ETag")
+
+	w := doRequest(r, "PATCH", "/file.txt", strings.NewReader("first"), map[string]string{"If-Match": etag})
+	assert.Equal(t, w.Code, 200)
+	newETag := w.Header().Get("ETag")
+	assert.NotEqual(t, newETag, etag)
+
+	// the second client still has the original etag
+	w = doRequest(r, "PATCH", "/file.txt", strings.NewReader("second"), map[string]string{"If-Match": etag})
+	assert.Equal(t, w.Code, 412)
+
+	data, _ := os.ReadFile(filepath.Join(dir, "file.txt"))
+	assert.Equal(t, string(data), "first")
+
+	w = doRequest(r, "PATCH", "/file.txt", strings.NewReader("third"), map[string]string{"If-Match": `"other", ` + newETag})
+	assert.Equal(t, w.Code, 200)
+}
@@ -0,0 +1,23 @@
+package files
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// NewRedirect creates a server which redirects plain HTTP requests to
+// the same host and path over HTTPS, on the given port. The redirect
+// preserves the method, so that uploads are not turned into downloads.
+func NewRedirect(port int16) *http.Server {
+	return &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host
+		}
+		if port != 443 {
+			host = net.JoinHostPort(host, strconv.Itoa(int(port)))
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})}
+}
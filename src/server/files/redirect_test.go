@@ -0,0 +1,29 @@
+package files
+
+import (
+	"github.com/go-playground/assert/v2"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewRedirect(t *testing.T) {
+	tests := []struct {
+		port     int16
+		host     string
+		target   string
+		location string
+	}{
+		{443, "example.com", "/dir/file.txt?key=abc", "https://example.com/dir/file.txt?key=abc"},
+		{1337, "example.com:80", "/file.txt", "https://example.com:1337/file.txt"},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodPut, test.target, nil)
+		req.Host = test.host
+		w := httptest.NewRecorder()
+		NewRedirect(test.port).Handler.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusPermanentRedirect, w.Code)
+		assert.Equal(t, test.location, w.Header().Get("Location"))
+	}
+}
@@ -1,13 +1,19 @@
 package files
 
 import (
+	"context"
+	"fsrv/src/access"
 	"fsrv/src/config"
 	"fsrv/src/database"
 	"fsrv/src/filemanager"
+	"fsrv/src/logging"
+	"fsrv/src/server/certs"
 	"fsrv/src/server/files/filesmw"
 	"fsrv/src/server/files/handlers"
 	"fsrv/src/server/middleware"
 	"github.com/gin-gonic/gin"
+	"log"
+	"net"
 	"net/http"
 )
 
@@ -15,6 +21,9 @@ type Server struct {
 	config      *config.Config
 	database    database.DBInterface
 	fileManager *filemanager.FileManager
+	http        *http.Server
+	active      *middleware.ActiveRequests
+	done        chan struct{}
 }
 
 func New(cfg *config.Config, db database.DBInterface, fm *filemanager.FileManager) *Server {
@@ -22,15 +31,73 @@ func New(cfg *config.Config, db database.DBInterface, fm *filemanager.FileManage
 		config:      cfg,
 		database:    db,
 		fileManager: fm,
+		http:        &http.Server{},
+		active:      middleware.NewActiveRequests(),
+		done:        make(chan struct{}),
 	}
 }
 
+// Start listens on a tcp address and serves requests.
 func (s *Server) Start(addr string) error {
-	r := gin.Default()
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Serve serves requests on a listener until the server is shut
+// down, after which it returns http.ErrServerClosed. If TLS is
+// configured, requests are served over TLS.
+func (s *Server) Serve(l net.Listener) error {
+	tlsCfg := s.config.Server.TLS
+	if tlsCfg != nil {
+		var err error
+		s.http.TLSConfig, err = certs.ServerConfig(tlsCfg, s.done)
+		if err != nil {
+			l.Close()
+			return err
+		}
+	}
+
+	s.http.ErrorLog = log.New(logging.Default().Writer(logging.WARNING), "", 0)
+	r := gin.New()
+	r.Use(s.active.Track())
+	r.Use(middleware.RequestID())
+	r.Use(middleware.Metrics("files"))
 	r.Use(middleware.GetIP())
-	r.Use(filesmw.UnifiedRateLimit(s.database, s.config.Server))
-	r.Use(filesmw.Auth(s.database, s.config.FileManager))
+	r.Use(middleware.LogRequests())
+	r.Use(gin.RecoveryWithWriter(logging.Default().Writer(logging.ERROR)))
+	r.Use(filesmw.ClassifyOperation(s.fileManager))
+	if tlsCfg != nil && len(tlsCfg.ClientKeys) > 0 {
+		r.Use(filesmw.ClientCertificate(tlsCfg.ClientKeys))
+	}
+	r.Use(filesmw.UnifiedRateLimit(s.database, s.config.Server, s.done))
+	if s.config.Server.ConcurrentReads > 0 || s.config.Server.ConcurrentWrites > 0 {
+		r.Use(filesmw.ConcurrentRequestLimit(s.config.Server.ConcurrentReads, s.config.Server.ConcurrentWrites))
+	}
+	resolver := access.New(s.database, s.fileManager, s.config.FileManager)
+	r.Use(filesmw.Auth(resolver, s.fileManager, s.config.Server.ExplainAccess))
 
-	handlers.New(s.database, s.fileManager).Register(r)
-	return http.ListenAndServe(addr, r)
+	handlers.New(s.database, s.fileManager, resolver).Register(r)
+	s.http.Handler = r
+	if tlsCfg != nil {
+		// the certificate is provided by the tls config
+		return s.http.ServeTLS(l, "", "")
+	}
+	return s.http.Serve(l)
+}
+
+// Shutdown stops the server from accepting requests, and waits for active
+// requests to finish until the context is done, after which their connections
+// are closed and their handlers are waited for. The background tasks of the
+// server are then stopped. It must only be called once.
+func (s *Server) Shutdown(ctx context.Context) error {
+	err := s.http.Shutdown(ctx)
+	if err != nil {
+		_ = s.http.Close()
+	}
+	s.active.Wait()
+	close(s.done)
+	return err
 }
@@ -0,0 +1,65 @@
+package listener
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// unixPrefix is the prefix of addresses which are unix domain socket paths.
+const unixPrefix = "unix:"
+
+// Listen listens on an address, which is either a tcp address, such as
+// ':1337' or '127.0.0.1:1338', or 'unix:' followed by the path of a unix
+// domain socket. A stale socket left at the path is removed first, and
+// the permissions of the socket are set to mode, an octal string such as
+// '0660', if it is not empty.
+func Listen(addr string, mode string) (net.Listener, error) {
+	if !strings.HasPrefix(addr, unixPrefix) {
+		return net.Listen("tcp", addr)
+	}
+	path := strings.TrimPrefix(addr, unixPrefix)
+
+	err := removeSocket(path)
+	if err != nil {
+		return nil, err
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	if mode != "" {
+		perm, err := strconv.ParseUint(mode, 8, 32)
+		if err != nil {
+			l.Close()
+			return nil, fmt.Errorf("invalid socket mode '%s': %w", mode, err)
+		}
+		err = os.Chmod(path, fs.FileMode(perm))
+		if err != nil {
+			l.Close()
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// removeSocket removes a unix domain socket left by a previous run,
+// refusing to remove any other type of file.
+func removeSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&fs.ModeSocket == 0 {
+		return fmt.Errorf("cannot listen on '%s': file exists and is not a socket", path)
+	}
+	return os.Remove(path)
+}
@@ -0,0 +1,50 @@
+package listener
+
+import (
+	"github.com/go-playground/assert/v2"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestListen_Unix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "admin.sock")
+
+	l, err := Listen("unix:"+path, "0600")
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, fs.FileMode(0600), info.Mode().Perm())
+
+	// a socket left by a previous run is replaced
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	assert.Equal(t, nil, l.Close())
+	l, err = Listen("unix:"+path, "")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, l.Close())
+}
+
+func TestListen_Errors(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	err := os.WriteFile(file, nil, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = Listen("unix:"+file, "")
+	assert.NotEqual(t, nil, err)
+	_, err = Listen("unix:"+filepath.Join(dir, "admin.sock"), "rw")
+	assert.NotEqual(t, nil, err)
+}
+
+func TestListen_TCP(t *testing.T) {
+	l, err := Listen("127.0.0.1:0", "")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "tcp", l.Addr().Network())
+	assert.Equal(t, nil, l.Close())
+}
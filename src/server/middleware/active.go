@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"sync"
+)
+
+// ActiveRequests counts the requests being handled by a server, so that
+// once the server is shut down, and the connections of requests which did
+// not finish in time are closed, it can wait for their handlers to return
+// before the database and file manager they use are closed.
+type ActiveRequests struct {
+	mutex sync.Mutex
+	idle  *sync.Cond
+	count int
+}
+
+func NewActiveRequests() *ActiveRequests {
+	a := &ActiveRequests{}
+	a.idle = sync.NewCond(&a.mutex)
+	return a
+}
+
+// Track counts a request as active until its handlers return.
+func (a *ActiveRequests) Track() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		a.mutex.Lock()
+		a.count++
+		a.mutex.Unlock()
+		defer a.done()
+
+		ctx.Next()
+	}
+}
+
+func (a *ActiveRequests) done() {
+	a.mutex.Lock()
+	a.count--
+	if a.count == 0 {
+		a.idle.Broadcast()
+	}
+	a.mutex.Unlock()
+}
+
+// Wait waits until no requests are active.
+func (a *ActiveRequests) Wait() {
+	a.mutex.Lock()
+	for a.count > 0 {
+		a.idle.Wait()
+	}
+	a.mutex.Unlock()
+}
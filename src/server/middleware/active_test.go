@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestActiveRequests(t *testing.T) {
+	active := NewActiveRequests()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	r := gin.New()
+	r.Use(active.Track())
+	r.GET("/", func(ctx *gin.Context) {
+		close(started)
+		<-release
+		ctx.Status(204)
+	})
+
+	// no requests are active
+	active.Wait()
+
+	go r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	<-started
+
+	waited := make(chan struct{})
+	go func() {
+		active.Wait()
+		close(waited)
+	}()
+	select {
+	case <-waited:
+		t.Fatal("returned while a request was active")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-waited:
+	case <-time.After(time.Second):
+		t.Fatal("did not return once the request finished")
+	}
+	assert.Equal(t, 0, active.count)
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"encoding/hex"
+	"fsrv/src/logging"
+	"fsrv/utils/keygen"
+	"github.com/gin-gonic/gin"
+	"time"
+)
+
+// RequestIDHeader is the response header which contains the request id.
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDBytes is the number of random bytes in request ids.
+const requestIDBytes = 8
+
+// RequestID assigns a random id to the request, which is returned in the
+// RequestIDHeader header, and a logger which adds the id to its records.
+//
+//	Added Context Fields:
+//	 requestID -> string
+//	 logger -> *logging.Logger
+func RequestID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id := hex.EncodeToString(keygen.GetRand(requestIDBytes))
+		ctx.Header(RequestIDHeader, id)
+		ctx.Set("requestID", id)
+		ctx.Set("logger", logging.Default().WithRequestID(id))
+	}
+}
+
+// LogRequests logs each request once it has been handled. The query is
+// not logged, as it may contain a key.
+//
+//	Middleware Dependencies:
+//	 RequestID
+//	 GetIP
+func LogRequests() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		GetLogger(ctx).Infof("%s %s %d %s %s", ctx.Request.Method, ctx.Request.URL.Path,
+			ctx.Writer.Status(), time.Since(start), ctx.GetString("ip"))
+	}
+}
+
+// GetLogger returns the logger of the request, or the
+// default logger if the request does not have one.
+func GetLogger(ctx *gin.Context) *logging.Logger {
+	if logger, ok := ctx.Get("logger"); ok {
+		return logger.(*logging.Logger)
+	}
+	return logging.Default()
+}
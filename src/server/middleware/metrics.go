@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"fsrv/src/metrics"
+	"fsrv/src/types"
+	"github.com/gin-gonic/gin"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Metrics records the count, duration and body sizes of requests to a
+// server. Requests are partitioned by the operation assigned by
+// ClassifyOperation, or "none" if there is none, such as on the admin
+// server, where "operation" may be a query value instead, or when the
+// method is not allowed.
+func Metrics(server string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		body := &countingReader{r: ctx.Request.Body}
+		if ctx.Request.Body != nil {
+			ctx.Request.Body = body
+		}
+
+		ctx.Next()
+
+		op := "none"
+		if value, ok := ctx.Get("operation"); ok {
+			if opType, ok := value.(types.OperationType); ok {
+				op = opType.String()
+			}
+		}
+		metrics.Requests.With(server, op, strconv.Itoa(ctx.Writer.Status())).Inc()
+		metrics.ObserveSince(metrics.RequestDuration.With(server, op), start)
+		metrics.RequestBytes.With(server, op).Add(float64(body.n))
+		if size := ctx.Writer.Size(); size > 0 {
+			metrics.ResponseBytes.With(server, op).Add(float64(size))
+		}
+	}
+}
+
+// countingReader counts the bytes read from a request body.
+type countingReader struct {
+	r io.ReadCloser
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+func (r *countingReader) Close() error {
+	return r.r.Close()
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StringQuery returns a QueryParseFunc which
+// accepts any value, including an empty one.
+func StringQuery() QueryParseFunc[string] {
+	return func(value string, present bool) (string, error) {
+		return value, nil
+	}
+}
+
+// IntQuery returns a QueryParseFunc which parses an integer in the
+// range [min, max], using def when the query is absent or empty.
+func IntQuery(def, min, max int) QueryParseFunc[int] {
+	return func(value string, present bool) (int, error) {
+		if !present || value == "" {
+			return def, nil
+		}
+
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, errors.New("expected an integer")
+		}
+		if n < min || n > max {
+			return 0, fmt.Errorf("expected a value between %d and %d", min, max)
+		}
+		return n, nil
+	}
+}
+
+// EnumQuery returns a QueryParseFunc which accepts one of the given
+// values, using the first value when the query is absent or empty.
+func EnumQuery(values ...string) QueryParseFunc[string] {
+	return func(value string, present bool) (string, error) {
+		if !present || value == "" {
+			return values[0], nil
+		}
+
+		for _, v := range values {
+			if value == v {
+				return value, nil
+			}
+		}
+		return "", fmt.Errorf("expected one of: %s", strings.Join(values, ", "))
+	}
+}
+
+// BoolQuery returns a QueryParseFunc which parses a boolean,
+// using def when the query is absent or empty.
+func BoolQuery(def bool) QueryParseFunc[bool] {
+	return func(value string, present bool) (bool, error) {
+		if !present || value == "" {
+			return def, nil
+		}
+
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return false, errors.New("expected a boolean")
+		}
+		return b, nil
+	}
+}
@@ -1,5 +1,7 @@
 package types
 
+import "fmt"
+
 // OperationType represents the type of operation occurring on a particular file or directory.
 type OperationType int8
 
@@ -33,3 +35,24 @@ func (opType OperationType) Int() int {
 		panic("OperationType to int conversion failure")
 	}
 }
+
+// operationNames are the names of the operation types, by value.
+var operationNames = [...]string{"read", "write", "modify", "delete"}
+
+// String returns the name of an operation type, as used in the API.
+func (opType OperationType) String() string {
+	if opType < 0 || int(opType) >= len(operationNames) {
+		return "unknown"
+	}
+	return operationNames[opType]
+}
+
+// ParseOperationType returns the operation type with the given name.
+func ParseOperationType(name string) (OperationType, error) {
+	for i, n := range operationNames {
+		if n == name {
+			return OperationType(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown operation type '%s'", name)
+}
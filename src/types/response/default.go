@@ -6,7 +6,20 @@ var EmptyError = NewResponse[any](false, "", nil)
 var Forbidden = NewErrorMessage("forbidden")
 var ForbiddenExpiredKey = NewErrorMessage("forbidden: expired key")
 var Unauthorized = NewErrorMessage("unauthorized")
+var UnauthorizedExpiredToken = NewErrorMessage("unauthorized: expired token")
 var TooManyRequests = NewErrorMessage("too many requests")
 var TooManyConcurrentRequests = NewErrorMessage("too many concurrent requests")
+var MethodNotAllowed = NewErrorMessage("method not allowed")
+
+var NotFound = NewErrorMessage("not found")
+var InvalidFileName = NewErrorMessage("invalid file name")
+var FileExists = NewErrorMessage("a file already exists at the given path")
+var NotDirectory = NewErrorMessage("a parent of the given path is not a directory")
+var IsDirectory = NewErrorMessage("the given path is a directory")
+var PreconditionFailed = NewErrorMessage("precondition failed")
+var InvalidRange = NewErrorMessage("the given range cannot be written to the file")
+var RecursiveRequired = NewErrorMessage("directories may only be deleted with recursive=true")
+var TrashItemMissing = NewErrorMessage("the specified trash item does not exist")
+var MaxDepthExceeded = NewErrorMessage("the maximum directory depth would be exceeded")
 
 var InternalServerError = NewErrorMessage("internal server error")
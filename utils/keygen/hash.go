@@ -0,0 +1,16 @@
+package keygen
+
+import (
+	"encoding/base64"
+	"fsrv/utils"
+)
+
+// keyHashBytes is the number of bytes of the sha512 sum of a key kept in its hash.
+const keyHashBytes = 32
+
+// HashKey returns the id under which a key is stored, so
+// that the key itself is never stored by the server.
+func HashKey(key string) string {
+	sum := utils.Sha512Sum([]byte(key))[:keyHashBytes]
+	return base64.RawURLEncoding.EncodeToString(sum)
+}
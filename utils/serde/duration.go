@@ -10,8 +10,7 @@ type Duration time.Duration
 
 func (j Duration) MarshalJSON() ([]byte, error) {
 	ms := time.Duration(j).Milliseconds()
-	i := strconv.FormatInt(ms, 10)
-	return json.Marshal(i)
+	return []byte(strconv.FormatInt(ms, 10)), nil
 }
 
 func (j *Duration) UnmarshalJSON(data []byte) error {
@@ -10,8 +10,7 @@ type Time time.Time
 
 func (j Time) MarshalJSON() ([]byte, error) {
 	ms := time.Time(j).UnixMilli()
-	i := strconv.FormatInt(ms, 10)
-	return json.Marshal(i)
+	return []byte(strconv.FormatInt(ms, 10)), nil
 }
 
 func (j *Time) UnmarshalJSON(data []byte) error {
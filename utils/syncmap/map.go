@@ -69,11 +69,17 @@ func (s *CountMap[K, V]) Increment(id K) V {
 	return s.data[id]
 }
 
-// Decrement decrements the value and returns the updated value
+// Decrement decrements the value and returns the updated value.
+// The value is removed once it reaches zero, so that counts of
+// ids which are no longer in use do not accumulate.
 func (s *CountMap[K, V]) Decrement(id K) V {
 	s.mux.Lock()
 	defer s.mux.Unlock()
 
 	s.data[id]--
-	return s.data[id]
+	value := s.data[id]
+	if value == 0 {
+		delete(s.data, id)
+	}
+	return value
 }